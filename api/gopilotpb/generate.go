@@ -0,0 +1,3 @@
+package gopilotpb
+
+//go:generate protoc -I .. --go_out=../.. --go_opt=module=msfs2020-gopilot --go-grpc_out=../.. --go-grpc_opt=module=msfs2020-gopilot gopilot.proto
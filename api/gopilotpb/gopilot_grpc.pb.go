@@ -0,0 +1,297 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: gopilot.proto
+
+package gopilotpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	GoPilot_Subscribe_FullMethodName    = "/gopilot.v1.GoPilot/Subscribe"
+	GoPilot_SetData_FullMethodName      = "/gopilot.v1.GoPilot/SetData"
+	GoPilot_Teleport_FullMethodName     = "/gopilot.v1.GoPilot/Teleport"
+	GoPilot_FindAirports_FullMethodName = "/gopilot.v1.GoPilot/FindAirports"
+	GoPilot_Status_FullMethodName       = "/gopilot.v1.GoPilot/Status"
+)
+
+// GoPilotClient is the client API for GoPilot service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GoPilotClient interface {
+	// Subscribe streams the values of the SimVars whenever the simulator sent new
+	// data (see data_request_interval). Samples a slow client cannot keep up
+	// with are skipped, the next one always holds the latest values.
+	Subscribe(ctx context.Context, in *SimVarList, opts ...grpc.CallOption) (GoPilot_SubscribeClient, error)
+	// SetData sets a SimVar on the user aircraft.
+	SetData(ctx context.Context, in *SetDataRequest, opts ...grpc.CallOption) (*SetDataResponse, error)
+	// Teleport moves the user aircraft.
+	Teleport(ctx context.Context, in *TeleportRequest, opts ...grpc.CallOption) (*TeleportResponse, error)
+	// FindAirports returns the nearest airports, nearest first.
+	FindAirports(ctx context.Context, in *FindAirportsRequest, opts ...grpc.CallOption) (*FindAirportsResponse, error)
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+}
+
+type goPilotClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGoPilotClient(cc grpc.ClientConnInterface) GoPilotClient {
+	return &goPilotClient{cc}
+}
+
+func (c *goPilotClient) Subscribe(ctx context.Context, in *SimVarList, opts ...grpc.CallOption) (GoPilot_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GoPilot_ServiceDesc.Streams[0], GoPilot_Subscribe_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &goPilotSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GoPilot_SubscribeClient interface {
+	Recv() (*Sample, error)
+	grpc.ClientStream
+}
+
+type goPilotSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *goPilotSubscribeClient) Recv() (*Sample, error) {
+	m := new(Sample)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *goPilotClient) SetData(ctx context.Context, in *SetDataRequest, opts ...grpc.CallOption) (*SetDataResponse, error) {
+	out := new(SetDataResponse)
+	err := c.cc.Invoke(ctx, GoPilot_SetData_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goPilotClient) Teleport(ctx context.Context, in *TeleportRequest, opts ...grpc.CallOption) (*TeleportResponse, error) {
+	out := new(TeleportResponse)
+	err := c.cc.Invoke(ctx, GoPilot_Teleport_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goPilotClient) FindAirports(ctx context.Context, in *FindAirportsRequest, opts ...grpc.CallOption) (*FindAirportsResponse, error) {
+	out := new(FindAirportsResponse)
+	err := c.cc.Invoke(ctx, GoPilot_FindAirports_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goPilotClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, GoPilot_Status_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GoPilotServer is the server API for GoPilot service.
+// All implementations must embed UnimplementedGoPilotServer
+// for forward compatibility
+type GoPilotServer interface {
+	// Subscribe streams the values of the SimVars whenever the simulator sent new
+	// data (see data_request_interval). Samples a slow client cannot keep up
+	// with are skipped, the next one always holds the latest values.
+	Subscribe(*SimVarList, GoPilot_SubscribeServer) error
+	// SetData sets a SimVar on the user aircraft.
+	SetData(context.Context, *SetDataRequest) (*SetDataResponse, error)
+	// Teleport moves the user aircraft.
+	Teleport(context.Context, *TeleportRequest) (*TeleportResponse, error)
+	// FindAirports returns the nearest airports, nearest first.
+	FindAirports(context.Context, *FindAirportsRequest) (*FindAirportsResponse, error)
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	mustEmbedUnimplementedGoPilotServer()
+}
+
+// UnimplementedGoPilotServer must be embedded to have forward compatible implementations.
+type UnimplementedGoPilotServer struct {
+}
+
+func (UnimplementedGoPilotServer) Subscribe(*SimVarList, GoPilot_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedGoPilotServer) SetData(context.Context, *SetDataRequest) (*SetDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetData not implemented")
+}
+func (UnimplementedGoPilotServer) Teleport(context.Context, *TeleportRequest) (*TeleportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Teleport not implemented")
+}
+func (UnimplementedGoPilotServer) FindAirports(context.Context, *FindAirportsRequest) (*FindAirportsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindAirports not implemented")
+}
+func (UnimplementedGoPilotServer) Status(context.Context, *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedGoPilotServer) mustEmbedUnimplementedGoPilotServer() {}
+
+// UnsafeGoPilotServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GoPilotServer will
+// result in compilation errors.
+type UnsafeGoPilotServer interface {
+	mustEmbedUnimplementedGoPilotServer()
+}
+
+func RegisterGoPilotServer(s grpc.ServiceRegistrar, srv GoPilotServer) {
+	s.RegisterService(&GoPilot_ServiceDesc, srv)
+}
+
+func _GoPilot_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SimVarList)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GoPilotServer).Subscribe(m, &goPilotSubscribeServer{stream})
+}
+
+type GoPilot_SubscribeServer interface {
+	Send(*Sample) error
+	grpc.ServerStream
+}
+
+type goPilotSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *goPilotSubscribeServer) Send(m *Sample) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _GoPilot_SetData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoPilotServer).SetData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoPilot_SetData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoPilotServer).SetData(ctx, req.(*SetDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoPilot_Teleport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TeleportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoPilotServer).Teleport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoPilot_Teleport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoPilotServer).Teleport(ctx, req.(*TeleportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoPilot_FindAirports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindAirportsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoPilotServer).FindAirports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoPilot_FindAirports_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoPilotServer).FindAirports(ctx, req.(*FindAirportsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoPilot_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoPilotServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoPilot_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoPilotServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GoPilot_ServiceDesc is the grpc.ServiceDesc for GoPilot service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GoPilot_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gopilot.v1.GoPilot",
+	HandlerType: (*GoPilotServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetData",
+			Handler:    _GoPilot_SetData_Handler,
+		},
+		{
+			MethodName: "Teleport",
+			Handler:    _GoPilot_Teleport_Handler,
+		},
+		{
+			MethodName: "FindAirports",
+			Handler:    _GoPilot_FindAirports_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _GoPilot_Status_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _GoPilot_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gopilot.proto",
+}
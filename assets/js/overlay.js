@@ -0,0 +1,69 @@
+// Keeps the elements of an overlay page (see internal/overlay) up to date. When
+// the connection is re-established, the page resumes its session or, e.g. after
+// a restart of GoPilot, registers the SimVars again.
+(() => {
+    const meta = 'overlay';
+    const resumeMeta = 'resume';
+    const reconnectInterval = 2000;
+    const vars = JSON.parse(document.getElementById('overlay-vars').textContent) || [];
+    const address = 'ws://' + window.location.hostname + ':' + window.location.port + '/ws';
+    let token = null;
+
+    function format(element, value) {
+        if (typeof value !== 'number') {
+            return value;
+        }
+        const decimals = element.dataset.decimals;
+        return decimals === undefined ? Math.round(value) : value.toFixed(Number(decimals));
+    }
+
+    function update(data) {
+        document.querySelectorAll('[data-simvar]').forEach((element) => {
+            const value = data[element.dataset.simvar];
+            if (value !== undefined) {
+                element.textContent = format(element, value);
+            }
+        });
+        // Custom widgets can listen to this, e.g. to rotate a needle.
+        document.dispatchEvent(new CustomEvent('simvars', {detail: data}));
+    }
+
+    function connect() {
+        const socket = new WebSocket(address);
+        const register = () => {
+            if (vars.length > 0) {
+                socket.send(JSON.stringify({type: 'register', data: vars, meta, debug: 0}));
+            }
+        };
+        let resuming = false;
+        socket.onopen = () => {
+            if (token === null) {
+                register();
+                return;
+            }
+            resuming = true;
+            socket.send(JSON.stringify({type: 'resume', data: {token}, meta: resumeMeta, debug: 0}));
+        };
+        socket.onclose = () => {
+            setTimeout(connect, reconnectInterval);
+        };
+        socket.onmessage = (e) => {
+            const msg = JSON.parse(e.data);
+            if (msg.type === 'simvars' && msg.meta === meta) {
+                update(msg.data);
+            } else if (msg.type === 'session') {
+                if (msg.meta === resumeMeta) {
+                    resuming = false;
+                    if (msg.data.resumed === false) {
+                        register();
+                    }
+                    token = msg.data.token;
+                } else if (resuming === false) {
+                    token = msg.data.token;
+                }
+            }
+        };
+    }
+
+    connect();
+})();
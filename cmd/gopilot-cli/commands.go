@@ -0,0 +1,395 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"msfs2020-gopilot/internal/alerts"
+	"msfs2020-gopilot/internal/client"
+)
+
+const (
+	formatTable  = "table"
+	formatNDJSON = "ndjson"
+	formatJSON   = "json"
+
+	defaultDataType = "float64"
+	watchMeta       = "gopilot-cli"
+)
+
+func runWatch(opts *globalOptions, args []string) error {
+	fs := newFlagSet("watch")
+	format := fs.String("format", formatTable, "Output format: table or ndjson")
+	count := fs.Int("count", 0, "Stop after this many samples (0: run until interrupted)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != formatTable && *format != formatNDJSON {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("no SimVars given, e.g. \"PLANE ALTITUDE:feet\"")
+	}
+	vars, err := parseSimVars(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	c, err := client.Dial(opts.address, opts.timeout)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.Register(vars, watchMeta); err != nil {
+		return err
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	printer := newSamplePrinter(os.Stdout, *format, vars)
+	samples := 0
+	for {
+		select {
+		case msg, ok := <-c.Messages():
+			if !ok {
+				return c.Err()
+			}
+			if msg.Type != "simvars" || msg.Meta != watchMeta {
+				continue
+			}
+			data := make(map[string]interface{})
+			if err := json.Unmarshal(msg.Data, &data); err != nil {
+				return err
+			}
+			printer.print(data)
+			samples++
+			if *count > 0 && samples >= *count {
+				return c.Send("deregister", nil, "")
+			}
+		case <-interrupt:
+			return c.Send("deregister", nil, "")
+		}
+	}
+}
+
+func runSet(opts *globalOptions, args []string) error {
+	fs := newFlagSet("set")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 3 {
+		return fmt.Errorf("expected NAME UNIT VALUE")
+	}
+	value, err := strconv.ParseFloat(fs.Arg(2), 64)
+	if err != nil {
+		return fmt.Errorf("invalid value %q", fs.Arg(2))
+	}
+	data := map[string]interface{}{
+		"name":  fs.Arg(0),
+		"unit":  fs.Arg(1),
+		"value": value,
+	}
+	return sendOnce(opts, "setdata", data)
+}
+
+func runTeleport(opts *globalOptions, args []string) error {
+	fs := newFlagSet("teleport")
+	lat := fs.Float64("lat", math.NaN(), "Latitude in degrees")
+	lon := fs.Float64("lon", math.NaN(), "Longitude in degrees")
+	alt := fs.Float64("alt", math.NaN(), "Altitude in feet")
+	hdg := fs.Float64("hdg", 0, "True heading in degrees")
+	spd := fs.Float64("spd", 0, "True airspeed in knots")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if math.IsNaN(*lat) || math.IsNaN(*lon) || math.IsNaN(*alt) {
+		return fmt.Errorf("--lat, --lon and --alt are required")
+	}
+	data := map[string]interface{}{
+		"latitude":  *lat,
+		"longitude": *lon,
+		"altitude":  *alt,
+		"heading":   *hdg,
+		"airspeed":  *spd,
+	}
+	return sendOnce(opts, "teleport", data)
+}
+
+func runAirports(opts *globalOptions, args []string) error {
+	fs := newFlagSet("airports")
+	lat := fs.Float64("lat", math.NaN(), "Latitude in degrees")
+	lon := fs.Float64("lon", math.NaN(), "Longitude in degrees")
+	radius := fs.Float64("radius", 50000, "Search radius in meters")
+	max := fs.Int("max", 10, "Maximum number of airports")
+	filter := fs.String("filter", "", "Airport types separated by |, e.g. large_airport|medium_airport")
+	format := fs.String("format", formatTable, "Output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if math.IsNaN(*lat) || math.IsNaN(*lon) {
+		return fmt.Errorf("--lat and --lon are required")
+	}
+	data := map[string]interface{}{
+		"latitude":    *lat,
+		"longitude":   *lon,
+		"radius":      *radius,
+		"maxAirports": *max,
+	}
+	if *filter != "" {
+		data["filter"] = *filter
+	}
+
+	c, err := client.Dial(opts.address, opts.timeout)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.Send("airports", data, watchMeta); err != nil {
+		return err
+	}
+	msg, err := c.WaitForAny(opts.timeout, "airports", "result")
+	if err != nil {
+		return err
+	}
+	if msg.Type == "result" {
+		result, err := client.ParseResult(msg)
+		if err != nil {
+			return err
+		}
+		if err := result.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("unexpected result")
+	}
+
+	airports := make([]map[string]interface{}, 0)
+	if err := json.Unmarshal(msg.Data, &airports); err != nil {
+		return err
+	}
+	if *format == formatJSON {
+		return json.NewEncoder(os.Stdout).Encode(airports)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ICAO\tNAME\tTYPE\tLATITUDE\tLONGITUDE\tELEVATION")
+	for _, ap := range airports {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", ap["icao"], ap["name"], ap["type"], ap["latitude"], ap["longitude"], ap["elevation"])
+	}
+	return w.Flush()
+}
+
+func runStatus(opts *globalOptions, args []string) error {
+	fs := newFlagSet("status")
+	requireSim := fs.Bool("require-sim", false, "Exit with an error if GoPilot is not connected to the simulator")
+	format := fs.String("format", formatTable, "Output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := client.Dial(opts.address, opts.timeout)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	start := time.Now()
+	if err := c.Send("ping", nil, watchMeta); err != nil {
+		return err
+	}
+	if _, err := c.WaitFor("pong", opts.timeout); err != nil {
+		return err
+	}
+	latency := time.Since(start)
+
+	msg, err := c.WaitFor("status", opts.timeout)
+	if err != nil {
+		return err
+	}
+	status := make(map[string]interface{})
+	if err := json.Unmarshal(msg.Data, &status); err != nil {
+		return err
+	}
+	connected, _ := status["simconnect"].(bool)
+
+	if *format == formatJSON {
+		out := map[string]interface{}{
+			"address":   opts.address,
+			"latencyMs": float64(latency.Microseconds()) / 1000.0,
+			"status":    status,
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "Server\t%s\n", opts.address)
+		fmt.Fprintf(w, "Latency\t%s\n", latency.Round(time.Microsecond))
+		keys := make([]string, 0, len(status))
+		for key := range status {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(w, "%s\t%v\n", key, status[key])
+		}
+		w.Flush()
+	}
+
+	if *requireSim && !connected {
+		return fmt.Errorf("not connected to the simulator")
+	}
+	return nil
+}
+
+// runAlerts replays a recording against alert rules without a server, so rules
+// can be checked before they are used in flight.
+func runAlerts(opts *globalOptions, args []string) error {
+	fs := newFlagSet("alerts")
+	rulesPath := fs.String("rules", "configs/alerts.yml", "Alert rules file")
+	format := fs.String("format", formatTable, "Output format: table or ndjson")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != formatTable && *format != formatNDJSON {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected a recording (NDJSON, - for stdin)")
+	}
+	rules, err := alerts.LoadRules(*rulesPath)
+	if err != nil {
+		return err
+	}
+
+	in := os.Stdin
+	if fs.Arg(0) != "-" {
+		file, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	if *format == formatTable {
+		fmt.Fprintln(w, "TIME\tSTATE\tSEVERITY\tRULE\tMESSAGE")
+	}
+	count := 0
+	// Airports are not available offline, so rules using airportDistance
+	// never trigger.
+	engine := alerts.NewEngine(nil, func(alert *alerts.Alert) {
+		count++
+		if *format == formatNDJSON {
+			if buf, err := json.Marshal(alert); err == nil {
+				fmt.Println(string(buf))
+			}
+			return
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", alert.Time.Format("15:04:05.000"), alert.State, alert.Severity, alert.Rule, alert.Message)
+	})
+	engine.SetRules(rules)
+	if err := alerts.Replay(engine, in); err != nil {
+		return err
+	}
+	if *format == formatTable {
+		w.Flush()
+		fmt.Printf("%d alert(s)\n", count)
+	}
+	return nil
+}
+
+// sendOnce sends a message and waits for its result, so errors like a missing
+// simulator connection make the command fail.
+func sendOnce(opts *globalOptions, msgType string, data interface{}) error {
+	c, err := client.Dial(opts.address, opts.timeout)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	return c.Call(msgType, data, watchMeta, opts.timeout)
+}
+
+// parseSimVars parses arguments of the form NAME:UNIT[:TYPE].
+func parseSimVars(args []string) ([]client.SimVar, error) {
+	vars := make([]client.SimVar, 0, len(args))
+	for _, arg := range args {
+		parts := strings.Split(arg, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid SimVar %q, expected NAME:UNIT[:TYPE]", arg)
+		}
+		typ := defaultDataType
+		if len(parts) == 3 && parts[2] != "" {
+			typ = parts[2]
+		}
+		vars = append(vars, client.SimVar{
+			Name:    parts[0],
+			Unit:    parts[1],
+			Type:    typ,
+			Moniker: parts[0],
+		})
+	}
+	return vars, nil
+}
+
+type samplePrinter struct {
+	out           io.Writer
+	format        string
+	vars          []client.SimVar
+	headerWritten bool
+}
+
+func newSamplePrinter(out io.Writer, format string, vars []client.SimVar) *samplePrinter {
+	return &samplePrinter{out: out, format: format, vars: vars}
+}
+
+func (p *samplePrinter) print(data map[string]interface{}) {
+	now := time.Now()
+	if p.format == formatNDJSON {
+		buf, err := json.Marshal(map[string]interface{}{
+			"time": now.Format(time.RFC3339Nano),
+			"data": data,
+		})
+		if err == nil {
+			fmt.Fprintln(p.out, string(buf))
+		}
+		return
+	}
+
+	if !p.headerWritten {
+		columns := []string{fmt.Sprintf("%-12s", "TIME")}
+		for _, v := range p.vars {
+			columns = append(columns, fmt.Sprintf("%20s", v.Moniker))
+		}
+		fmt.Fprintln(p.out, strings.Join(columns, " "))
+		p.headerWritten = true
+	}
+	columns := []string{fmt.Sprintf("%-12s", now.Format("15:04:05.000"))}
+	for _, v := range p.vars {
+		columns = append(columns, fmt.Sprintf("%20s", formatSampleValue(data[v.Moniker])))
+	}
+	fmt.Fprintln(p.out, strings.Join(columns, " "))
+}
+
+func formatSampleValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case float64:
+		return strconv.FormatFloat(v, 'f', 4, 64)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	defaultAddress = "localhost:8888"
+	defaultTimeout = 10 * time.Second
+)
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(opts *globalOptions, args []string) error
+}
+
+type globalOptions struct {
+	address string
+	timeout time.Duration
+}
+
+var commands = []command{
+	{"watch", "watch [flags] NAME:UNIT[:TYPE] ...", "Stream SimVar values as a table or NDJSON", runWatch},
+	{"set", "set NAME UNIT VALUE", "Set a SimVar on the user aircraft", runSet},
+	{"teleport", "teleport --lat LAT --lon LON --alt FEET [--hdg DEG] [--spd KNOTS]", "Teleport the user aircraft", runTeleport},
+	{"airports", "airports --lat LAT --lon LON [--radius METERS] [--max N] [--filter TYPES]", "Find airports around a position", runAirports},
+	{"status", "status [--require-sim]", "Show the server and SimConnect status", runStatus},
+	{"alerts", "alerts [--rules FILE] RECORDING", "Replay a recording (watch --format ndjson) against alert rules", runAlerts},
+}
+
+func main() {
+	opts := &globalOptions{}
+	flag.StringVar(&opts.address, "addr", defaultAddress, "GoPilot server address (host:port)")
+	flag.DurationVar(&opts.timeout, "timeout", defaultTimeout, "Timeout for connecting and waiting for replies")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := flag.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(opts, flag.Args()[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "gopilot-cli %s: %s\n", name, err.Error())
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "gopilot-cli: unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: gopilot-cli [--addr HOST:PORT] [--timeout DURATION] <command> [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-10s %s\n             %s\n", cmd.name, cmd.summary, cmd.usage)
+	}
+	fmt.Fprintf(out, "\nGlobal flags:\n")
+	flag.PrintDefaults()
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("gopilot-cli "+name, flag.ContinueOnError)
+}
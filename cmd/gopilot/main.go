@@ -1,16 +1,20 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"math/rand"
 	"msfs2020-gopilot/internal/app"
 	"msfs2020-gopilot/internal/config"
+	"msfs2020-gopilot/internal/daemon"
 	"msfs2020-gopilot/internal/filepacker"
+	"msfs2020-gopilot/internal/resources"
 	"os"
+	"os/signal"
 	"path"
 	"runtime/debug"
+	"syscall"
 	"time"
 
 	"github.com/common-nighthawk/go-figure"
@@ -20,17 +24,12 @@ import (
 )
 
 const (
-	appTitle  = "MSFS2020-GoPilot"
-	dataDir   = "data/"
-	assetsDir = "assets/"
-
-	defaultConfigFilePath      = "./configs/config.yml"
-	defaultServerAddress       = "0.0.0.0:8888"
-	defaultSimConnectDLLPath   = "."
-	defaultConnectionTimeout   = 600 // seconds
-	defaultRequestDataInterval = 200 // milliseconds
-	projectURL                 = "http://github.com/grumpypixel/msfs2020-gopilot"
-	releasesURL                = projectURL + "/releases"
+	appTitle = "MSFS2020-GoPilot"
+
+	defaultConfigFilePath = "./configs/config.yml"
+	configWatchInterval   = 2 // seconds
+	projectURL            = "http://github.com/grumpypixel/msfs2020-gopilot"
+	releasesURL           = projectURL + "/releases"
 )
 
 type Parameters struct {
@@ -45,8 +44,6 @@ func init() {
 }
 
 func main() {
-	welcome()
-
 	defer func() {
 		if r := recover(); r != nil {
 			log.Warnf("stacktrace from panic: %s", string(debug.Stack()))
@@ -54,29 +51,116 @@ func main() {
 	}()
 
 	var configFilePath string
+	var printConfig, checkConfig, watchConfig, daemonMode bool
 	flag.StringVar(&configFilePath, "cfg", defaultConfigFilePath, "Config file location")
+	flag.BoolVar(&printConfig, "print-config", false, "Print the effective configuration as YAML and exit")
+	flag.BoolVar(&checkConfig, "check-config", false, "Validate the configuration and exit")
+	flag.BoolVar(&watchConfig, "watch-config", true, "Apply changes to the config file while running (where possible)")
+	flag.BoolVar(&daemonMode, "daemon", false, "Run unattended: log JSON to the rotating log file and write a PID file")
+	config.RegisterFlags(flag.CommandLine)
 	flag.Parse()
 
+	loadOptions := config.LoadOptions{
+		Path:     configFilePath,
+		Required: isFlagSet("cfg"),
+		Flags:    flag.CommandLine,
+	}
+	if printConfig || checkConfig {
+		os.Exit(runConfigCommand(loadOptions, printConfig))
+	}
+
+	if daemonMode {
+		log.SetFormatter(&log.JSONFormatter{})
+		log.SetOutput(os.Stdout)
+	} else {
+		welcome()
+	}
+
 	log.Infof("Loading config at {%s}", configFilePath)
-	cfg, err := config.NewConfigFromFile(configFilePath)
+	cfg, err := config.Load(loadOptions)
 	if err != nil {
-		log.Info("Loading a default configuration...")
-		cfg = newDefaultConfig()
+		log.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
 	}
-	prettyPrint("Configuration:\n", cfg)
+	prettyPrint("Configuration:\n", cfg.Redacted())
+
+	log.SetLevel(cfg.Level())
 
-	log.SetLevel(getLogLevel(cfg.LogLevel))
+	cleanup := func() {}
+	if daemonMode {
+		if cleanup, err = startDaemon(cfg.Daemon); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// From here on, the PID file and the log file have to be released before
+	// exiting, so log.Fatal must not be used.
+	fail := func(err error) {
+		log.Error(err)
+		cleanup()
+		os.Exit(1)
+	}
 
 	if err := checkInstallation(cfg.SimConnectDLLPath); err != nil {
-		log.Fatal(err)
+		fail(err)
 	}
 
-	app := app.NewApp(cfg)
-	if err := app.Run(); err != nil {
-		log.Error(err)
+	res, err := loadResources(cfg.AssetsOverrideDir)
+	if err != nil {
+		fail(err)
+	}
+
+	app := app.NewApp(cfg, res)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if watchConfig {
+		watcher := config.NewWatcher(loadOptions, configWatchInterval*time.Second, app.ApplyConfig)
+		go watcher.Run(ctx)
+	}
+
+	if err := app.Run(ctx); err != nil {
+		fail(err)
 	}
 
 	log.Info("Bye \\(^-^)/")
+	cleanup()
+}
+
+// startDaemon redirects the log to the rotating log file and acquires the PID
+// file. The returned function releases both.
+func startDaemon(cfg config.DaemonConfig) (func(), error) {
+	var logFile *daemon.RotatingFile
+	if cfg.LogFile != "" {
+		var err error
+		logFile, err = daemon.OpenRotatingFile(cfg.LogFile, cfg.LogMaxSize*1024*1024, int(cfg.LogMaxBackups))
+		if err != nil {
+			return nil, fmt.Errorf("unable to open log file: %w", err)
+		}
+		log.Infof("Logging to {%s}", cfg.LogFile)
+		log.SetOutput(logFile)
+	}
+	pidFile, err := daemon.AcquirePIDFile(cfg.PIDFile)
+	if err != nil {
+		if logFile != nil {
+			log.Error(err)
+			log.SetOutput(os.Stdout)
+			logFile.Close()
+		}
+		return nil, err
+	}
+	log.Infof("Wrote PID %d to {%s}", os.Getpid(), cfg.PIDFile)
+	return func() {
+		if err := pidFile.Release(); err != nil {
+			log.Warn("Unable to remove PID file: ", err)
+		}
+		if logFile != nil {
+			log.SetOutput(os.Stdout)
+			logFile.Close()
+		}
+	}, nil
 }
 
 func welcome() {
@@ -85,15 +169,46 @@ func welcome() {
 	fmt.Printf("\nWelcome to %s\nHomepage: %s\nReleases: %s\n\n", appTitle, projectURL, releasesURL)
 }
 
-func newDefaultConfig() *config.Config {
-	return &config.Config{
-		ConnectionName:      randomConnectionName(),
-		ConnectionTimeout:   defaultConnectionTimeout,
-		SimConnectDLLPath:   ".",
-		ServerAddress:       defaultServerAddress,
-		DataRequestInterval: defaultRequestDataInterval,
-		LogLevel:            "info",
+func runConfigCommand(opts config.LoadOptions, printConfig bool) int {
+	cfg, err := config.Load(opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	validationErr := cfg.Validate()
+	if printConfig {
+		str, err := cfg.YAML()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Print(str)
+	}
+	if validationErr != nil {
+		if verrs, ok := validationErr.(config.ValidationError); ok {
+			fmt.Fprintln(os.Stderr, "Invalid configuration:")
+			for _, ferr := range verrs {
+				fmt.Fprintf(os.Stderr, "  %s\n", ferr.Error())
+			}
+		} else {
+			fmt.Fprintln(os.Stderr, validationErr)
+		}
+		return 1
+	}
+	if !printConfig {
+		fmt.Println("Configuration OK")
 	}
+	return 0
+}
+
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
 
 func checkInstallation(simConnectDLLPath string) error {
@@ -111,17 +226,22 @@ func checkInstallation(simConnectDLLPath string) error {
 			log.Error("Unable to unpack DLL: ", err)
 		}
 	}
-	// Check assets directory
-	if _, err := os.Stat(assetsDir); os.IsNotExist(err) {
-		return err
-	}
-	// Check data directory
-	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
-		return err
-	}
 	return nil
 }
 
+func loadResources(overrideDir string) (*resources.Resources, error) {
+	if overrideDir != "" {
+		res, err := resources.New(overrideDir)
+		if err == nil {
+			log.Infof("Serving assets and data from {%s}", overrideDir)
+			return res, nil
+		}
+		log.Errorf("Unable to use assets override directory {%s} (error: %s)", overrideDir, err.Error())
+	}
+	log.Info("Serving embedded assets and data")
+	return resources.New("")
+}
+
 func unpack(data []byte, fullpath string) error {
 	unpacked, err := filepacker.Unpack(data)
 	if err != nil {
@@ -149,28 +269,3 @@ func prettyPrint(msg string, data interface{}) {
 	}
 	log.Info(msg, string(bytes))
 }
-
-func getLogLevel(level string) log.Level {
-	switch level {
-	case "error":
-		return log.ErrorLevel
-	case "warn":
-		return log.WarnLevel
-	case "debug":
-		return log.DebugLevel
-	case "trace":
-		return log.TraceLevel
-	}
-	return log.InfoLevel
-}
-
-func randomConnectionName() string {
-	rand.Seed(time.Now().Unix())
-	names := []string{
-		"0xDECAFBAD", "0xBADDCAFE", "0xCAFED00D",
-		"Boobytrap", "Sobeit Void", "Transpotato",
-		"A Butt Tuba", "Evil Olive", "Flee to Me, Remote Elf",
-		"Sit on a Potato Pan, Otis", "Taco Cat", "UFO Tofu",
-	}
-	return names[rand.Intn(len(names))]
-}
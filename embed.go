@@ -0,0 +1,12 @@
+package gopilot
+
+import "embed"
+
+// The web assets and the OurAirports database are compiled into the executable
+// so that GoPilot can be run from any working directory.
+
+//go:embed assets
+var Assets embed.FS
+
+//go:embed data/ourairports
+var Data embed.FS
@@ -5,7 +5,9 @@ go 1.17
 require (
 	github.com/buger/jsonparser v1.1.1
 	github.com/common-nighthawk/go-figure v0.0.0-20210622060536-734e95fb86be
-	github.com/google/uuid v1.2.0
+	github.com/eclipse/paho.mqtt.golang v1.3.5
+	github.com/fxamacker/cbor/v2 v2.5.0
+	github.com/google/uuid v1.3.0
 	github.com/gorilla/mux v1.8.0
 	github.com/gorilla/websocket v1.4.2
 	github.com/grumpypixel/go-airport-finder v0.0.0-20210902211810-793a4fb1490b
@@ -13,14 +15,23 @@ require (
 	github.com/ilyakaznacheev/cleanenv v1.2.5
 	github.com/mattn/go-colorable v0.1.8
 	github.com/sirupsen/logrus v1.8.1
+	github.com/vmihailenco/msgpack v4.0.4+incompatible
+	google.golang.org/grpc v1.56.3
+	google.golang.org/protobuf v1.30.0
+	gopkg.in/yaml.v2 v2.2.2
 )
 
 require (
 	github.com/BurntSushi/toml v0.3.1 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
 	github.com/grumpypixel/go-webget v0.0.0-20210513194017-df576311f21d // indirect
 	github.com/joho/godotenv v1.3.0 // indirect
 	github.com/mattn/go-isatty v0.0.12 // indirect
-	golang.org/x/sys v0.0.0-20200223170610-d5e6a3e2c0ae // indirect
-	gopkg.in/yaml.v2 v2.2.2 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/net v0.11.0 // indirect
+	golang.org/x/sys v0.9.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	google.golang.org/appengine v1.6.7 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
 	olympos.io/encoding/edn v0.0.0-20200308123125-93e3b8dd0e24 // indirect
 )
@@ -0,0 +1,308 @@
+package alerts
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"msfs2020-gopilot/internal/geo"
+	"msfs2020-gopilot/internal/telemetry"
+
+	"github.com/google/uuid"
+)
+
+const (
+	StateActive  = "active"
+	StateCleared = "cleared"
+
+	// Airports are searched within this radius. If there is none, the
+	// airport distance is the radius.
+	airportSearchRadius = 25 * geo.MetersPerNauticalMile
+	// The nearest airport is looked up again once the aircraft has moved
+	// this far or the last lookup is this old.
+	airportLookupDistance = 250.0 // meters
+	airportLookupAge      = 10 * time.Second
+
+	monikerAltitude          = "altitude"
+	monikerHeight            = "height"
+	monikerIndicatedAirspeed = "indicatedAirspeed"
+	monikerTrueAirspeed      = "trueAirspeed"
+	monikerGroundSpeed       = "groundSpeed"
+	monikerVerticalSpeed     = "verticalSpeed"
+	monikerBank              = "bank"
+	monikerPitch             = "pitch"
+	monikerHeading           = "heading"
+	monikerGForce            = "gForce"
+	monikerOnGround          = "onGround"
+	monikerOverspeed         = "overspeed"
+	monikerStall             = "stall"
+	monikerLatitude          = "latitude"
+	monikerLongitude         = "longitude"
+	monikerAirportDistance   = "airportDistance"
+)
+
+var vars = []telemetry.Var{
+	{Name: "PLANE ALTITUDE", Unit: "feet", Type: "float64", Moniker: monikerAltitude},
+	{Name: "PLANE ALT ABOVE GROUND", Unit: "feet", Type: "float64", Moniker: monikerHeight},
+	{Name: "AIRSPEED INDICATED", Unit: "knot", Type: "float64", Moniker: monikerIndicatedAirspeed},
+	{Name: "AIRSPEED TRUE", Unit: "knot", Type: "float64", Moniker: monikerTrueAirspeed},
+	{Name: "GROUND VELOCITY", Unit: "knot", Type: "float64", Moniker: monikerGroundSpeed},
+	{Name: "VERTICAL SPEED", Unit: "ft/min", Type: "float64", Moniker: monikerVerticalSpeed},
+	{Name: "PLANE BANK DEGREES", Unit: "degrees", Type: "float64", Moniker: monikerBank},
+	{Name: "PLANE PITCH DEGREES", Unit: "degrees", Type: "float64", Moniker: monikerPitch},
+	{Name: "PLANE HEADING DEGREES TRUE", Unit: "degrees", Type: "float64", Moniker: monikerHeading},
+	{Name: "G FORCE", Unit: "GForce", Type: "float64", Moniker: monikerGForce},
+	{Name: "SIM ON GROUND", Unit: "bool", Type: "int32", Moniker: monikerOnGround},
+	{Name: "OVERSPEED WARNING", Unit: "bool", Type: "int32", Moniker: monikerOverspeed},
+	{Name: "STALL WARNING", Unit: "bool", Type: "int32", Moniker: monikerStall},
+	{Name: "PLANE LATITUDE", Unit: "degrees", Type: "float64", Moniker: monikerLatitude},
+	{Name: "PLANE LONGITUDE", Unit: "degrees", Type: "float64", Moniker: monikerLongitude},
+}
+
+func isKnownVar(name string) bool {
+	if name == monikerAirportDistance {
+		return true
+	}
+	for _, v := range vars {
+		if v.Moniker == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AirportLocator returns the distance in meters to the nearest airport within
+// the radius, and false if there is none. It returns an error if it can't
+// search at all, e.g. without an airports database.
+type AirportLocator func(latitude, longitude, radius float64) (float64, bool, error)
+
+type Alert struct {
+	ID        string             `json:"id"`
+	Rule      string             `json:"rule"`
+	Message   string             `json:"message"`
+	Severity  string             `json:"severity"`
+	State     string             `json:"state"`
+	Time      time.Time          `json:"time"`
+	Since     time.Time          `json:"since"`
+	Latitude  float64            `json:"latitude"`
+	Longitude float64            `json:"longitude"`
+	Values    map[string]float64 `json:"values"`
+}
+
+type ruleState struct {
+	since     time.Time // conditions hold since
+	active    bool
+	lastFired time.Time
+	alert     *Alert
+}
+
+// Engine evaluates the rules against every sample. An alert is sent when the
+// conditions of a rule start to hold (after the hold time) and again when they
+// no longer hold, with the state "cleared".
+type Engine struct {
+	findAirport AirportLocator
+	onAlert     func(alert *Alert)
+	rules       []*Rule
+	states      map[string]*ruleState
+	airport     airportCache
+	mutex       sync.Mutex
+}
+
+type airportCache struct {
+	time      time.Time
+	latitude  float64
+	longitude float64
+	distance  float64
+	found     bool
+}
+
+func NewEngine(findAirport AirportLocator, onAlert func(alert *Alert)) *Engine {
+	return &Engine{
+		findAirport: findAirport,
+		onAlert:     onAlert,
+		states:      make(map[string]*ruleState),
+	}
+}
+
+// Vars returns all variables rules can refer to, so that rules can be changed
+// without registering new SimVars.
+func (engine *Engine) Vars() []telemetry.Var {
+	return vars
+}
+
+// SetRules replaces the rules. The state of rules which still exist is kept,
+// alerts of removed rules are dropped silently.
+func (engine *Engine) SetRules(rules *RuleSet) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	states := make(map[string]*ruleState)
+	for _, rule := range rules.Rules {
+		if state, ok := engine.states[rule.Name]; ok {
+			states[rule.Name] = state
+		} else {
+			states[rule.Name] = &ruleState{}
+		}
+	}
+	engine.rules = rules.Rules
+	engine.states = states
+}
+
+func (engine *Engine) Rules() []*Rule {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	rules := make([]*Rule, len(engine.rules))
+	copy(rules, engine.rules)
+	return rules
+}
+
+// Active returns the active alerts, oldest first.
+func (engine *Engine) Active() []*Alert {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	alerts := make([]*Alert, 0)
+	for _, state := range engine.states {
+		if state.active {
+			alerts = append(alerts, state.alert)
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].Since.Before(alerts[j].Since) })
+	return alerts
+}
+
+func (engine *Engine) Consume(sample *telemetry.Sample) {
+	engine.mutex.Lock()
+	alerts := engine.update(sample)
+	engine.mutex.Unlock()
+	if engine.onAlert != nil {
+		for _, alert := range alerts {
+			engine.onAlert(alert)
+		}
+	}
+}
+
+func (engine *Engine) update(sample *telemetry.Sample) []*Alert {
+	if len(engine.rules) == 0 {
+		return nil
+	}
+	t := sample.Time
+	values := make(map[string]float64, len(vars)+1)
+	for _, v := range vars {
+		if value, ok := sample.Float(v.Moniker); ok {
+			values[v.Moniker] = value
+		}
+	}
+	// PLANE PITCH DEGREES is negative for nose up.
+	if pitch, ok := values[monikerPitch]; ok {
+		values[monikerPitch] = -pitch
+	}
+	if engine.needsAirport() {
+		if distance, ok := engine.airportDistance(t, values); ok {
+			values[monikerAirportDistance] = distance
+		}
+	}
+
+	alerts := make([]*Alert, 0)
+	for _, rule := range engine.rules {
+		state := engine.states[rule.Name]
+		holds := true
+		for i := range rule.When {
+			if !rule.When[i].holds(values) {
+				holds = false
+				break
+			}
+		}
+
+		if !holds {
+			state.since = time.Time{}
+			if state.active {
+				state.active = false
+				alert := engine.newAlert(rule, StateCleared, t, state.alert.Since, values)
+				alert.ID = state.alert.ID
+				alerts = append(alerts, alert)
+			}
+			continue
+		}
+
+		if state.since.IsZero() {
+			state.since = t
+		}
+		switch {
+		case !state.active && t.Sub(state.since) >= seconds(rule.Hold):
+			state.active = true
+			state.alert = engine.newAlert(rule, StateActive, t, t, values)
+		case state.active && rule.Repeat > 0 && t.Sub(state.lastFired) >= seconds(rule.Repeat):
+			alert := engine.newAlert(rule, StateActive, t, state.alert.Since, values)
+			alert.ID = state.alert.ID
+			state.alert = alert
+		default:
+			continue
+		}
+		state.lastFired = t
+		alerts = append(alerts, state.alert)
+	}
+	return alerts
+}
+
+func (engine *Engine) newAlert(rule *Rule, state string, t, since time.Time, values map[string]float64) *Alert {
+	alert := &Alert{
+		ID:        fmt.Sprintf("%s-%s", since.Format("20060102-150405"), uuid.New().String()[:8]),
+		Rule:      rule.Name,
+		Message:   rule.Message,
+		Severity:  rule.Severity,
+		State:     state,
+		Time:      t,
+		Since:     since,
+		Latitude:  values[monikerLatitude],
+		Longitude: values[monikerLongitude],
+		Values:    make(map[string]float64),
+	}
+	for _, name := range rule.Vars() {
+		if value, ok := values[name]; ok {
+			alert.Values[name] = math.Round(value*100) / 100
+		}
+	}
+	return alert
+}
+
+func (engine *Engine) needsAirport() bool {
+	for _, rule := range engine.rules {
+		for _, cond := range rule.When {
+			if cond.Var == monikerAirportDistance {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// airportDistance returns the distance to the nearest airport in nautical
+// miles. It is unknown if no search could be made, which is different from
+// finding no airport within the radius.
+func (engine *Engine) airportDistance(t time.Time, values map[string]float64) (float64, bool) {
+	lat, ok1 := values[monikerLatitude]
+	lon, ok2 := values[monikerLongitude]
+	if engine.findAirport == nil || !ok1 || !ok2 {
+		return 0, false
+	}
+	cache := &engine.airport
+	if cache.time.IsZero() || t.Sub(cache.time) >= airportLookupAge ||
+		geo.Distance(cache.latitude, cache.longitude, lat, lon) >= airportLookupDistance {
+		var err error
+		cache.distance, cache.found, err = engine.findAirport(lat, lon, airportSearchRadius)
+		if err != nil {
+			cache.time = time.Time{}
+			return 0, false
+		}
+		cache.time, cache.latitude, cache.longitude = t, lat, lon
+	}
+	if !cache.found {
+		return geo.MetersToNauticalMiles(airportSearchRadius), true
+	}
+	return geo.MetersToNauticalMiles(cache.distance), true
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
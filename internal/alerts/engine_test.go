@@ -0,0 +1,273 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+const testRules = `
+rules:
+  - name: steep_bank
+    message: Bank angle above 30°
+    hold: 2
+    repeat: 5
+    when:
+      - var: bank
+        abs: true
+        above: 30
+
+  - name: low_altitude
+    severity: critical
+    when:
+      - var: height
+        below: 1000
+      - var: airportDistance
+        above: 3
+`
+
+func newTestEngine(t *testing.T, findAirport AirportLocator) (*Engine, *[]*Alert) {
+	t.Helper()
+	rules, err := ParseRules([]byte(testRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	alerts := make([]*Alert, 0)
+	engine := NewEngine(findAirport, func(alert *Alert) {
+		alerts = append(alerts, alert)
+	})
+	engine.SetRules(rules)
+	return engine, &alerts
+}
+
+// replay feeds one sample per second, the data of a sample is a JSON object.
+func replay(t *testing.T, engine *Engine, samples ...string) {
+	t.Helper()
+	lines := make([]string, len(samples))
+	for i, data := range samples {
+		lines[i] = fmt.Sprintf(`{"time": %q, "data": %s}`, start.Add(time.Duration(i)*time.Second).Format(time.RFC3339), data)
+	}
+	if err := Replay(engine, strings.NewReader(strings.Join(lines, "\n"))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type expectedAlert struct {
+	second int
+	state  string
+	since  int
+}
+
+func checkAlerts(t *testing.T, alerts []*Alert, expected []expectedAlert) {
+	t.Helper()
+	if len(alerts) != len(expected) {
+		for _, alert := range alerts {
+			t.Logf("%s %s at %s", alert.Rule, alert.State, alert.Time.Sub(start))
+		}
+		t.Fatalf("got %d alerts, want %d", len(alerts), len(expected))
+	}
+	for i, want := range expected {
+		got := alerts[i]
+		if got.Time != start.Add(time.Duration(want.second)*time.Second) || got.State != want.state ||
+			got.Since != start.Add(time.Duration(want.since)*time.Second) {
+			t.Errorf("alert #%d is %s at %s since %s, want %s at %ds since %ds",
+				i+1, got.State, got.Time.Sub(start), got.Since.Sub(start), want.state, want.second, want.since)
+		}
+	}
+}
+
+func TestHoldRepeatClear(t *testing.T) {
+	engine, alerts := newTestEngine(t, nil)
+	replay(t, engine,
+		`{"bank": 10}`,
+		`{"bank": 35}`, // holds from 1s
+		`{"PLANE BANK DEGREES": -40}`,
+		`{"bank": 45}`, // held for 2s: fires
+		`{"bank": 50}`,
+		`{"bank": 50}`,
+		`{"bank": 50}`,
+		`{"bank": 50}`,
+		`{"bank": 50}`, // 5s after firing: repeats
+		`{"bank": 25}`, // clears
+		`{"bank": 35}`,
+		`{"bank": 10}`, // interrupted before the hold time
+		`{"bank": 35}`,
+		`{"bank": 35}`,
+		`{"bank": 31}`, // held for 2s: fires again
+	)
+	checkAlerts(t, *alerts, []expectedAlert{
+		{second: 3, state: StateActive, since: 3},
+		{second: 8, state: StateActive, since: 3},
+		{second: 9, state: StateCleared, since: 3},
+		{second: 14, state: StateActive, since: 14},
+	})
+
+	first, repeated, cleared, again := (*alerts)[0], (*alerts)[1], (*alerts)[2], (*alerts)[3]
+	if repeated.ID != first.ID || cleared.ID != first.ID {
+		t.Error("repeated and cleared alerts have a new ID")
+	}
+	if again.ID == first.ID {
+		t.Error("alert fired again with the ID of the cleared one")
+	}
+	if first.Rule != "steep_bank" || first.Severity != SeverityWarning || first.Values["bank"] != 45 {
+		t.Errorf("alert %+v", first)
+	}
+	if cleared.Values["bank"] != 25 {
+		t.Errorf("cleared alert has bank %g, want 25", cleared.Values["bank"])
+	}
+	if active := engine.Active(); len(active) != 1 || active[0].ID != again.ID {
+		t.Errorf("active alerts %v, want the last one", active)
+	}
+}
+
+func TestMissingValuesDoNotHold(t *testing.T) {
+	engine, alerts := newTestEngine(t, nil)
+	replay(t, engine,
+		`{"height": 500}`,
+		`{"height": 500, "latitude": 50, "longitude": 8}`,
+		`{}`,
+		`{}`,
+		`{}`,
+	)
+	// Without a locator, the airport distance is unknown.
+	checkAlerts(t, *alerts, nil)
+}
+
+func TestAirportDistance(t *testing.T) {
+	lookups := 0
+	engine, alerts := newTestEngine(t, func(latitude, longitude, radius float64) (float64, bool, error) {
+		lookups++
+		if latitude > 50.05 {
+			return 1000, true, nil
+		}
+		return 10000, true, nil
+	})
+	replay(t, engine,
+		`{"height": 1500, "latitude": 50, "longitude": 8}`,
+		`{"height": 900, "latitude": 50, "longitude": 8}`, // 5.4 NM from the airport: fires without hold
+		`{"height": 800, "latitude": 50, "longitude": 8.001}`,
+		`{"height": 700, "latitude": 50.1, "longitude": 8}`, // moved 11 km: 0.5 NM
+	)
+	checkAlerts(t, *alerts, []expectedAlert{
+		{second: 1, state: StateActive, since: 1},
+		{second: 3, state: StateCleared, since: 1},
+	})
+	if (*alerts)[0].Severity != SeverityCritical {
+		t.Errorf("severity %s, want critical", (*alerts)[0].Severity)
+	}
+	if got := (*alerts)[0].Values[monikerAirportDistance]; got != 5.4 {
+		t.Errorf("airport distance %g, want 5.4", got)
+	}
+	// The second and third samples are less than 250 m and 10 s from the first.
+	if lookups != 2 {
+		t.Errorf("%d airport lookups, want 2", lookups)
+	}
+}
+
+// Without an airports database, the distance is unknown rather than the search
+// radius, or low_altitude would fire on every takeoff and landing.
+func TestAirportsUnavailable(t *testing.T) {
+	lookups := 0
+	engine, alerts := newTestEngine(t, func(latitude, longitude, radius float64) (float64, bool, error) {
+		lookups++
+		return 0, false, errors.New("airports database not available")
+	})
+	replay(t, engine,
+		`{"height": 500, "latitude": 50, "longitude": 8}`,
+		`{"height": 400, "latitude": 50, "longitude": 8}`,
+	)
+	checkAlerts(t, *alerts, nil)
+	if lookups != 2 {
+		t.Errorf("%d airport lookups, want 2", lookups)
+	}
+}
+
+func TestNoAirportWithinRadius(t *testing.T) {
+	engine, alerts := newTestEngine(t, func(latitude, longitude, radius float64) (float64, bool, error) {
+		return 0, false, nil
+	})
+	replay(t, engine, `{"height": 500, "latitude": 50, "longitude": 8}`)
+	checkAlerts(t, *alerts, []expectedAlert{{second: 0, state: StateActive, since: 0}})
+	if got := (*alerts)[0].Values[monikerAirportDistance]; got != 25 {
+		t.Errorf("airport distance %g, want the radius of 25 NM", got)
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	rules, err := ParseRules([]byte(`
+rules:
+  - name: zone
+    when:
+      - inside: [[50.10, 8.45], [50.10, 8.70], [49.96, 8.70], [49.96, 8.45]]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	alerts := make([]*Alert, 0)
+	engine := NewEngine(nil, func(alert *Alert) { alerts = append(alerts, alert) })
+	engine.SetRules(rules)
+	replay(t, engine,
+		`{"latitude": 50.2, "longitude": 8.5}`,
+		`{"latitude": 50.05, "longitude": 8.5}`,
+		`{"latitude": 50.05, "longitude": 8.8}`,
+	)
+	checkAlerts(t, alerts, []expectedAlert{
+		{second: 1, state: StateActive, since: 1},
+		{second: 2, state: StateCleared, since: 1},
+	})
+}
+
+// Changing the rules keeps the state of the remaining ones.
+func TestSetRulesKeepsState(t *testing.T) {
+	engine, alerts := newTestEngine(t, nil)
+	replay(t, engine, `{"bank": 40}`, `{"bank": 40}`, `{"bank": 40}`)
+	rules := engine.Rules()
+	engine.SetRules(&RuleSet{Rules: rules[:1]})
+	if active := engine.Active(); len(active) != 1 || active[0].ID != (*alerts)[0].ID {
+		t.Errorf("active alerts %v after changing the rules", active)
+	}
+	engine.SetRules(&RuleSet{Rules: rules[1:]})
+	if active := engine.Active(); len(active) != 0 {
+		t.Errorf("active alerts %v of a removed rule", active)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		err   string
+	}{
+		{name: "no name", rules: "rules: [{when: [{var: bank, above: 1}]}]", err: "name must not be empty"},
+		{name: "duplicate", rules: "rules: [{name: a, when: [{var: bank, above: 1}]}, {name: a, when: [{var: bank, above: 1}]}]", err: "duplicate name"},
+		{name: "severity", rules: "rules: [{name: a, severity: fatal, when: [{var: bank, above: 1}]}]", err: "unknown severity"},
+		{name: "negative hold", rules: "rules: [{name: a, hold: -1, when: [{var: bank, above: 1}]}]", err: "must not be negative"},
+		{name: "no conditions", rules: "rules: [{name: a}]", err: "no conditions"},
+		{name: "unknown var", rules: "rules: [{name: a, when: [{var: altitud, above: 1}]}]", err: "unknown var"},
+		{name: "no threshold", rules: "rules: [{name: a, when: [{var: bank}]}]", err: "needs above and/or below"},
+		{name: "threshold without var", rules: "rules: [{name: a, when: [{above: 1}]}]", err: "need a var"},
+		{name: "short polygon", rules: "rules: [{name: a, when: [{inside: [[1, 2], [3, 4]]}]}]", err: "at least 3 points"},
+		{name: "var and polygon", rules: "rules: [{name: a, when: [{var: bank, above: 1, outside: [[1, 2], [3, 4], [5, 6]]}]}]", err: "exactly one"},
+		{name: "unknown field", rules: "rules: [{name: a, when: [{var: bank, over: 1}]}]", err: "over"},
+	}
+	for _, test := range tests {
+		_, err := ParseRules([]byte(test.rules))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %v, want %q", test.name, err, test.err)
+		}
+	}
+
+	rules, err := LoadRules("../../configs/alerts.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range rules.Rules {
+		if rule.Severity == "" {
+			t.Errorf("rule %s has no severity", rule.Name)
+		}
+	}
+}
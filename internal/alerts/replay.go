@@ -0,0 +1,50 @@
+package alerts
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+type recordedSample struct {
+	Time time.Time              `json:"time"`
+	Data map[string]interface{} `json:"data"`
+}
+
+// Replay feeds recorded samples to the engine, e.g. to check rules against a
+// flight recorded with `gopilot-cli watch --format ndjson`. Every line is a
+// JSON object {"time": ..., "data": {...}} where the keys of data are either
+// monikers (e.g. "height") or SimVar names (e.g. "PLANE ALT ABOVE GROUND").
+func Replay(engine *Engine, r io.Reader) error {
+	monikers := make(map[string]string, len(vars))
+	for _, v := range vars {
+		monikers[v.Name] = v.Moniker
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		recorded := recordedSample{}
+		if err := json.Unmarshal(scanner.Bytes(), &recorded); err != nil {
+			return fmt.Errorf("line %d: %s", line, err.Error())
+		}
+		sample := telemetry.NewSample(recorded.Time)
+		for key, value := range recorded.Data {
+			if moniker, ok := monikers[key]; ok {
+				key = moniker
+			}
+			sample.Set(key, value)
+		}
+		engine.Consume(sample)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,197 @@
+package alerts
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// RuleSet is the content of a rules file.
+type RuleSet struct {
+	Rules []*Rule `yaml:"rules" json:"rules"`
+}
+
+// Rule triggers an alert once all of its conditions hold.
+type Rule struct {
+	Name     string      `yaml:"name" json:"name"`
+	Message  string      `yaml:"message" json:"message"`
+	Severity string      `yaml:"severity" json:"severity"`
+	When     []Condition `yaml:"when" json:"when"`
+	// Hold is the number of seconds the conditions have to hold before the
+	// alert is triggered.
+	Hold float64 `yaml:"hold" json:"hold"`
+	// Repeat is the number of seconds after which an active alert is sent
+	// again. 0 sends it once.
+	Repeat float64 `yaml:"repeat" json:"repeat"`
+}
+
+// Condition is either a comparison of a variable with a threshold, or a test
+// whether the aircraft is inside or outside a polygon.
+type Condition struct {
+	Var     string   `yaml:"var,omitempty" json:"var,omitempty"`
+	Above   *float64 `yaml:"above,omitempty" json:"above,omitempty"`
+	Below   *float64 `yaml:"below,omitempty" json:"below,omitempty"`
+	Abs     bool     `yaml:"abs,omitempty" json:"abs,omitempty"`
+	Inside  []Point  `yaml:"inside,omitempty" json:"inside,omitempty"`
+	Outside []Point  `yaml:"outside,omitempty" json:"outside,omitempty"`
+}
+
+// Point is a [latitude, longitude] pair.
+type Point [2]float64
+
+func LoadRules(path string) (*RuleSet, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRules(buf)
+}
+
+func ParseRules(buf []byte) (*RuleSet, error) {
+	rules := &RuleSet{}
+	if err := yaml.UnmarshalStrict(buf, rules); err != nil {
+		return nil, err
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Validate checks the rules and fills in the default severity.
+func (rs *RuleSet) Validate() error {
+	names := make(map[string]bool)
+	for i, rule := range rs.Rules {
+		if rule == nil || strings.TrimSpace(rule.Name) == "" {
+			return fmt.Errorf("rule #%d: name must not be empty", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("rule %q: duplicate name", rule.Name)
+		}
+		names[rule.Name] = true
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule %q: %s", rule.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+func (rule *Rule) validate() error {
+	switch rule.Severity {
+	case "":
+		rule.Severity = SeverityWarning
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+	default:
+		return fmt.Errorf("unknown severity %q, expected one of info, warning, critical", rule.Severity)
+	}
+	if rule.Hold < 0 || rule.Repeat < 0 {
+		return fmt.Errorf("hold and repeat must not be negative")
+	}
+	if len(rule.When) == 0 {
+		return fmt.Errorf("no conditions")
+	}
+	for i := range rule.When {
+		if err := rule.When[i].validate(); err != nil {
+			return fmt.Errorf("condition #%d: %s", i+1, err.Error())
+		}
+	}
+	return nil
+}
+
+func (cond *Condition) validate() error {
+	kinds := 0
+	if cond.Var != "" {
+		kinds++
+		if !isKnownVar(cond.Var) {
+			return fmt.Errorf("unknown var %q", cond.Var)
+		}
+		if cond.Above == nil && cond.Below == nil {
+			return fmt.Errorf("var %q needs above and/or below", cond.Var)
+		}
+	} else if cond.Above != nil || cond.Below != nil || cond.Abs {
+		return fmt.Errorf("above, below and abs need a var")
+	}
+	for _, polygon := range [][]Point{cond.Inside, cond.Outside} {
+		if polygon == nil {
+			continue
+		}
+		kinds++
+		if len(polygon) < 3 {
+			return fmt.Errorf("a polygon needs at least 3 points")
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("expected exactly one of var, inside, outside")
+	}
+	return nil
+}
+
+// Vars returns the variables the rule depends on.
+func (rule *Rule) Vars() []string {
+	vars := make([]string, 0, len(rule.When))
+	for _, cond := range rule.When {
+		if cond.Var != "" {
+			vars = append(vars, cond.Var)
+		} else {
+			vars = append(vars, monikerLatitude, monikerLongitude)
+		}
+	}
+	return vars
+}
+
+// holds reports whether the condition holds for the given values. Conditions
+// on missing values do not hold.
+func (cond *Condition) holds(values map[string]float64) bool {
+	switch {
+	case cond.Var != "":
+		value, ok := values[cond.Var]
+		if !ok {
+			return false
+		}
+		if cond.Abs && value < 0 {
+			value = -value
+		}
+		if cond.Above != nil && value <= *cond.Above {
+			return false
+		}
+		if cond.Below != nil && value >= *cond.Below {
+			return false
+		}
+		return true
+	case cond.Inside != nil || cond.Outside != nil:
+		lat, ok1 := values[monikerLatitude]
+		lon, ok2 := values[monikerLongitude]
+		if !ok1 || !ok2 {
+			return false
+		}
+		if cond.Inside != nil {
+			return insidePolygon(cond.Inside, lat, lon)
+		}
+		return !insidePolygon(cond.Outside, lat, lon)
+	}
+	return false
+}
+
+// insidePolygon uses the even-odd rule on plain latitude/longitude, which is
+// good enough for areas that do not cross the antimeridian or a pole.
+func insidePolygon(polygon []Point, lat, lon float64) bool {
+	inside := false
+	j := len(polygon) - 1
+	for i := range polygon {
+		lati, loni := polygon[i][0], polygon[i][1]
+		latj, lonj := polygon[j][0], polygon[j][1]
+		if (lati > lat) != (latj > lat) && lon < (lonj-loni)*(lat-lati)/(latj-lati)+loni {
+			inside = !inside
+		}
+		j = i
+	}
+	return inside
+}
@@ -0,0 +1,71 @@
+package alerts
+
+import (
+	"context"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Watcher polls the rules file and applies the rules to the engine whenever
+// the file's modification time or size changes. Invalid rules are reported
+// and the previous rules are kept.
+type Watcher struct {
+	path     string
+	interval time.Duration
+	engine   *Engine
+	modTime  time.Time
+	size     int64
+}
+
+func NewWatcher(path string, interval time.Duration, engine *Engine) *Watcher {
+	return &Watcher{
+		path:     path,
+		interval: interval,
+		engine:   engine,
+		size:     -1,
+	}
+}
+
+// Load loads the rules file once.
+func (watcher *Watcher) Load() error {
+	watcher.modTime, watcher.size = watcher.stat()
+	rules, err := LoadRules(watcher.path)
+	if err != nil {
+		return err
+	}
+	watcher.engine.SetRules(rules)
+	log.Infof("Loaded %d alert rule(s) from {%s}", len(rules.Rules), watcher.path)
+	return nil
+}
+
+func (watcher *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(watcher.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			modTime, size := watcher.stat()
+			if modTime.Equal(watcher.modTime) && size == watcher.size {
+				continue
+			}
+			log.Infof("Alert rules {%s} changed. Reloading...", watcher.path)
+			if err := watcher.Load(); err != nil {
+				log.Error("Ignoring alert rules change: ", err)
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (watcher *Watcher) stat() (time.Time, int64) {
+	info, err := os.Stat(watcher.path)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"msfs2020-gopilot/internal/alerts"
+	"msfs2020-gopilot/internal/geo"
+	"msfs2020-gopilot/internal/util"
+	"msfs2020-gopilot/internal/webserver"
+
+	alphafoxtrot "github.com/grumpypixel/go-airport-finder"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	alertRulesWatchInterval = 2 // seconds
+	alertWebhookTimeout     = 5 // seconds
+)
+
+type alertService struct {
+	engine  *alerts.Engine
+	watcher *alerts.Watcher
+	webhook *http.Client
+}
+
+func (app *App) initAlerts() {
+	engine := alerts.NewEngine(app.nearestAirportDistance, app.onAlert)
+	watcher := alerts.NewWatcher(app.cfg.Alerts.RulesFile, alertRulesWatchInterval*time.Second, engine)
+	// Invalid rules are not fatal, the watcher picks up the fixed file.
+	if err := watcher.Load(); err != nil {
+		log.Error("Unable to load alert rules: ", err)
+	}
+	app.alerts = &alertService{
+		engine:  engine,
+		watcher: watcher,
+		webhook: &http.Client{Timeout: alertWebhookTimeout * time.Second},
+	}
+	app.AddConsumer("alerts", engine)
+}
+
+func (app *App) nearestAirportDistance(latitude, longitude, radius float64) (float64, bool, error) {
+	if app.airportFinder == nil {
+		return 0, false, errAirportsNotAvailable
+	}
+	airport := app.airportFinder.FindNearestAirport(latitude, longitude, radius, alphafoxtrot.AirportTypeActive)
+	if airport == nil {
+		return 0, false, nil
+	}
+	return geo.Distance(latitude, longitude, airport.LatitudeDeg, airport.LongitudeDeg), true, nil
+}
+
+func (app *App) onAlert(alert *alerts.Alert) {
+	if alert.State == alerts.StateActive {
+		log.Warnf("Alert %s: %s", alert.Rule, alert.Message)
+	} else {
+		log.Infof("Alert %s cleared", alert.Rule)
+	}
+
+	msg := map[string]interface{}{
+		"type": "alert",
+		"data": alert,
+	}
+	buf, err := json.Marshal(msg)
+	if err != nil {
+		app.metrics.messagesDropped.Inc(dropReasonEncodingFailed)
+		return
+	}
+	app.broadcast("alert", buf)
+
+	if url := app.cfg.Alerts.WebhookURL; url != "" {
+		go app.postAlert(url, buf)
+	}
+}
+
+func (app *App) postAlert(url string, buf []byte) {
+	resp, err := app.alerts.webhook.Post(url, contentTypeJSON, bytes.NewReader(buf))
+	if err != nil {
+		log.Warn("Alert webhook failed: ", util.RedactURLError(err))
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Warnf("Alert webhook returned %s", resp.Status)
+	}
+}
+
+func (app *App) alertRoutes(headers map[string]string) []webserver.Route {
+	if app.alerts == nil {
+		return nil
+	}
+	return []webserver.Route{
+		{Pattern: "/api/alerts", Handler: app.alertsHandler(headers)},
+	}
+}
+
+func (app *App) alertsHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		data := map[string]interface{}{
+			"rules":  app.alerts.engine.Rules(),
+			"active": app.alerts.engine.Active(),
+		}
+		writeJSON(w, headers, http.StatusOK, data)
+	}
+}
@@ -1,19 +1,26 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"msfs2020-gopilot/internal/config"
+	"msfs2020-gopilot/internal/flightlog"
+	"msfs2020-gopilot/internal/ownship"
+	"msfs2020-gopilot/internal/resources"
+	"msfs2020-gopilot/internal/session"
+	"msfs2020-gopilot/internal/telemetry"
 	"msfs2020-gopilot/internal/util"
+	"msfs2020-gopilot/internal/webhooks"
 	"msfs2020-gopilot/internal/webserver"
 	"msfs2020-gopilot/internal/websockets"
 	"net"
 	"net/http"
-	"os"
-	"os/signal"
-	"path/filepath"
+	"path"
+	"reflect"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 
 	"github.com/buger/jsonparser"
@@ -31,104 +38,330 @@ type Message struct {
 
 const (
 	appTitle                   = "MSFS2020-GoPilot"
-	dataDir                    = "data/"
-	airportsDataDir            = dataDir + "/ourairports"
+	airportsDataDir            = "ourairports"
 	contentTypeHTML            = "text/html"
 	contentTypeText            = "text/plain; charset=utf-8"
+	contentTypeJSON            = "application/json; charset=utf-8"
 	defaultAirportSearchRadius = 50 * 1000.0
 	defaultMaxAirportCount     = 10
+	maxAirportCount            = 100
 	connectRetryInterval       = 1 // seconds
 	receiveDataInterval        = 1 // milliseconds
-	shutdownDelay              = 3 // seconds
+	shutdownTimeout            = 5 // seconds
+	eventHandlerStopTimeout    = 1 // seconds
 	broadcastInterval          = 250
 	// dataRequestInterval        = 200 // milliseconds
 )
 
+var (
+	errNotConnected         = errors.New("not connected to SimConnect")
+	errAirportsNotAvailable = errors.New("airports database not available")
+)
+
 type App struct {
-	cfg              *config.Config
-	requestManager   *RequestManager
-	socket           *websockets.WebSocket
-	mate             *simconnect.SimMate
-	airportFinder    *alphafoxtrot.AirportFinder
-	done             chan interface{}
-	flightSimVersion string
-	eventListener    *simconnect.EventListener
-}
-
-func NewApp(cfg *config.Config) *App {
-	return &App{
+	cfg            *config.Config
+	resources      *resources.Resources
+	requestManager *RequestManager
+	socket         *websockets.WebSocket
+	mate           *simconnect.SimMate
+	airportFinder  *alphafoxtrot.AirportFinder
+	quit           context.CancelFunc
+	simulatorInfo  *SimulatorInfo
+	eventListener  *simconnect.EventListener
+	metrics        *appMetrics
+	feeds          *feedMap
+	maneuvers      *maneuverService
+	logbook        *logbookService
+	landings       *landingService
+	alerts         *alertService
+	traffic        *trafficService
+	ownship        *ownship.Tracker
+	flights        *flightlog.Tracker
+	gdl90          *gdl90Service
+	nmea           *nmeaService
+	xplane         *xplaneService
+	mqtt           *mqttService
+	grpc           *grpcService
+	webhooks       *webhooks.Dispatcher
+	webServer      *webserver.WebServer
+	presence       *presenceService
+	sessions       *session.Store
+	services       sync.WaitGroup
+	stopServices   context.CancelFunc
+	clientEvents   *clientEventMap
+	eventHandler   chan interface{}
+	cfgMutex       sync.Mutex
+	// simVarsMutex makes registering and releasing SimVars atomic with adding
+	// and removing the requests which use them.
+	simVarsMutex sync.Mutex
+	infoMutex    sync.Mutex
+}
+
+func NewApp(cfg *config.Config, res *resources.Resources) *App {
+	app := &App{
 		cfg:            cfg,
+		resources:      res,
 		requestManager: NewRequestManager(),
-		done:           make(chan interface{}, 1),
 		airportFinder:  alphafoxtrot.NewAirportFinder(),
+		feeds:          newFeedMap(),
+		clientEvents:   newClientEventMap(),
+	}
+	app.metrics = newAppMetrics(app)
+	app.initManeuvers()
+	if cfg.Sessions.Enabled {
+		app.initSessions()
+	}
+	if cfg.Logbook.Enabled {
+		app.initLogbook()
+	}
+	if cfg.Landings.Enabled {
+		app.initLandings()
+	}
+	if cfg.Alerts.Enabled {
+		app.initAlerts()
+	}
+	if cfg.Traffic.Enabled {
+		app.initTraffic()
+	}
+	if cfg.GDL90.Enabled {
+		app.initGDL90()
+	}
+	if cfg.NMEA.Enabled {
+		app.initNMEA()
+	}
+	if cfg.XPlane.Enabled {
+		app.initXPlane()
+	}
+	if cfg.MQTT.Enabled {
+		app.initMQTT()
 	}
+	if cfg.Webhooks.Enabled {
+		app.initWebhooks()
+	}
+	if cfg.Presence.Enabled {
+		app.initPresence()
+	}
+	if cfg.GRPC.Enabled {
+		app.initGRPC()
+	}
+	return app
 }
 
-func (app *App) Run() error {
-	app.addEventListeners()
+// Run serves until ctx is cancelled or the simulator quits, then shuts down
+// in order: the event handler, the WebSocket clients, the web server, the
+// services and finally the connection with the simulator. Errors which prevent
+// GoPilot from starting are returned.
+func (app *App) Run(ctx context.Context) error {
+	ctx, app.quit = context.WithCancel(ctx)
+	defer app.quit()
 
-	app.socket = websockets.NewWebSocket()
-	go app.handleSocketMessages()
+	app.addEventListeners()
 
-	serverShutdown := make(chan bool, 1)
-	defer close(serverShutdown)
+	app.socket = websockets.NewWebSocket(websockets.Options{
+		QueueSize:         int(app.cfg.WebSocket.QueueSize),
+		SlowClientTimeout: time.Duration(app.cfg.WebSocket.SlowClientTimeout) * time.Second,
+		OnDrop: func(reason string) {
+			app.metrics.messagesDropped.Inc(reason)
+		},
+		OnSlowClient: func(connection *websockets.Connection) {
+			app.metrics.slowClients.Inc()
+		},
+	})
+
+	// The services outlive ctx so that they keep working until the clients are
+	// gone, see shutdown.
+	var services context.Context
+	services, app.stopServices = context.WithCancel(context.Background())
+	app.startService(services, app.handleSocketMessages)
 
 	app.listNetworkInterfaces()
 
-	log.Info("Loading airport database...")
-	airportFinderOptions := alphafoxtrot.PresetLoadOptions(airportsDataDir)
-	airportFinderFilter := alphafoxtrot.AirportTypeAll
-	if errs := app.airportFinder.Load(airportFinderOptions, airportFinderFilter); len(errs) > 0 {
-		log.Warn("Airport finder will not be available, because of the following errors:")
-		for err := range errs {
-			log.Error(err)
-		}
-		app.airportFinder = nil
-	}
+	app.loadAirports()
 
 	log.Info("Loading ", simconnect.SimConnectDLL, "...")
 	if err := simconnect.Initialize(app.cfg.SimConnectDLLPath); err != nil {
+		app.shutdown()
 		return err
 	}
 
 	app.mate = simconnect.NewSimMate()
-	app.initWebServer(app.cfg.ServerAddress, serverShutdown)
+	app.registerFeeds()
+	if err := app.initWebServer(app.cfg.ServerAddress); err != nil {
+		app.shutdown()
+		return err
+	}
+
+	app.startService(services, func(ctx context.Context) {
+		app.Broadcast(ctx, broadcastInterval*time.Millisecond)
+	})
+
+	if app.alerts != nil {
+		app.startService(services, app.alerts.watcher.Run)
+	}
+
+	if app.gdl90 != nil {
+		app.startService(services, app.runGDL90)
+	}
+
+	if app.nmea != nil {
+		app.startService(services, app.runNMEA)
+	}
+
+	if app.xplane != nil {
+		app.startService(services, app.runXPlane)
+	}
+
+	if app.mqtt != nil {
+		app.startService(services, app.runMQTT)
+	}
 
-	stopBroadcast := make(chan interface{}, 1)
-	defer close(stopBroadcast)
-	go app.Broadcast(broadcastInterval*time.Millisecond, stopBroadcast)
+	if app.grpc != nil {
+		app.startService(services, app.runGRPC)
+	}
+
+	if app.webhooks != nil {
+		app.startService(services, app.webhooks.Run)
+	}
+
+	if app.presence != nil {
+		app.startService(services, app.runPresence)
+	}
 
 	retryInterval := connectRetryInterval * time.Second
 	timeout := time.Second * time.Duration(app.cfg.ConnectionTimeout)
-	if err := app.connect(app.cfg.ConnectionName, retryInterval, timeout); err != nil {
+	if err := app.connect(ctx, app.cfg.ConnectionName, retryInterval, timeout); err != nil {
+		log.Info("Shutting down...")
+		app.shutdown()
+		if ctx.Err() != nil {
+			// Cancelled while waiting for the simulator.
+			return nil
+		}
 		return err
 	}
 
-	go app.handleTerminationSignal()
-
-	stopEventHandler := make(chan interface{}, 1)
-	defer close(stopEventHandler)
+	if app.traffic != nil {
+		app.startService(services, app.runTraffic)
+	}
 
-	requestInterval := time.Duration(app.cfg.DataRequestInterval) * time.Millisecond
-	receiveInterval := receiveDataInterval * time.Millisecond
-	go app.mate.HandleEvents(requestInterval, receiveInterval, stopEventHandler, app.eventListener)
+	app.cfgMutex.Lock()
+	app.startEventHandler(app.cfg.DataRequestInterval)
+	app.cfgMutex.Unlock()
 
-	<-app.done
-	defer close(app.done)
+	<-ctx.Done()
 
 	log.Info("Shutting down...")
 
-	stopEventHandler <- true
-	stopBroadcast <- true
-	serverShutdown <- true
+	app.cfgMutex.Lock()
+	app.stopEventHandler()
+	app.cfgMutex.Unlock()
 
-	if err := app.disconnect(); err != nil {
-		return err
+	app.shutdown()
+
+	return app.disconnect()
+}
+
+// startService runs a service until ctx is cancelled. shutdown waits for it to
+// return.
+func (app *App) startService(ctx context.Context, service func(context.Context)) {
+	app.services.Add(1)
+	go func() {
+		defer app.services.Done()
+		service(ctx)
+	}()
+}
+
+// shutdown closes the WebSocket connections, stops the web server and then
+// the services. Each step gets what is left of shutdownTimeout.
+func (app *App) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout*time.Second)
+	defer cancel()
+
+	app.socket.Shutdown(ctx, "GoPilot is shutting down")
+	if app.webServer != nil {
+		if err := app.webServer.Shutdown(ctx); err != nil {
+			log.Warn("Web server did not shut down cleanly: ", err)
+		}
 	}
 
-	log.Info("Taking a quick nap...")
-	time.Sleep(shutdownDelay * time.Second)
-	return nil
+	app.stopServices()
+	done := make(chan struct{})
+	go func() {
+		app.services.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Warn("Some services did not stop in time")
+	}
+}
+
+func (app *App) loadAirports() {
+	log.Info("Loading airport database...")
+	dir, err := app.resources.DataDir(airportsDataDir)
+	if err != nil {
+		log.Warn("Airport finder will not be available: ", err)
+		app.airportFinder = nil
+		return
+	}
+	airportFinderOptions := alphafoxtrot.PresetLoadOptions(dir)
+	airportFinderFilter := alphafoxtrot.AirportTypeAll
+	if errs := app.airportFinder.Load(airportFinderOptions, airportFinderFilter); len(errs) > 0 {
+		log.Warn("Airport finder will not be available, because of the following errors:")
+		for _, err := range errs {
+			log.Error(err)
+		}
+		app.airportFinder = nil
+	}
+}
+
+func (app *App) startEventHandler(dataRequestInterval int64) {
+	app.eventHandler = make(chan interface{})
+	requestInterval := time.Duration(dataRequestInterval) * time.Millisecond
+	receiveInterval := receiveDataInterval * time.Millisecond
+	go app.mate.HandleEvents(requestInterval, receiveInterval, app.eventHandler, app.eventListener)
+}
+
+func (app *App) stopEventHandler() {
+	if app.eventHandler == nil {
+		return
+	}
+	// The channel is unbuffered so that the event handler has returned
+	// before a new one may be started.
+	select {
+	case app.eventHandler <- true:
+	case <-time.After(eventHandlerStopTimeout * time.Second):
+		log.Warn("Event handler did not stop in time")
+	}
+	close(app.eventHandler)
+	app.eventHandler = nil
+}
+
+// ApplyConfig applies a changed configuration at runtime. Only the log level and
+// the data request interval can be changed on the fly; all other settings
+// require a restart.
+func (app *App) ApplyConfig(cfg *config.Config) {
+	app.cfgMutex.Lock()
+	defer app.cfgMutex.Unlock()
+
+	if cfg.LogLevel != app.cfg.LogLevel {
+		log.Infof("Changing log level from %s to %s", app.cfg.LogLevel, cfg.LogLevel)
+		log.SetLevel(cfg.Level())
+		app.cfg.LogLevel = cfg.LogLevel
+	}
+
+	if cfg.DataRequestInterval != app.cfg.DataRequestInterval {
+		log.Infof("Changing data request interval from %d to %d ms", app.cfg.DataRequestInterval, cfg.DataRequestInterval)
+		app.cfg.DataRequestInterval = cfg.DataRequestInterval
+		if app.eventHandler != nil {
+			app.stopEventHandler()
+			app.startEventHandler(cfg.DataRequestInterval)
+		}
+	}
+
+	if !reflect.DeepEqual(app.cfg, cfg) {
+		log.Warn("Some configuration changes will only take effect after a restart")
+	}
 }
 
 func (app *App) addEventListeners() {
@@ -141,30 +374,50 @@ func (app *App) addEventListeners() {
 	}
 }
 
-func (app *App) initWebServer(address string, shutdown chan bool) {
+func (app *App) initWebServer(address string) error {
 	htmlHeaders := app.Headers(contentTypeHTML)
 	textHeaders := app.Headers(contentTypeText)
-	webServer := webserver.NewWebServer(address, shutdown)
-	htmlDir := "assets/html"
+	jsonHeaders := app.Headers(contentTypeJSON)
+	// The debug endpoints are not meant for other web pages.
+	debugHeaders := app.Headers(contentTypeJSON)
+	delete(debugHeaders, "Access-Control-Allow-Origin")
+	webServer := webserver.NewWebServer(address)
+	htmlDir := "html"
 	routes := []webserver.Route{
-		{Pattern: "/", Handler: app.staticContentHandler(htmlHeaders, "/", filepath.Join(htmlDir, "vfrmap.html"))},
-		{Pattern: "/vfrmap", Handler: app.staticContentHandler(htmlHeaders, "/vfrmap", filepath.Join(htmlDir, "vfrmap.html"))},
-		{Pattern: "/mehmap", Handler: app.staticContentHandler(htmlHeaders, "/mehmap", filepath.Join(htmlDir, "mehmap.html"))},
-		{Pattern: "/setdata", Handler: app.staticContentHandler(htmlHeaders, "/setdata", filepath.Join(htmlDir, "setdata.html"))},
-		{Pattern: "/airports", Handler: app.staticContentHandler(htmlHeaders, "/airports", filepath.Join(htmlDir, "airports.html"))},
-		{Pattern: "/teleport", Handler: app.staticContentHandler(htmlHeaders, "/teleport", filepath.Join(htmlDir, "teleporter.html"))},
-		{Pattern: "/steepturns", Handler: app.staticContentHandler(htmlHeaders, "/steepturns", filepath.Join(htmlDir, "steepturns.html"))},
-		// {Pattern: "/experimental", Handler: app.staticContentHandler(htmlHeaders, "/experimental", filepath.Join(htmlDir, "experimental/index.html"))},
-		{Pattern: "/debug", Handler: app.generatedContentHandler(textHeaders, "/debug", app.DebugGenerator)},
+		{Pattern: "/", Handler: app.staticContentHandler(htmlHeaders, "/", path.Join(htmlDir, "vfrmap.html"))},
+		{Pattern: "/vfrmap", Handler: app.staticContentHandler(htmlHeaders, "/vfrmap", path.Join(htmlDir, "vfrmap.html"))},
+		{Pattern: "/mehmap", Handler: app.staticContentHandler(htmlHeaders, "/mehmap", path.Join(htmlDir, "mehmap.html"))},
+		{Pattern: "/setdata", Handler: app.staticContentHandler(htmlHeaders, "/setdata", path.Join(htmlDir, "setdata.html"))},
+		{Pattern: "/airports", Handler: app.staticContentHandler(htmlHeaders, "/airports", path.Join(htmlDir, "airports.html"))},
+		{Pattern: "/teleport", Handler: app.staticContentHandler(htmlHeaders, "/teleport", path.Join(htmlDir, "teleporter.html"))},
+		{Pattern: "/steepturns", Handler: app.staticContentHandler(htmlHeaders, "/steepturns", path.Join(htmlDir, "steepturns.html"))},
+		// {Pattern: "/experimental", Handler: app.staticContentHandler(htmlHeaders, "/experimental", path.Join(htmlDir, "experimental/index.html"))},
+		{Pattern: "/debug", Handler: app.debugHandler(debugHeaders)},
+		{Pattern: "/api/debug", Handler: app.debugHandler(debugHeaders)},
+		{Pattern: "/api/debug/connections/{uuid}/disconnect", Handler: app.adminOnly(app.disconnectClientHandler(debugHeaders))},
+		{Pattern: "/api/debug/requests/{id}", Handler: app.adminOnly(app.dropRequestHandler(debugHeaders))},
 		{Pattern: "/simvars", Handler: app.generatedContentHandler(textHeaders, "/simvars", app.simvarsGenerator)},
+		{Pattern: "/metrics", Handler: app.metrics.registry.Handler()},
 		{Pattern: "/ws", Handler: app.socket.Serve},
 	}
+	routes = append(routes, app.maneuverRoutes(jsonHeaders)...)
+	routes = append(routes, app.logbookRoutes(jsonHeaders)...)
+	routes = append(routes, app.landingRoutes(jsonHeaders)...)
+	routes = append(routes, app.alertRoutes(jsonHeaders)...)
+	routes = append(routes, app.trafficRoutes(jsonHeaders)...)
+	routes = append(routes, app.presenceRoutes(jsonHeaders)...)
+	routes = append(routes, app.overlayRoutes(htmlHeaders, jsonHeaders)...)
+	routes = append(routes, app.healthRoutes(jsonHeaders)...)
 
 	log.Info("Starting web server...")
 	staticAssetsDir := "/assets/"
-	webServer.Run(routes, staticAssetsDir)
+	if err := webServer.Run(routes, staticAssetsDir, app.resources.Assets()); err != nil {
+		return fmt.Errorf("unable to start the web server: %w", err)
+	}
+	app.webServer = webServer
 
 	log.Info("Web Server listening on ", address)
+	return nil
 }
 
 // https://golang-examples.tumblr.com/post/99458329439/get-local-ip-addresses
@@ -195,7 +448,7 @@ func (app *App) listNetworkInterfaces() {
 	log.Info("Your network interfaces:\n", str)
 }
 
-func (app *App) connect(name string, retryInterval, timeout time.Duration) error {
+func (app *App) connect(ctx context.Context, name string, retryInterval, timeout time.Duration) error {
 	log.Info("Trying to establish a connection with the Simulator...")
 	connectTicker := time.NewTicker(retryInterval)
 	defer connectTicker.Stop()
@@ -217,7 +470,8 @@ func (app *App) connect(name string, retryInterval, timeout time.Duration) error
 			}
 		case <-timeoutTimer.C:
 			return fmt.Errorf("establishing a connection with the simulator timed out painfully")
-		default:
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 	}
 }
@@ -230,39 +484,37 @@ func (app *App) disconnect() error {
 	return nil
 }
 
-func (app *App) handleTerminationSignal() {
-	sigterm := make(chan os.Signal, 1)
-	defer close(sigterm)
-	signal.Notify(sigterm, os.Interrupt, syscall.SIGTERM)
-
+func (app *App) handleSocketMessages(ctx context.Context) {
 	for {
 		select {
-		case <-sigterm:
-			log.Info("SIGTERM. Terminating process...")
-			app.done <- true
+		case <-ctx.Done():
 			return
-		}
-	}
-}
 
-func (app *App) handleSocketMessages() {
-	for {
-		select {
 		case event := <-app.socket.EventReceiver:
 			eventType := event.Type
 			connID := event.Connection.UUID()
 			switch eventType {
 			case websockets.SocketEventConnected:
 				log.Info("Client connected: ", connID)
+				if app.sessions != nil {
+					app.openSession(connID)
+				}
+				app.notify(webhooks.EventClientConnected, clientInfo(event.Connection))
 
 			case websockets.SocketEventDisconnected:
 				log.Info("Client disconnected: ", connID)
-				app.removeRequests(connID)
+				app.closeSession(connID)
+				app.notify(webhooks.EventClientDisconnected, clientInfo(event.Connection))
 
 			case websockets.SocketEventMessage:
 				msg := &Message{}
-				json.Unmarshal(event.Data, msg)
+				if err := json.Unmarshal(event.Data, msg); err != nil {
+					log.Warn("Received malformed message from ", connID, ": ", err)
+					app.metrics.messagesDropped.Inc(dropReasonMalformed)
+					continue
+				}
 				log.Debug("Message", connID, msg)
+				app.metrics.messagesIn.Inc(msg.Type)
 
 				switch msg.Type {
 				case "airports":
@@ -271,26 +523,35 @@ func (app *App) handleSocketMessages() {
 				case "deregister":
 					app.handleDeregisterMessage(msg, connID)
 
+				case "evaluate":
+					app.handleEvaluateMessage(msg, connID)
+
 				case "echo":
 					app.handleEchoMessage(msg, connID)
 
+				case "event":
+					app.sendResult(msg, connID, app.handleEventMessage(msg))
+
 				case "ping":
 					app.handlePingMessage(msg, connID)
 
 				case "register":
 					app.handleRegisterMessage(msg, event.Data, connID)
 
+				case "resume":
+					app.handleResumeMessage(msg, connID)
+
 				case "setdata":
-					app.handleSetDataMessage(msg)
+					app.sendResult(msg, connID, app.handleSetDataMessage(msg))
 
 				case "teleport":
-					app.handleTeleportMessage(msg)
+					app.sendResult(msg, connID, app.handleTeleportMessage(msg))
 
 				default:
-					log.Warn("Received unknown message with type: %s\n data: %v\n sender: %s\n", msg.Type, msg.Data, connID)
+					app.metrics.messagesDropped.Inc(dropReasonUnknownType)
+					log.Warnf("Received unknown message with type: %s\n data: %v\n sender: %s\n", msg.Type, msg.Data, connID)
 				}
 			}
-		default:
 		}
 	}
 }
@@ -298,10 +559,12 @@ func (app *App) handleSocketMessages() {
 func (app *App) handleAirportsMessage(msg *Message, connID string) {
 	latitude, ok := util.FloatFromJson("latitude", msg.Data)
 	if !ok {
+		app.sendResult(msg, connID, missingField("latitude"))
 		return
 	}
 	longitude, ok := util.FloatFromJson("longitude", msg.Data)
 	if !ok {
+		app.sendResult(msg, connID, missingField("longitude"))
 		return
 	}
 	radiusInMeters, ok := util.FloatFromJson("radius", msg.Data)
@@ -325,17 +588,12 @@ func (app *App) handleAirportsMessage(msg *Message, connID string) {
 	}
 
 	go func() {
-		if app.airportFinder == nil {
-			log.Warn("Airports database not available")
+		airports, err := app.findAirports(latitude, longitude, radiusInMeters, maxAirports, uint64(airportFilter))
+		if err != nil {
+			app.sendResult(msg, connID, err)
 			return
 		}
 
-		log.Info("Finding airports...")
-		airports := app.airportFinder.FindNearestAirports(latitude, longitude, radiusInMeters, maxAirports, uint64(airportFilter))
-		if len(airports) == 0 {
-			log.Info("No airports found around ", latitude, ", ", longitude)
-		}
-
 		airportList := make([]map[string]interface{}, 0)
 		for _, airport := range airports {
 			ap := make(map[string]interface{})
@@ -354,23 +612,43 @@ func (app *App) handleAirportsMessage(msg *Message, connID string) {
 			"data": airportList,
 		}
 
-		log.Infof("Found %d airports", len(airports))
 		if buf, err := json.Marshal(reply); err == nil {
-			app.socket.Send(connID, buf)
+			app.send(connID, "airports", buf)
 			log.Debug(airportList)
 		} else {
+			app.metrics.messagesDropped.Inc(dropReasonEncodingFailed)
 			log.Error(err)
 		}
 	}()
 }
 
+// findAirports returns at most maxAirportCount airports, however many are asked
+// for.
+func (app *App) findAirports(latitude, longitude, radius float64, maxAirports int, filter uint64) ([]*alphafoxtrot.Airport, error) {
+	if app.airportFinder == nil {
+		return nil, errAirportsNotAvailable
+	}
+	if maxAirports > maxAirportCount {
+		maxAirports = maxAirportCount
+	}
+	log.Info("Finding airports...")
+	start := time.Now()
+	airports := app.airportFinder.FindNearestAirports(latitude, longitude, radius, maxAirports, filter)
+	app.metrics.airportQueries.ObserveDuration(start)
+	if len(airports) == 0 {
+		log.Info("No airports found around ", latitude, ", ", longitude)
+	}
+	log.Infof("Found %d airports", len(airports))
+	return airports, nil
+}
+
 func (app *App) handleDeregisterMessage(msg *Message, connID string) {
 	app.removeRequests(connID)
 }
 
 func (app *App) handleEchoMessage(msg *Message, connID string) {
 	if buf, err := json.Marshal(msg); err == nil {
-		app.socket.Send(connID, buf)
+		app.send(connID, msg.Type, buf)
 	}
 }
 
@@ -378,78 +656,116 @@ func (app *App) handlePingMessage(msg *Message, connID string) {
 	reply := map[string]interface{}{
 		"type": "pong",
 		"meta": msg.Meta,
-		"data": time.Now().String,
+		"data": time.Now().String(),
 	}
 	if buf, err := json.Marshal(reply); err == nil {
-		app.socket.Send(connID, buf)
+		app.send(connID, "pong", buf)
 	}
 }
 
 func (app *App) handleRegisterMessage(msg *Message, raw []byte, connID string) {
-	request := NewRequest(connID, msg.Meta)
+	vars := make([]telemetry.Var, 0)
 	jsonparser.ArrayEach(raw, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
 		n, _, _, _ := jsonparser.Get(value, "name")
 		u, _, _, _ := jsonparser.Get(value, "unit")
 		t, _, _, _ := jsonparser.Get(value, "type")
 		m, _, _, _ := jsonparser.Get(value, "moniker")
-		name := string(n)
-		unit := string(u)
-		typ := simconnect.StringToDataType(string(t))
-		moniker := string(m)
-		defineID := app.mate.AddSimVar(name, unit, typ)
-		log.Info(fmt.Sprintf("Added SimVar with id: %d, name: %s, unit: %s, type: %d", defineID, name, unit, typ))
-		request.Add(defineID, name, moniker)
+		vars = append(vars, telemetry.Var{Name: string(n), Unit: string(u), Type: string(t), Moniker: string(m)})
 	}, "data")
-	app.requestManager.AddRequest(request)
+	request := NewRequest(connID, msg.Meta)
+	app.addRequest(request, vars)
 	log.Info("Added request ", request)
+	app.sendMonikers(request)
 }
 
-func (app *App) handleSetDataMessage(msg *Message) {
-	if !app.mate.IsConnected() {
-		log.Warn("Not connected to SimConnect. Ignoring SetDataMessage.")
-		return
+// addRequest registers the SimVars of a new request with SimConnect and adds
+// the request.
+func (app *App) addRequest(request *Request, vars []telemetry.Var) {
+	app.simVarsMutex.Lock()
+	defer app.simVarsMutex.Unlock()
+	for _, v := range vars {
+		typ := simconnect.StringToDataType(v.Type)
+		defineID := app.mate.AddSimVar(v.Name, v.Unit, typ)
+		log.Debug(fmt.Sprintf("Added SimVar with id: %d, name: %s, unit: %s, type: %d", defineID, v.Name, v.Unit, typ))
+		request.Add(defineID, v.Name, v.Moniker)
 	}
+	app.requestManager.AddRequest(request)
+}
+
+// dropRequests removes the requests which match and releases the SimVars
+// which are no longer used by any request.
+func (app *App) dropRequests(match func(request *Request) bool) []*Request {
+	app.simVarsMutex.Lock()
+	defer app.simVarsMutex.Unlock()
+	removed := app.requestManager.RemoveRequests(match)
+	for _, request := range removed {
+		for defineID, v := range request.Vars {
+			if app.requestManager.RefCount(v.Name) == 0 {
+				app.mate.RemoveSimVar(defineID)
+				log.Debug("Removed SimVar", defineID)
+			}
+		}
+	}
+	app.removeFeeds(removed)
+	return removed
+}
+
+func (app *App) handleSetDataMessage(msg *Message) error {
 	name, ok := util.StringFromJson("name", msg.Data)
 	if !ok {
-		return
+		return missingField("name")
 	}
 	unit, ok := util.StringFromJson("unit", msg.Data)
 	if !ok {
-		return
+		return missingField("unit")
 	}
 	value, ok := util.FloatFromJson("value", msg.Data)
 	if !ok {
-		return
+		return missingField("value")
 	}
-	if err := app.mate.SetSimObjectData(name, unit, value, simconnect.DataTypeFloat64); err != nil {
-		log.Error(err)
+	if err := app.setData(name, unit, value); err != nil {
+		return err
 	}
+	return nil
 }
 
-func (app *App) handleTeleportMessage(msg *Message) {
+func (app *App) setData(name, unit string, value float64) error {
 	if !app.mate.IsConnected() {
-		log.Warn("Not connected to SimConnect. Ignoring TeleportMessage.")
-		return
+		return errNotConnected
 	}
+	return app.mate.SetSimObjectData(name, unit, value, simconnect.DataTypeFloat64)
+}
+
+func (app *App) handleTeleportMessage(msg *Message) error {
 	latitude, ok := util.FloatFromJson("latitude", msg.Data)
 	if !ok {
-		return
+		return missingField("latitude")
 	}
 	longitude, ok := util.FloatFromJson("longitude", msg.Data)
 	if !ok {
-		return
+		return missingField("longitude")
 	}
 	altitude, ok := util.FloatFromJson("altitude", msg.Data)
 	if !ok {
-		return
+		return missingField("altitude")
 	}
 	heading, ok := util.FloatFromJson("heading", msg.Data)
 	if !ok {
-		return
+		return missingField("heading")
 	}
 	airspeed, ok := util.FloatFromJson("airspeed", msg.Data)
 	if !ok {
-		return
+		return missingField("airspeed")
+	}
+	if err := app.teleport(latitude, longitude, altitude, heading, airspeed); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (app *App) teleport(latitude, longitude, altitude, heading, airspeed float64) error {
+	if !app.mate.IsConnected() {
+		return errNotConnected
 	}
 
 	bank := 0.0
@@ -463,44 +779,53 @@ func (app *App) handleTeleportMessage(msg *Message) {
 	app.mate.SetSimObjectData("PLANE BANK DEGREES", "degrees", bank, simconnect.DataTypeFloat64)
 	app.mate.SetSimObjectData("PLANE PITCH DEGREES", "degrees", pitch, simconnect.DataTypeFloat64)
 
-	log.Info("Teleporting to lat: %f lng: %f alt: %f hdg: %f spd: %f bnk: %f pit: %f",
+	log.Infof("Teleporting to lat: %f lng: %f alt: %f hdg: %f spd: %f bnk: %f pit: %f",
 		latitude, longitude, altitude, heading, airspeed, bank, pitch)
+	app.notify(webhooks.EventTeleport, map[string]float64{
+		"latitude":  latitude,
+		"longitude": longitude,
+		"altitude":  altitude,
+		"heading":   heading,
+		"airspeed":  airspeed,
+	})
+	return nil
 }
 
 func (app *App) removeRequests(connID string) {
-	temp := make([]*Request, 0)
-	removed := make([]*Request, 0)
-	for _, request := range app.requestManager.Requests {
-		if request.ClientID != connID {
-			temp = append(temp, request)
-		} else {
-			removed = append(removed, request)
-		}
-	}
-	app.requestManager.Requests = temp
-	for _, request := range removed {
-		for defineID, v := range request.Vars {
-			count := app.requestManager.RefCount(v.Name)
-			if count == 0 {
-				app.mate.RemoveSimVar(defineID)
-				log.Debug("Removed SimVar", defineID)
-			}
-		}
-	}
+	app.dropRequests(func(request *Request) bool {
+		return request.ClientID() == connID
+	})
 }
 
 func (app *App) OnOpen(applName, applVersion, applBuild, simConnectVersion, simConnectBuild string) {
 	log.Info("Connected \\o/")
-	app.flightSimVersion = fmt.Sprintf(
-		"Flight Simulator says:\n Name: %s\n Version: %s (build %s)\n SimConnect: %s (build %s)",
+	info := &SimulatorInfo{
+		ApplicationName:    applName,
+		ApplicationVersion: applVersion,
+		ApplicationBuild:   applBuild,
+		SimConnectVersion:  simConnectVersion,
+		SimConnectBuild:    simConnectBuild,
+	}
+	app.infoMutex.Lock()
+	app.simulatorInfo = info
+	app.infoMutex.Unlock()
+	log.Infof("Flight Simulator says:\n Name: %s\n Version: %s (build %s)\n SimConnect: %s (build %s)",
 		applName, applVersion, applBuild, simConnectVersion, simConnectBuild)
-	log.Info(app.flightSimVersion)
 	log.Info("CLEAR PROP!")
+	app.notify(webhooks.EventSimConnected, info)
+}
+
+// simulator returns the simulator GoPilot is connected to, if any.
+func (app *App) simulator() *SimulatorInfo {
+	app.infoMutex.Lock()
+	defer app.infoMutex.Unlock()
+	return app.simulatorInfo
 }
 
 func (app *App) OnQuit() {
 	log.Info("Disconnected (︶︹︶)")
-	app.done <- true
+	app.notify(webhooks.EventSimDisconnected, nil)
+	app.quit()
 }
 
 func (app *App) OnEventID(eventID simconnect.DWord) {
@@ -520,50 +845,70 @@ func (app *App) OnException(exceptionCode simconnect.DWord) {
 // }
 
 func (app *App) OnDataReady() {
-	for _, request := range app.requestManager.Requests {
+	start := time.Now()
+	defer app.metrics.dataReadyDuration.ObserveDuration(start)
+
+	now := time.Now()
+	for _, request := range app.requestManager.All() {
+		vars := app.requestValues(request)
+		if feed, ok := app.feeds.get(request.ID); ok {
+			feed.consume(now, vars)
+			continue
+		}
+
+		recipient := request.ClientID()
+		encoding, ok := app.socket.Encoding(recipient)
+		if ok && websockets.IsBinary(encoding) {
+			app.sendBinarySimVars(request, encoding, vars)
+			continue
+		}
 		msg := map[string]interface{}{
 			"type": "simvars",
 			"meta": request.Meta,
+			"data": vars,
 		}
+		if buf, err := json.Marshal(msg); err == nil {
+			app.sendMessage(recipient, "simvars", &websockets.Message{Data: buf, Key: simVarsKey(request)})
+		} else {
+			app.metrics.messagesDropped.Inc(dropReasonEncodingFailed)
+		}
+	}
+}
 
-		vars := make(map[string]interface{})
-		for defineID, v := range request.Vars {
-			value, dataType, ok := app.mate.SimVarValueAndDataType(defineID)
-			if !ok || value == nil {
-				continue
-			}
-			switch dataType {
-			case simconnect.DataTypeInt32:
-				vars[v.Moniker] = simconnect.ValueToInt32(value)
-
-			case simconnect.DataTypeInt64:
-				vars[v.Moniker] = simconnect.ValueToInt64(value)
-
-			case simconnect.DataTypeFloat32:
-				vars[v.Moniker] = simconnect.ValueToFloat32(value)
-
-			case simconnect.DataTypeFloat64:
-				vars[v.Moniker] = simconnect.ValueToFloat64(value)
-
-			case simconnect.DataTypeString8,
-				simconnect.DataTypeString32,
-				simconnect.DataTypeString64,
-				simconnect.DataTypeString128,
-				simconnect.DataTypeString256,
-				simconnect.DataTypeString260,
-				simconnect.DataTypeStringV:
-				vars[v.Moniker] = simconnect.ValueToString(value)
-			}
+func (app *App) requestValues(request *Request) map[string]interface{} {
+	vars := make(map[string]interface{})
+	for defineID, v := range request.Vars {
+		value, dataType, ok := app.mate.SimVarValueAndDataType(defineID)
+		if !ok || value == nil {
+			continue
 		}
-		msg["data"] = vars
-		recipient := request.ClientID
-		if buf, err := json.Marshal(msg); err == nil {
-			app.socket.Send(recipient, buf)
+		switch dataType {
+		case simconnect.DataTypeInt32:
+			vars[v.Moniker] = simconnect.ValueToInt32(value)
+
+		case simconnect.DataTypeInt64:
+			vars[v.Moniker] = simconnect.ValueToInt64(value)
+
+		case simconnect.DataTypeFloat32:
+			vars[v.Moniker] = simconnect.ValueToFloat32(value)
+
+		case simconnect.DataTypeFloat64:
+			vars[v.Moniker] = simconnect.ValueToFloat64(value)
+
+		case simconnect.DataTypeString8,
+			simconnect.DataTypeString32,
+			simconnect.DataTypeString64,
+			simconnect.DataTypeString128,
+			simconnect.DataTypeString256,
+			simconnect.DataTypeString260,
+			simconnect.DataTypeStringV:
+			vars[v.Moniker] = simconnect.ValueToString(value)
 		}
 	}
+	return vars
 }
 
-func (app *App) Broadcast(broadcastInterval time.Duration, stop chan interface{}) {
+func (app *App) Broadcast(ctx context.Context, broadcastInterval time.Duration) {
 	broadcastTicker := time.NewTicker(broadcastInterval)
 	defer broadcastTicker.Stop()
 
@@ -573,7 +918,7 @@ func (app *App) Broadcast(broadcastInterval time.Duration, stop chan interface{}
 			if err := app.BroadcastStatusMessage(); err != nil {
 				log.Error(err)
 			}
-		case <-stop:
+		case <-ctx.Done():
 			log.Info("Stopped broadcasting")
 			return
 		}
@@ -587,10 +932,68 @@ func (app *App) BroadcastStatusMessage() error {
 	if err != nil {
 		return err
 	}
-	app.socket.Broadcast(buf)
+	app.broadcast("status", buf)
 	return nil
 }
 
+// Broadcasts which only carry the latest state. A client which falls behind
+// gets the latest one only. Traffic messages must not be skipped, since they
+// list the targets removed since the previous one.
+var stateBroadcastTypes = map[string]bool{
+	"status": true,
+}
+
+func missingField(name string) error {
+	return fmt.Errorf("missing or invalid %s", name)
+}
+
+// sendResult tells the sender of a message without another reply whether it
+// succeeded, or why it failed.
+func (app *App) sendResult(msg *Message, connID string, err error) {
+	data := map[string]interface{}{
+		"request": msg.Type,
+		"ok":      err == nil,
+	}
+	if err != nil {
+		log.Warnf("Failed to handle %s message from %s: %s", msg.Type, connID, err)
+		data["error"] = err.Error()
+	}
+	reply := map[string]interface{}{
+		"type": "result",
+		"meta": msg.Meta,
+		"data": data,
+	}
+	if buf, err := json.Marshal(reply); err == nil {
+		app.send(connID, "result", buf)
+	}
+}
+
+func (app *App) send(connID, msgType string, buf []byte) bool {
+	return app.sendMessage(connID, msgType, &websockets.Message{Data: buf})
+}
+
+func (app *App) sendMessage(connID, msgType string, message *websockets.Message) bool {
+	if !app.socket.Send(connID, message) {
+		if app.keepForSession(connID, msgType, message.Data) {
+			return false
+		}
+		app.metrics.messagesDropped.Inc(dropReasonUnknownRecipient)
+		return false
+	}
+	app.metrics.messagesOut.Inc(msgType)
+	return true
+}
+
+func (app *App) broadcast(msgType string, buf []byte) {
+	message := &websockets.Message{Data: buf}
+	if stateBroadcastTypes[msgType] {
+		message.Key = msgType
+	}
+	app.socket.Broadcast(message)
+	app.keepForSessions(msgType, buf)
+	app.metrics.messagesOut.Add(float64(app.socket.ConnectionCount()), msgType)
+}
+
 func (app *App) Headers(contentType string) map[string]string {
 	headers := map[string]string{
 		"Access-Control-Allow-Origin": "*",
@@ -611,7 +1014,7 @@ func (app *App) staticContentHandler(headers map[string]string, urlPath, filePat
 		for key, value := range headers {
 			w.Header().Set(key, value)
 		}
-		http.ServeFile(w, r, filePath)
+		resources.ServeFile(w, r, app.resources.Assets(), filePath)
 	}
 }
 
@@ -634,34 +1037,6 @@ func (app *App) simvarsGenerator(w http.ResponseWriter) {
 	fmt.Fprintf(w, "%s\n", app.simVars())
 }
 
-func (app *App) DebugGenerator(w http.ResponseWriter) {
-	fmt.Fprintf(w, "%s\n\n", appTitle)
-	if len(app.flightSimVersion) > 0 {
-		fmt.Fprintf(w, "%s\n\n", app.flightSimVersion)
-	}
-	fmt.Fprintf(w, "SimConnect\n  initialized: %v\n  conncected: %v\n\n", simconnect.IsInitialized(), app.mate.IsConnected())
-	fmt.Fprintf(w, "Clients: %d\n", app.socket.ConnectionCount())
-	uuids := app.socket.ConnectionUUIDs()
-	for i, uuid := range uuids {
-		fmt.Fprintf(w, "  %02d: %s\n", i, uuid)
-	}
-	fmt.Fprintf(w, "\n")
-	fmt.Fprintf(w, "%s\n\n", app.simVars())
-	fmt.Fprintf(w, "%s\n", app.requests())
-}
-
-func (app *App) requests() string {
-	var dump string
-	dump += fmt.Sprintf("Requests: %d\n", app.requestManager.RequestCount())
-	for i, request := range app.requestManager.Requests {
-		dump += fmt.Sprintf("  %02d: Client: %s Vars: %d Meta: %s\n", i+1, request.ClientID, len(request.Vars), request.Meta)
-		for j, simVar := range request.Vars {
-			dump += fmt.Sprintf("    %02d: name: %s moniker: %s\n", j, simVar.Name, simVar.Moniker)
-		}
-	}
-	return dump
-}
-
 func (app *App) simVars() string {
 	indent := "  "
 	dump := app.mate.SimVarDump(indent)
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"msfs2020-gopilot/internal/config"
+	"msfs2020-gopilot/internal/websockets"
+
+	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect"
+)
+
+// newTestApp returns an app with the default configuration which is not
+// connected to a simulator. SimVars can be added and removed, but they never
+// get a value.
+func newTestApp(t *testing.T, configure func(cfg *config.Config)) *App {
+	t.Helper()
+	cfg, err := config.Load(config.LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.DataDir = t.TempDir()
+	cfg.Maneuvers.Dir = t.TempDir()
+	if configure != nil {
+		configure(cfg)
+	}
+	app := NewApp(cfg, nil)
+	app.mate = simconnect.NewSimMate()
+	app.socket = websockets.NewWebSocket(websockets.Options{
+		QueueSize:         int(cfg.WebSocket.QueueSize),
+		SlowClientTimeout: time.Duration(cfg.WebSocket.SlowClientTimeout) * time.Second,
+	})
+	return app
+}
+
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,263 @@
+package app
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect"
+	log "github.com/sirupsen/logrus"
+)
+
+type SimulatorInfo struct {
+	ApplicationName    string `json:"applicationName"`
+	ApplicationVersion string `json:"applicationVersion"`
+	ApplicationBuild   string `json:"applicationBuild"`
+	SimConnectVersion  string `json:"simConnectVersion"`
+	SimConnectBuild    string `json:"simConnectBuild"`
+}
+
+type DebugInfo struct {
+	Title       string            `json:"title"`
+	Timestamp   time.Time         `json:"timestamp"`
+	SimConnect  DebugSimConnect   `json:"simconnect"`
+	Simulator   *SimulatorInfo    `json:"simulator"`
+	Connections []DebugConnection `json:"connections"`
+	Requests    []DebugRequest    `json:"requests"`
+	SimVars     []DebugSimVar     `json:"simvars"`
+}
+
+type DebugSimConnect struct {
+	Initialized bool `json:"initialized"`
+	Connected   bool `json:"connected"`
+}
+
+type DebugConnection struct {
+	UUID           string         `json:"uuid"`
+	RemoteAddress  string         `json:"remoteAddress"`
+	ConnectedSince time.Time      `json:"connectedSince"`
+	Encoding       string         `json:"encoding"`
+	QueueDepth     int            `json:"queueDepth"`
+	Subscriptions  []DebugRequest `json:"subscriptions"`
+}
+
+type DebugRequest struct {
+	ID       string            `json:"id"`
+	ClientID string            `json:"clientId"`
+	Internal bool              `json:"internal"`
+	Meta     string            `json:"meta"`
+	Vars     []DebugRequestVar `json:"vars"`
+}
+
+type DebugRequestVar struct {
+	DefineID simconnect.DWord `json:"defineId"`
+	Name     string           `json:"name"`
+	Moniker  string           `json:"moniker"`
+}
+
+type DebugSimVar struct {
+	DefineID    simconnect.DWord `json:"defineId"`
+	RequestID   simconnect.DWord `json:"requestId"`
+	Name        string           `json:"name"`
+	Unit        string           `json:"unit"`
+	DataType    string           `json:"dataType"`
+	Value       interface{}      `json:"value"`
+	UpdateCount int64            `json:"updateCount"`
+	RefCount    int              `json:"refCount"`
+	Registered  bool             `json:"registered"`
+	Pending     bool             `json:"pending"`
+}
+
+func (app *App) debugInfo() *DebugInfo {
+	info := &DebugInfo{
+		Title:     appTitle,
+		Timestamp: time.Now(),
+		SimConnect: DebugSimConnect{
+			Initialized: simconnect.IsInitialized(),
+			Connected:   app.mate != nil && app.mate.IsConnected(),
+		},
+		Simulator:   app.simulator(),
+		Connections: make([]DebugConnection, 0),
+		Requests:    make([]DebugRequest, 0),
+		SimVars:     make([]DebugSimVar, 0),
+	}
+
+	subscriptions := make(map[string][]DebugRequest)
+	defineIDs := make(map[simconnect.DWord]bool)
+	for _, request := range app.requestManager.All() {
+		req := debugRequest(request)
+		info.Requests = append(info.Requests, req)
+		subscriptions[request.ClientID()] = append(subscriptions[request.ClientID()], req)
+		for defineID := range request.Vars {
+			defineIDs[defineID] = true
+		}
+	}
+
+	for _, connection := range app.socket.Connections() {
+		subs := subscriptions[connection.UUID()]
+		if subs == nil {
+			subs = make([]DebugRequest, 0)
+		}
+		info.Connections = append(info.Connections, DebugConnection{
+			UUID:           connection.UUID(),
+			RemoteAddress:  connection.RemoteAddr(),
+			ConnectedSince: connection.ConnectedSince(),
+			Encoding:       connection.Encoding(),
+			QueueDepth:     connection.QueueDepth(),
+			Subscriptions:  subs,
+		})
+	}
+	sort.Slice(info.Connections, func(i, j int) bool {
+		return info.Connections[i].ConnectedSince.Before(info.Connections[j].ConnectedSince)
+	})
+
+	if app.mate != nil {
+		for defineID := range defineIDs {
+			simVar, ok := app.mate.SimVar(defineID)
+			if !ok {
+				continue
+			}
+			info.SimVars = append(info.SimVars, DebugSimVar{
+				DefineID:    simVar.DefineID,
+				RequestID:   simVar.RequestID,
+				Name:        simVar.Name,
+				Unit:        simVar.Unit,
+				DataType:    simconnect.DataTypeToString(simVar.DataType),
+				Value:       simVar.Value,
+				UpdateCount: simVar.UpdateCount,
+				RefCount:    app.requestManager.RefCount(simVar.Name),
+				Registered:  simVar.Registered,
+				Pending:     simVar.Pending,
+			})
+		}
+		sort.Slice(info.SimVars, func(i, j int) bool {
+			return info.SimVars[i].DefineID < info.SimVars[j].DefineID
+		})
+	}
+	return info
+}
+
+func debugRequest(request *Request) DebugRequest {
+	req := DebugRequest{
+		ID:       request.ID,
+		ClientID: request.ClientID(),
+		Internal: isInternalClient(request.ClientID()),
+		Meta:     request.Meta,
+		Vars:     make([]DebugRequestVar, 0, len(request.Vars)),
+	}
+	for defineID, v := range request.Vars {
+		req.Vars = append(req.Vars, DebugRequestVar{DefineID: defineID, Name: v.Name, Moniker: v.Moniker})
+	}
+	sort.Slice(req.Vars, func(i, j int) bool {
+		return req.Vars[i].DefineID < req.Vars[j].DefineID
+	})
+	return req
+}
+
+func (app *App) debugHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, headers, http.StatusOK, app.debugInfo())
+	}
+}
+
+func (app *App) disconnectClientHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		connID := mux.Vars(r)["uuid"]
+		if !app.socket.Disconnect(connID) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Info("Force-disconnected client: ", connID)
+		writeJSON(w, headers, http.StatusOK, map[string]interface{}{"disconnected": connID})
+	}
+}
+
+func (app *App) dropRequestHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		requestID := mux.Vars(r)["id"]
+		removed := app.dropRequests(func(request *Request) bool {
+			return request.ID == requestID
+		})
+		if len(removed) == 0 {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Info("Dropped request: ", requestID)
+		writeJSON(w, headers, http.StatusOK, map[string]interface{}{"dropped": requestID})
+	}
+}
+
+// adminOnly guards the debug endpoints which change state. They are accepted
+// from the local machine, or from other hosts with the configured admin token
+// as "Authorization: Bearer <token>". Requests from web pages of other origins
+// are rejected, so a page opened in a local browser cannot use them either.
+func (app *App) adminOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !sameOrigin(r) || !(fromLoopback(r) || hasToken(r, app.cfg.AdminToken)) {
+			log.Warn("Rejected debug request from ", r.RemoteAddr, ": ", r.Method, " ", r.URL.Path)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func fromLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func hasToken(r *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(token)) == 1
+}
+
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+func writeJSON(w http.ResponseWriter, headers map[string]string, status int, data interface{}) {
+	buf, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for key, value := range headers {
+		w.Header().Set(key, value)
+	}
+	w.WriteHeader(status)
+	w.Write(buf)
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"msfs2020-gopilot/internal/config"
+)
+
+func TestAdminOnly(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		token      string
+		status     int
+	}{
+		{name: "loopback", remoteAddr: "127.0.0.1:50000", status: http.StatusOK},
+		{name: "loopback IPv6", remoteAddr: "[::1]:50000", status: http.StatusOK},
+		{name: "same origin", remoteAddr: "127.0.0.1:50000", headers: map[string]string{"Origin": "http://localhost:8888"}, status: http.StatusOK},
+		{name: "other origin", remoteAddr: "127.0.0.1:50000", headers: map[string]string{"Origin": "http://example.com"}, status: http.StatusForbidden},
+		{name: "remote", remoteAddr: "192.168.1.20:50000", status: http.StatusForbidden},
+		{name: "remote without token", remoteAddr: "192.168.1.20:50000", token: "s3cret", status: http.StatusForbidden},
+		{name: "remote with token", remoteAddr: "192.168.1.20:50000", headers: map[string]string{"Authorization": "Bearer s3cret"}, token: "s3cret", status: http.StatusOK},
+		{name: "remote with wrong token", remoteAddr: "192.168.1.20:50000", headers: map[string]string{"Authorization": "Bearer secret"}, token: "s3cret", status: http.StatusForbidden},
+		{name: "remote with empty token", remoteAddr: "192.168.1.20:50000", headers: map[string]string{"Authorization": "Bearer "}, status: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		app := &App{cfg: &config.Config{AdminToken: test.token}}
+		handler := app.adminOnly(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		r := httptest.NewRequest(http.MethodPost, "http://localhost:8888/api/debug/requests/1", nil)
+		r.RemoteAddr = test.remoteAddr
+		for key, value := range test.headers {
+			r.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s: status %d, want %d", test.name, w.Code, test.status)
+		}
+	}
+}
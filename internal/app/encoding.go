@@ -0,0 +1,54 @@
+package app
+
+import (
+	"encoding/json"
+
+	"msfs2020-gopilot/internal/websockets"
+)
+
+// sendMonikers sends the moniker table of a request to its client if the
+// client uses a binary encoding. The simvars messages for the request carry
+// the values keyed by the index in the table.
+func (app *App) sendMonikers(request *Request) {
+	clientID := request.ClientID()
+	encoding, ok := app.socket.Encoding(clientID)
+	if !ok || !websockets.IsBinary(encoding) {
+		return
+	}
+	msg := map[string]interface{}{
+		"type": "monikers",
+		"meta": request.Meta,
+		"data": request.Monikers,
+	}
+	if buf, err := json.Marshal(msg); err == nil {
+		app.send(clientID, "monikers", buf)
+	} else {
+		app.metrics.messagesDropped.Inc(dropReasonEncodingFailed)
+	}
+}
+
+func (app *App) sendBinarySimVars(request *Request, encoding string, vars map[string]interface{}) {
+	values := make(map[int]interface{}, len(vars))
+	for _, v := range request.Vars {
+		if value, ok := vars[v.Moniker]; ok {
+			values[v.Index] = value
+		}
+	}
+	msg := map[string]interface{}{
+		"type": "simvars",
+		"meta": request.Meta,
+		"data": values,
+	}
+	buf, err := websockets.Marshal(encoding, msg)
+	if err != nil {
+		app.metrics.messagesDropped.Inc(dropReasonEncodingFailed)
+		return
+	}
+	app.sendMessage(request.ClientID(), "simvars", &websockets.Message{Data: buf, Encoded: true, Key: simVarsKey(request)})
+}
+
+// simVarsKey lets the simvars messages of a request replace each other in the
+// queue of a client which can't keep up.
+func simVarsKey(request *Request) string {
+	return "simvars:" + request.ID
+}
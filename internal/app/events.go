@@ -0,0 +1,60 @@
+package app
+
+import (
+	"sync"
+
+	"msfs2020-gopilot/internal/util"
+
+	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect"
+	log "github.com/sirupsen/logrus"
+)
+
+// clientEventMap keeps the client event IDs of the simulator events which were
+// transmitted so far. A name is only mapped once per connection.
+type clientEventMap struct {
+	ids   map[string]simconnect.DWord
+	mutex sync.Mutex
+}
+
+func newClientEventMap() *clientEventMap {
+	return &clientEventMap{ids: make(map[string]simconnect.DWord)}
+}
+
+// handleEventMessage transmits a simulator event (e.g. PARKING_BRAKES) with an
+// optional data value to the user aircraft.
+func (app *App) handleEventMessage(msg *Message) error {
+	name, ok := util.StringFromJson("name", msg.Data)
+	if !ok || name == "" {
+		return missingField("name")
+	}
+	if !app.mate.IsConnected() {
+		return errNotConnected
+	}
+	data, _ := util.FloatFromJson("data", msg.Data)
+	if err := app.transmitEvent(name, int32(data)); err != nil {
+		return err
+	}
+	log.Infof("Transmitted event %s (data: %d)", name, int32(data))
+	return nil
+}
+
+func (app *App) transmitEvent(name string, data int32) error {
+	app.clientEvents.mutex.Lock()
+	eventID, ok := app.clientEvents.ids[name]
+	if !ok {
+		eventID = simconnect.NewEventID()
+		if err := app.mate.MapClientEventToSimEvent(eventID, name); err != nil {
+			app.clientEvents.mutex.Unlock()
+			return err
+		}
+		app.clientEvents.ids[name] = eventID
+	}
+	app.clientEvents.mutex.Unlock()
+
+	return app.mate.TransmitClientEvent(
+		uint32(simconnect.ObjectIDUser),
+		uint32(eventID),
+		simconnect.DWord(uint32(data)),
+		simconnect.GroupPriorityHighest,
+		simconnect.EventFlagGroupIDIsPriority)
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"msfs2020-gopilot/internal/telemetry"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	internalClientPrefix = "internal:"
+)
+
+// A feed connects a server-side telemetry.Consumer to the request pipeline.
+// Its request is handled like any client's request, but OnDataReady hands the
+// values to the consumer instead of sending them over the WebSocket.
+type feed struct {
+	name     string
+	consumer telemetry.Consumer
+	request  *Request
+}
+
+type feedMap struct {
+	feeds map[string]*feed // by request ID
+	mutex sync.RWMutex
+}
+
+func newFeedMap() *feedMap {
+	return &feedMap{feeds: make(map[string]*feed)}
+}
+
+func (m *feedMap) add(f *feed) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.feeds[f.request.ID] = f
+}
+
+func (m *feedMap) get(requestID string) (*feed, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	f, ok := m.feeds[requestID]
+	return f, ok
+}
+
+func (m *feedMap) remove(requestID string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.feeds, requestID)
+}
+
+func (m *feedMap) all() []*feed {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	feeds := make([]*feed, 0, len(m.feeds))
+	for _, f := range m.feeds {
+		feeds = append(feeds, f)
+	}
+	return feeds
+}
+
+func (f *feed) consume(t time.Time, values map[string]interface{}) {
+	if len(values) == 0 {
+		return
+	}
+	sample := telemetry.NewSample(t)
+	for moniker, value := range values {
+		sample.Set(moniker, value)
+	}
+	f.consumer.Consume(sample)
+}
+
+// AddConsumer registers a server-side consumer of SimVar samples. It has to be
+// called before Run.
+func (app *App) AddConsumer(name string, consumer telemetry.Consumer) {
+	request := NewRequest(internalClientPrefix+name, name)
+	app.feeds.add(&feed{
+		name:     name,
+		consumer: consumer,
+		request:  request,
+	})
+}
+
+func (app *App) registerFeeds() {
+	for _, f := range app.feeds.all() {
+		app.startFeed(f)
+		log.Infof("Added internal request for %s with %d SimVars", f.name, len(f.request.Vars))
+	}
+}
+
+// addClientFeed feeds the samples of a new request of a client to a consumer.
+// The feed is removed along with the client's other requests.
+func (app *App) addClientFeed(request *Request, name string, consumer telemetry.Consumer) *feed {
+	f := &feed{
+		name:     name,
+		consumer: consumer,
+		request:  request,
+	}
+	app.feeds.add(f)
+	app.startFeed(f)
+	return f
+}
+
+func (app *App) startFeed(f *feed) {
+	app.addRequest(f.request, f.consumer.Vars())
+}
+
+func (app *App) removeFeeds(removed []*Request) {
+	for _, request := range removed {
+		app.feeds.remove(request.ID)
+	}
+}
+
+func isInternalClient(clientID string) bool {
+	return strings.HasPrefix(clientID, internalClientPrefix)
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+type countingConsumer struct {
+	vars    []telemetry.Var
+	samples int32
+}
+
+func (c *countingConsumer) Vars() []telemetry.Var {
+	return c.vars
+}
+
+func (c *countingConsumer) Consume(sample *telemetry.Sample) {
+	atomic.AddInt32(&c.samples, 1)
+}
+
+var testVars = []telemetry.Var{
+	{Name: "PLANE ALTITUDE", Unit: "feet", Type: "float64", Moniker: "altitude"},
+	{Name: "AIRSPEED INDICATED", Unit: "knot", Type: "float64", Moniker: "airspeed"},
+}
+
+func TestFeedMap(t *testing.T) {
+	m := newFeedMap()
+	f := &feed{name: "test", request: NewRequest("client", "")}
+	m.add(f)
+	if got, ok := m.get(f.request.ID); !ok || got != f {
+		t.Error("feed not found")
+	}
+	m.remove(f.request.ID)
+	if _, ok := m.get(f.request.ID); ok {
+		t.Error("feed was not removed")
+	}
+	if n := len(m.all()); n != 0 {
+		t.Errorf("%d feeds left", n)
+	}
+}
+
+// Clients and feeds come and go while the event handler ticks. Run with -race.
+func TestRegistrationsWhileTicking(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	stop := make(chan struct{})
+	var ticker sync.WaitGroup
+	ticker.Add(1)
+	go func() {
+		defer ticker.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				app.OnDataReady()
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				connID := fmt.Sprintf("client-%d-%d", i, j)
+				app.addRequest(NewRequest(connID, "meta"), testVars)
+				app.addClientFeed(NewRequest(connID, "feed"), "test", &countingConsumer{vars: testVars})
+				app.requestManager.Reassign(connID, connID+"-resumed")
+				app.removeRequests(connID + "-resumed")
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(stop)
+	ticker.Wait()
+
+	if n := app.requestManager.RequestCount(); n != 0 {
+		t.Errorf("%d requests left", n)
+	}
+	if n := len(app.feeds.all()); n != 0 {
+		t.Errorf("%d feeds left", n)
+	}
+	if n := app.requestManager.RefCount("PLANE ALTITUDE"); n != 0 {
+		t.Errorf("PLANE ALTITUDE is still used %d times", n)
+	}
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"msfs2020-gopilot/internal/gdl90"
+	"msfs2020-gopilot/internal/ownship"
+	"msfs2020-gopilot/internal/traffic"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	gdl90DeviceName     = "GoPilot"
+	gdl90DeviceLongName = "MSFS2020 GoPilot"
+	// Address of the ownship. Traffic uses self-assigned addresses derived
+	// from the SimConnect object ID.
+	gdl90OwnshipAddress = 0xF0F0F0
+)
+
+type gdl90Service struct {
+	conn    net.Conn
+	tracker *ownship.Tracker
+}
+
+func (app *App) initGDL90() {
+	conn, err := net.Dial("udp", app.cfg.GDL90.Address)
+	if err != nil {
+		log.Warn("GDL90 output will not be available: ", err)
+		return
+	}
+	app.gdl90 = &gdl90Service{
+		conn:    conn,
+		tracker: app.ownshipTracker(),
+	}
+	log.Infof("Sending GDL90 to %s", app.cfg.GDL90.Address)
+}
+
+// runGDL90 sends ownship reports at the configured rate, and the heartbeat,
+// the device ID and the traffic reports once per second.
+func (app *App) runGDL90(ctx context.Context) {
+	defer app.gdl90.conn.Close()
+	rate := int(app.cfg.GDL90.OwnshipRate)
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+
+	tick := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			state, valid := app.gdl90.tracker.State()
+			buf := make([]byte, 0, 512)
+			if tick%rate == 0 {
+				buf = append(buf, gdl90.Frame(gdl90.Heartbeat(now, valid))...)
+				buf = append(buf, gdl90.Frame(gdl90.ForeFlightID(gdl90DeviceName, gdl90DeviceLongName))...)
+				if valid && app.traffic != nil {
+					for _, target := range app.traffic.table.Targets() {
+						buf = append(buf, gdl90.Frame(gdl90.TrafficReport(trafficReport(&target)))...)
+					}
+				}
+			}
+			if valid {
+				buf = append(buf, gdl90.Frame(gdl90.OwnshipReport(ownshipReport(&state)))...)
+				buf = append(buf, gdl90.Frame(gdl90.OwnshipGeoAltitude(state.Altitude))...)
+			}
+			tick++
+			if _, err := app.gdl90.conn.Write(buf); err != nil {
+				log.Debug("Unable to send GDL90: ", err)
+			}
+		}
+	}
+}
+
+func ownshipReport(state *ownship.State) *gdl90.Report {
+	return &gdl90.Report{
+		Address:       gdl90OwnshipAddress,
+		AddressType:   gdl90.AddressTypeICAO,
+		Latitude:      state.Latitude,
+		Longitude:     state.Longitude,
+		Altitude:      state.PressureAltitude,
+		Airborne:      !state.OnGround,
+		GroundSpeed:   state.GroundSpeed,
+		VerticalSpeed: state.VerticalSpeed,
+		Track:         state.Track,
+		Emitter:       gdl90.EmitterLight,
+		Callsign:      state.ATCID,
+	}
+}
+
+// trafficReport converts a target. The pressure altitude of traffic is not
+// known, so the MSL altitude is used.
+func trafficReport(target *traffic.Target) *gdl90.Report {
+	emitter := byte(gdl90.EmitterNone)
+	if target.Category == traffic.CategoryHelicopter {
+		emitter = gdl90.EmitterRotorcraft
+	}
+	return &gdl90.Report{
+		Address:       target.ID & 0xFFFFFF,
+		AddressType:   gdl90.AddressTypeSelfAssigned,
+		Latitude:      target.Latitude,
+		Longitude:     target.Longitude,
+		Altitude:      target.Altitude,
+		Airborne:      !target.OnGround,
+		GroundSpeed:   target.GroundSpeed,
+		VerticalSpeed: target.VerticalSpeed,
+		Track:         target.Heading,
+		Emitter:       emitter,
+		Callsign:      target.ATCID,
+	}
+}
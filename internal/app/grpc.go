@@ -0,0 +1,247 @@
+package app
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"msfs2020-gopilot/api/gopilotpb"
+	"msfs2020-gopilot/internal/telemetry"
+
+	"github.com/google/uuid"
+	alphafoxtrot "github.com/grumpypixel/go-airport-finder"
+	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	grpcClientPrefix     = "grpc:"
+	grpcSampleQueueSize  = 8
+	grpcGracefulShutdown = 2 // seconds
+)
+
+type grpcService struct {
+	gopilotpb.UnimplementedGoPilotServer
+	app           *App
+	server        *grpc.Server
+	listener      net.Listener
+	subscriptions int32
+}
+
+func (app *App) initGRPC() {
+	listener, err := net.Listen("tcp", app.cfg.GRPC.Address)
+	if err != nil {
+		log.Error("gRPC API will not be available: ", err)
+		return
+	}
+	service := &grpcService{
+		app:      app,
+		server:   grpc.NewServer(),
+		listener: listener,
+	}
+	gopilotpb.RegisterGoPilotServer(service.server, service)
+	app.grpc = service
+}
+
+func (app *App) runGRPC(ctx context.Context) {
+	go func() {
+		log.Info("Serving gRPC on ", app.grpc.listener.Addr())
+		if err := app.grpc.server.Serve(app.grpc.listener); err != nil {
+			log.Error("gRPC server stopped: ", err)
+		}
+	}()
+	<-ctx.Done()
+
+	// Subscriptions only end when the client cancels them, so do not wait
+	// for them forever.
+	done := make(chan interface{})
+	go func() {
+		app.grpc.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(grpcGracefulShutdown * time.Second):
+		app.grpc.server.Stop()
+	}
+}
+
+// grpcSubscription is the consumer of a Subscribe call. It keeps at most
+// grpcSampleQueueSize samples. If the client is too slow, the oldest samples
+// are dropped so that it always gets the latest values.
+type grpcSubscription struct {
+	vars    []telemetry.Var
+	samples chan *telemetry.Sample
+}
+
+func (sub *grpcSubscription) Vars() []telemetry.Var {
+	return sub.vars
+}
+
+func (sub *grpcSubscription) Consume(sample *telemetry.Sample) {
+	for {
+		select {
+		case sub.samples <- sample:
+			return
+		default:
+		}
+		select {
+		case <-sub.samples:
+		default:
+		}
+	}
+}
+
+func (srv *grpcService) Subscribe(list *gopilotpb.SimVarList, stream gopilotpb.GoPilot_SubscribeServer) error {
+	if len(list.Simvars) == 0 {
+		return status.Error(codes.InvalidArgument, "no SimVars")
+	}
+	sub := &grpcSubscription{
+		vars:    make([]telemetry.Var, 0, len(list.Simvars)),
+		samples: make(chan *telemetry.Sample, grpcSampleQueueSize),
+	}
+	for _, v := range list.Simvars {
+		if v.Name == "" {
+			return status.Error(codes.InvalidArgument, "SimVar without name")
+		}
+		dataType := v.Type
+		if dataType == "" {
+			dataType = "float64"
+		}
+		if simconnect.StringToDataType(dataType) == simconnect.DataTypeInvalid {
+			return status.Errorf(codes.InvalidArgument, "%s: invalid type %q", v.Name, v.Type)
+		}
+		sub.vars = append(sub.vars, telemetry.Var{Name: v.Name, Unit: v.Unit, Type: dataType, Moniker: v.Moniker})
+	}
+
+	connID := grpcClientPrefix + uuid.New().String()
+	srv.app.addClientFeed(NewRequest(connID, ""), "grpc", sub)
+	defer srv.app.removeRequests(connID)
+	atomic.AddInt32(&srv.subscriptions, 1)
+	defer atomic.AddInt32(&srv.subscriptions, -1)
+	log.Infof("gRPC client %s subscribed to %d SimVars", connID, len(sub.vars))
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			log.Infof("gRPC client %s unsubscribed", connID)
+			return nil
+
+		case sample := <-sub.samples:
+			if err := stream.Send(grpcSample(sample)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func grpcSample(sample *telemetry.Sample) *gopilotpb.Sample {
+	msg := &gopilotpb.Sample{
+		Time:   timestamppb.New(sample.Time),
+		Values: make(map[string]*gopilotpb.Value, len(sample.Values)),
+	}
+	for moniker, value := range sample.Values {
+		switch v := value.(type) {
+		case int32:
+			msg.Values[moniker] = &gopilotpb.Value{Kind: &gopilotpb.Value_Integer{Integer: int64(v)}}
+		case int64:
+			msg.Values[moniker] = &gopilotpb.Value{Kind: &gopilotpb.Value_Integer{Integer: v}}
+		case string:
+			msg.Values[moniker] = &gopilotpb.Value{Kind: &gopilotpb.Value_Text{Text: v}}
+		default:
+			if f, ok := sample.Float(moniker); ok {
+				msg.Values[moniker] = &gopilotpb.Value{Kind: &gopilotpb.Value_Number{Number: f}}
+			}
+		}
+	}
+	return msg
+}
+
+func (srv *grpcService) SetData(ctx context.Context, req *gopilotpb.SetDataRequest) (*gopilotpb.SetDataResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "no name")
+	}
+	if err := srv.app.setData(req.Name, req.Unit, req.Value); err != nil {
+		return nil, grpcError(err)
+	}
+	return &gopilotpb.SetDataResponse{}, nil
+}
+
+func (srv *grpcService) Teleport(ctx context.Context, req *gopilotpb.TeleportRequest) (*gopilotpb.TeleportResponse, error) {
+	if req.Latitude < -90 || req.Latitude > 90 || req.Longitude < -180 || req.Longitude > 180 {
+		return nil, status.Error(codes.InvalidArgument, "invalid position")
+	}
+	if err := srv.app.teleport(req.Latitude, req.Longitude, req.Altitude, req.Heading, req.Airspeed); err != nil {
+		return nil, grpcError(err)
+	}
+	return &gopilotpb.TeleportResponse{}, nil
+}
+
+func (srv *grpcService) FindAirports(ctx context.Context, req *gopilotpb.FindAirportsRequest) (*gopilotpb.FindAirportsResponse, error) {
+	radius := req.Radius
+	if radius <= 0 {
+		radius = defaultAirportSearchRadius
+	}
+	maxAirports := int(req.MaxAirports)
+	if maxAirports <= 0 {
+		maxAirports = defaultMaxAirportCount
+	}
+	filter := uint64(alphafoxtrot.AirportTypeAll)
+	if len(req.Types) > 0 {
+		filter = 0
+		for _, typ := range req.Types {
+			f := alphafoxtrot.AirportTypeFromString(typ)
+			if f == alphafoxtrot.AirportTypeUnknown {
+				return nil, status.Errorf(codes.InvalidArgument, "unknown airport type %q", typ)
+			}
+			filter |= f
+		}
+	}
+
+	airports, err := srv.app.findAirports(req.Latitude, req.Longitude, radius, maxAirports, filter)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	resp := &gopilotpb.FindAirportsResponse{Airports: make([]*gopilotpb.Airport, 0, len(airports))}
+	for _, airport := range airports {
+		resp.Airports = append(resp.Airports, &gopilotpb.Airport{
+			Icao:      airport.ICAOCode,
+			Type:      airport.Type,
+			Name:      airport.Name,
+			Latitude:  airport.LatitudeDeg,
+			Longitude: airport.LongitudeDeg,
+			Elevation: airport.ElevationFt,
+		})
+	}
+	return resp, nil
+}
+
+func (srv *grpcService) Status(ctx context.Context, req *gopilotpb.StatusRequest) (*gopilotpb.StatusResponse, error) {
+	resp := &gopilotpb.StatusResponse{
+		Simconnect:        srv.app.mate.IsConnected(),
+		WebsocketClients:  int32(srv.app.socket.ConnectionCount()),
+		GrpcSubscriptions: atomic.LoadInt32(&srv.subscriptions),
+	}
+	if info := srv.app.simulator(); info != nil {
+		resp.Simulator = &gopilotpb.SimulatorInfo{
+			ApplicationName:    info.ApplicationName,
+			ApplicationVersion: info.ApplicationVersion,
+			ApplicationBuild:   info.ApplicationBuild,
+			SimconnectVersion:  info.SimConnectVersion,
+			SimconnectBuild:    info.SimConnectBuild,
+		}
+	}
+	return resp, nil
+}
+
+func grpcError(err error) error {
+	if err == errNotConnected {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
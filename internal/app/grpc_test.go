@@ -0,0 +1,26 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"msfs2020-gopilot/api/gopilotpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFindAirportsWithoutDatabase(t *testing.T) {
+	app := newTestApp(t, nil)
+	app.airportFinder = nil
+	srv := &grpcService{app: app}
+
+	_, err := srv.FindAirports(context.Background(), &gopilotpb.FindAirportsRequest{
+		Latitude:    47.45,
+		Longitude:   -122.31,
+		MaxAirports: 1 << 30,
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("FindAirports = %v, want Unavailable", err)
+	}
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"net/http"
+
+	"msfs2020-gopilot/internal/webserver"
+)
+
+// Health of the process for supervisors and load balancers. /healthz answers
+// as long as the process serves requests, /readyz only while GoPilot is
+// connected to the simulator and the airport database is loaded.
+func (app *App) healthRoutes(headers map[string]string) []webserver.Route {
+	return []webserver.Route{
+		{Pattern: "/healthz", Handler: app.healthHandler(headers)},
+		{Pattern: "/readyz", Handler: app.readyHandler(headers)},
+	}
+}
+
+func (app *App) healthHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, headers, http.StatusOK, map[string]interface{}{
+			"status": "ok",
+		})
+	}
+}
+
+func (app *App) readyHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		checks := map[string]bool{
+			"simconnect": app.mate != nil && app.mate.IsConnected(),
+			"airports":   app.airportFinder != nil,
+		}
+		status, code := "ready", http.StatusOK
+		for _, ok := range checks {
+			if !ok {
+				status, code = "not ready", http.StatusServiceUnavailable
+			}
+		}
+		writeJSON(w, headers, code, map[string]interface{}{
+			"status": status,
+			"checks": checks,
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"msfs2020-gopilot/internal/landing"
+	"msfs2020-gopilot/internal/webserver"
+
+	"github.com/gorilla/mux"
+	alphafoxtrot "github.com/grumpypixel/go-airport-finder"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	landingsDataDir    = "landings"
+	maxRunwayAirports  = 5
+	defaultLandingsMax = 20
+)
+
+type landingService struct {
+	analyzer *landing.Analyzer
+	history  *landing.History
+}
+
+func (app *App) initLandings() {
+	dir, err := app.cfg.DataPath(landingsDataDir)
+	if err != nil {
+		log.Warn("Landing analyzer will not be available: ", err)
+		return
+	}
+	history, err := landing.NewHistory(dir, app.cfg.Landings.HistorySize)
+	if err != nil {
+		log.Warn("Landing analyzer will not be available: ", err)
+		return
+	}
+	analyzer := landing.NewAnalyzer(app.findRunways, app.onLanding)
+	app.landings = &landingService{analyzer: analyzer, history: history}
+	app.AddConsumer("landings", analyzer)
+	log.Info("Writing landing reports to ", history.Path())
+}
+
+func (app *App) findRunways(latitude, longitude, radius float64) []landing.RunwayEnd {
+	if app.airportFinder == nil {
+		return nil
+	}
+	airports := app.airportFinder.FindNearestAirports(latitude, longitude, radius, maxRunwayAirports, alphafoxtrot.AirportTypeRunways)
+	ends := make([]landing.RunwayEnd, 0)
+	for _, airport := range airports {
+		for _, rwy := range airport.Runways {
+			if rwy.Closed {
+				continue
+			}
+			ends = append(ends,
+				landing.RunwayEnd{
+					Airport:            airport.ICAOCode,
+					Ident:              rwy.LowEndIdent,
+					Latitude:           rwy.LowEndLatitudeDeg,
+					Longitude:          rwy.LowEndLongitudeDeg,
+					Heading:            rwy.LowEndHeadingDegT,
+					DisplacedThreshold: float64(rwy.LowEndDisplacedThresholdFt),
+					Length:             float64(rwy.LengthFt),
+					Width:              float64(rwy.WidthFt),
+				},
+				landing.RunwayEnd{
+					Airport:            airport.ICAOCode,
+					Ident:              rwy.HighEndIdent,
+					Latitude:           rwy.HighEndLatitudeDeg,
+					Longitude:          rwy.HighEndLongitudeDeg,
+					Heading:            rwy.HighEndHeadingDegT,
+					DisplacedThreshold: float64(rwy.HighEndDisplacedThresholdFt),
+					Length:             float64(rwy.LengthFt),
+					Width:              float64(rwy.WidthFt),
+				})
+		}
+	}
+	return ends
+}
+
+func (app *App) onLanding(report *landing.Report) {
+	runway := "unknown runway"
+	if report.Runway != nil {
+		runway = report.Runway.Airport + " " + report.Runway.Ident
+	}
+	log.Infof("Touchdown on %s: %.0f ft/min, %.2f G (%s)", runway, report.VerticalSpeed, report.GForce, report.Rating)
+	go func() {
+		if err := app.landings.history.Add(report); err != nil {
+			log.Error("Could not save landing report: ", err)
+		}
+	}()
+
+	msg := map[string]interface{}{
+		"type": "landing",
+		"data": report,
+	}
+	if buf, err := json.Marshal(msg); err == nil {
+		app.broadcast("landing", buf)
+	} else {
+		app.metrics.messagesDropped.Inc(dropReasonEncodingFailed)
+	}
+}
+
+func (app *App) landingRoutes(headers map[string]string) []webserver.Route {
+	if app.landings == nil {
+		return nil
+	}
+	return []webserver.Route{
+		{Pattern: "/api/landings", Handler: app.landingsHandler(headers)},
+		{Pattern: "/api/landings/{id}", Handler: app.landingHandler(headers)},
+	}
+}
+
+func (app *App) landingsHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		limit := defaultLandingsMax
+		if str := r.URL.Query().Get("limit"); str != "" {
+			var err error
+			if limit, err = strconv.Atoi(str); err != nil {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+		}
+		writeJSON(w, headers, http.StatusOK, app.landings.history.Reports(limit))
+	}
+}
+
+func (app *App) landingHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		report, ok := app.landings.history.Get(mux.Vars(r)["id"])
+		if !ok {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, headers, http.StatusOK, report)
+	}
+}
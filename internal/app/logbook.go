@@ -0,0 +1,171 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"msfs2020-gopilot/internal/flightlog"
+	"msfs2020-gopilot/internal/webhooks"
+	"msfs2020-gopilot/internal/webserver"
+
+	alphafoxtrot "github.com/grumpypixel/go-airport-finder"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	logbookDataDir   = "logbook"
+	contentTypeCSV   = "text/csv; charset=utf-8"
+	logbookDateParam = "2006-01-02"
+)
+
+type logbookService struct {
+	tracker *flightlog.Tracker
+	logbook *flightlog.Logbook
+}
+
+func (app *App) initLogbook() {
+	dir, err := app.cfg.DataPath(logbookDataDir)
+	if err != nil {
+		log.Warn("Logbook will not be available: ", err)
+		return
+	}
+	logbook, err := flightlog.NewLogbook(dir)
+	if err != nil {
+		log.Warn("Logbook will not be available: ", err)
+		return
+	}
+	app.logbook = &logbookService{tracker: app.flightTracker(), logbook: logbook}
+	log.Info("Writing logbook to ", logbook.Path())
+}
+
+// flightTracker returns the phase detection shared by the logbook, the
+// webhooks and the presence status, which is created by the first of them.
+func (app *App) flightTracker() *flightlog.Tracker {
+	if app.flights == nil {
+		app.flights = flightlog.NewTracker(app.findNearestAirport, app.onFlightEvent)
+		app.AddConsumer("flightlog", app.flights)
+	}
+	return app.flights
+}
+
+func (app *App) findNearestAirport(latitude, longitude, radius float64) (*flightlog.Airport, bool) {
+	if app.airportFinder == nil {
+		return nil, false
+	}
+	airport := app.airportFinder.FindNearestAirport(latitude, longitude, radius, alphafoxtrot.AirportTypeActive)
+	if airport == nil {
+		return nil, false
+	}
+	return &flightlog.Airport{ICAO: airport.ICAOCode, Name: airport.Name}, true
+}
+
+func (app *App) onFlightEvent(event *flightlog.Event) {
+	switch event.Type {
+	case flightlog.EventPhase:
+		log.Info("Flight phase: ", event.Phase)
+	case flightlog.EventTakeoff:
+		log.Infof("%s at %s", event.Type, airportName(event.Flight, event.Type))
+		app.notify(webhooks.EventTakeoff, event)
+	case flightlog.EventLanding:
+		log.Infof("%s at %s", event.Type, airportName(event.Flight, event.Type))
+		app.notify(webhooks.EventLanding, event)
+	case flightlog.EventFlight:
+		if app.logbook == nil {
+			return
+		}
+		entry := event.Entry
+		log.Infof("Logging flight %s (%.1f min block time)", entry.Route, entry.BlockTime)
+		go func() {
+			if err := app.logbook.logbook.Add(entry); err != nil {
+				log.Error("Could not write logbook entry: ", err)
+			}
+		}()
+	}
+}
+
+func airportName(flight *flightlog.Flight, eventType string) string {
+	airport := flight.Departure
+	if eventType == flightlog.EventLanding {
+		airport = flight.Arrival
+	}
+	if airport == nil {
+		return "unknown airport"
+	}
+	return airport.ICAO
+}
+
+func (app *App) logbookRoutes(headers map[string]string) []webserver.Route {
+	if app.logbook == nil {
+		return nil
+	}
+	return []webserver.Route{
+		{Pattern: "/api/logbook", Handler: app.logbookHandler(headers)},
+		{Pattern: "/api/logbook/current", Handler: app.logbookCurrentHandler(headers)},
+	}
+}
+
+// logbookHandler returns the logbook entries, newest first. Supported query
+// parameters: from and to (YYYY-MM-DD, takeoff date in UTC, to is exclusive),
+// airport (ICAO code), aircraft (part of the title or registration), limit and
+// format (json or csv).
+func (app *App) logbookHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		params := r.URL.Query()
+		query := flightlog.Query{
+			Airport:  params.Get("airport"),
+			Aircraft: params.Get("aircraft"),
+		}
+		var err error
+		if from := params.Get("from"); from != "" {
+			if query.From, err = time.Parse(logbookDateParam, from); err != nil {
+				http.Error(w, "invalid from date", http.StatusBadRequest)
+				return
+			}
+		}
+		if to := params.Get("to"); to != "" {
+			if query.To, err = time.Parse(logbookDateParam, to); err != nil {
+				http.Error(w, "invalid to date", http.StatusBadRequest)
+				return
+			}
+		}
+		if limit := params.Get("limit"); limit != "" {
+			if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+		}
+
+		entries, err := app.logbook.logbook.Entries(query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		switch params.Get("format") {
+		case "", "json":
+			writeJSON(w, headers, http.StatusOK, entries)
+		case "csv":
+			w.Header().Set("Content-Type", contentTypeCSV)
+			w.Header().Set("Content-Disposition", "attachment; filename=\"logbook.csv\"")
+			if err := flightlog.WriteCSV(w, entries); err != nil {
+				log.Error(err)
+			}
+		default:
+			http.Error(w, "unknown format", http.StatusBadRequest)
+		}
+	}
+}
+
+func (app *App) logbookCurrentHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, headers, http.StatusOK, app.logbook.tracker.Status())
+	}
+}
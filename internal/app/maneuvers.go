@@ -0,0 +1,273 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"msfs2020-gopilot/internal/maneuvers"
+	"msfs2020-gopilot/internal/maneuvers/approach"
+	"msfs2020-gopilot/internal/maneuvers/chandelles"
+	"msfs2020-gopilot/internal/maneuvers/lazyeights"
+	"msfs2020-gopilot/internal/maneuvers/slowflight"
+	"msfs2020-gopilot/internal/maneuvers/stalls"
+	"msfs2020-gopilot/internal/maneuvers/steepturns"
+	"msfs2020-gopilot/internal/maneuvers/turnsaroundpoint"
+	"msfs2020-gopilot/internal/util"
+	"msfs2020-gopilot/internal/webserver"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	maneuversDataDir = "maneuvers"
+	steepTurnsMeta   = "steepturns"
+
+	evaluateActionStart = "start"
+	evaluateActionStop  = "stop"
+	evaluateActionReset = "reset"
+
+	// Each evaluation keeps the samples of the current attempt, so a client
+	// cannot start an unlimited number of them.
+	maxClientEvaluations = 4
+)
+
+type maneuverService struct {
+	registry *maneuvers.Registry
+	store    *maneuvers.Store
+	// Server-side steep turns evaluation, see config.SteepTurnsConfig.
+	steepTurns *maneuvers.Session
+}
+
+func (app *App) initManeuvers() {
+	registry := maneuvers.NewRegistry()
+	registry.Register(steepturns.DefaultSpec(), steepturns.New)
+	registry.Register(slowflight.DefaultSpec(), slowflight.New)
+	registry.Register(stalls.DefaultSpec(), stalls.New)
+	registry.Register(chandelles.DefaultSpec(), chandelles.New)
+	registry.Register(lazyeights.DefaultSpec(), lazyeights.New)
+	registry.Register(turnsaroundpoint.DefaultSpec(), turnsaroundpoint.New)
+	registry.Register(approach.DefaultSpec(), approach.New)
+	if err := registry.LoadDir(app.cfg.Maneuvers.Dir); err != nil {
+		log.Error("Could not load maneuver tolerances, using defaults: ", err)
+	}
+	app.maneuvers = &maneuverService{registry: registry}
+
+	dir, err := app.cfg.DataPath(maneuversDataDir)
+	if err == nil {
+		app.maneuvers.store, err = maneuvers.NewStore(dir)
+	}
+	if err != nil {
+		log.Warn("Maneuver results will not be stored: ", err)
+	} else {
+		log.Info("Storing maneuver results in ", dir)
+	}
+
+	if app.cfg.SteepTurns.Enabled {
+		session, _ := registry.NewSession(steepturns.Maneuver, func(evaluation *maneuvers.Evaluation) {
+			app.onEvaluation(evaluation, nil, steepTurnsMeta)
+		})
+		app.maneuvers.steepTurns = session
+		app.AddConsumer(steepTurnsMeta, session)
+	}
+}
+
+// onEvaluation stores completed attempts and sends the evaluation to the client
+// of the request, or to all clients if there is no request.
+func (app *App) onEvaluation(evaluation *maneuvers.Evaluation, request *Request, meta string) {
+	if result := evaluation.Result; result != nil {
+		log.Infof("Maneuver %s completed: score %.1f, passed: %t", result.Maneuver, result.Score, result.Passed)
+		if app.maneuvers.store != nil {
+			go func() {
+				if err := app.maneuvers.store.Save(result); err != nil {
+					log.Error("Could not save maneuver result: ", err)
+				}
+			}()
+		}
+		// Clients fetch the trace from the API if they need it.
+		summary := *evaluation
+		summary.Result = result.Summary()
+		evaluation = &summary
+	}
+	msg := map[string]interface{}{
+		"type": "evaluation",
+		"meta": meta,
+		"data": evaluation,
+	}
+	buf, err := json.Marshal(msg)
+	if err != nil {
+		app.metrics.messagesDropped.Inc(dropReasonEncodingFailed)
+		return
+	}
+	if request == nil {
+		app.broadcast("evaluation", buf)
+	} else {
+		// The client may have resumed its session on another connection.
+		app.send(request.ClientID(), "evaluation", buf)
+	}
+}
+
+// handleEvaluateMessage starts, stops or resets the evaluation of a maneuver
+// for the sender. Evaluations are delivered with the meta of the message.
+func (app *App) handleEvaluateMessage(msg *Message, connID string) {
+	name, ok := util.StringFromJson("maneuver", msg.Data)
+	if !ok {
+		log.Warn("Received evaluate message without maneuver from ", connID)
+		return
+	}
+	action, ok := util.StringFromJson("action", msg.Data)
+	if !ok {
+		action = evaluateActionStart
+	}
+
+	switch action {
+	case evaluateActionStart:
+		if len(app.clientEvaluations(connID, msg.Meta, name)) > 0 {
+			log.Infof("Evaluation of %s for %s is already running", name, connID)
+			return
+		}
+		if app.evaluationCount(connID) >= maxClientEvaluations {
+			app.sendResult(msg, connID, fmt.Errorf("at most %d evaluations per client", maxClientEvaluations))
+			return
+		}
+		request := NewRequest(connID, msg.Meta)
+		session, err := app.maneuvers.registry.NewSession(name, func(evaluation *maneuvers.Evaluation) {
+			app.onEvaluation(evaluation, request, request.Meta)
+		})
+		if err != nil {
+			app.sendResult(msg, connID, err)
+			return
+		}
+		f := app.addClientFeed(request, name, session)
+		log.Infof("Started evaluation of %s for %s (request %s)", name, connID, f.request.ID)
+
+	case evaluateActionStop, evaluateActionReset:
+		for _, f := range app.clientEvaluations(connID, msg.Meta, name) {
+			if action == evaluateActionReset {
+				f.consumer.(*maneuvers.Session).Reset()
+				continue
+			}
+			requestID := f.request.ID
+			removed := app.dropRequests(func(request *Request) bool {
+				return request.ID == requestID
+			})
+			if len(removed) > 0 {
+				log.Infof("Stopped evaluation of %s for %s", name, connID)
+			}
+		}
+
+	default:
+		log.Warnf("Received evaluate message with unknown action %q from %s", action, connID)
+	}
+}
+
+func (app *App) clientEvaluations(connID, meta, name string) []*feed {
+	feeds := make([]*feed, 0)
+	for _, f := range app.feeds.all() {
+		_, isSession := f.consumer.(*maneuvers.Session)
+		if isSession && f.request.ClientID() == connID && f.request.Meta == meta && f.name == name {
+			feeds = append(feeds, f)
+		}
+	}
+	return feeds
+}
+
+// evaluationCount returns the number of evaluations of a client.
+func (app *App) evaluationCount(connID string) int {
+	count := 0
+	for _, f := range app.feeds.all() {
+		if _, isSession := f.consumer.(*maneuvers.Session); isSession && f.request.ClientID() == connID {
+			count++
+		}
+	}
+	return count
+}
+
+func (app *App) maneuverRoutes(headers map[string]string) []webserver.Route {
+	routes := []webserver.Route{
+		{Pattern: "/api/maneuvers", Handler: app.maneuversHandler(headers)},
+	}
+	if app.maneuvers.store != nil {
+		routes = append(routes,
+			webserver.Route{Pattern: "/api/maneuvers/results", Handler: app.maneuverResultsHandler(headers, "")},
+			webserver.Route{Pattern: "/api/maneuvers/results/{id}", Handler: app.maneuverResultHandler(headers)},
+		)
+	}
+	if app.maneuvers.steepTurns != nil {
+		routes = append(routes, webserver.Route{Pattern: "/api/steepturns/current", Handler: app.steepTurnsCurrentHandler(headers)})
+		if app.maneuvers.store != nil {
+			routes = append(routes,
+				webserver.Route{Pattern: "/api/steepturns", Handler: app.maneuverResultsHandler(headers, steepturns.Maneuver)},
+				webserver.Route{Pattern: "/api/steepturns/{id}", Handler: app.maneuverResultHandler(headers)},
+			)
+		}
+	}
+	return routes
+}
+
+func (app *App) maneuversHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, headers, http.StatusOK, app.maneuvers.registry.Specs())
+	}
+}
+
+// maneuverResultsHandler lists the stored results of a maneuver. Without a
+// maneuver, the "maneuver" query parameter may be used to filter the results.
+func (app *App) maneuverResultsHandler(headers map[string]string, maneuver string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		name := maneuver
+		if name == "" {
+			name = r.URL.Query().Get("maneuver")
+		}
+		results, err := app.maneuvers.store.List(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, headers, http.StatusOK, results)
+	}
+}
+
+func (app *App) maneuverResultHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		result, err := app.maneuvers.store.Get(mux.Vars(r)["id"])
+		if errors.Is(err, maneuvers.ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, headers, http.StatusOK, result)
+	}
+}
+
+// steepTurnsCurrentHandler returns the latest steep turns evaluation. DELETE
+// aborts the attempt in progress.
+func (app *App) steepTurnsCurrentHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodDelete:
+			app.maneuvers.steepTurns.Reset()
+		default:
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, headers, http.StatusOK, app.maneuvers.steepTurns.Status())
+	}
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"testing"
+
+	"msfs2020-gopilot/internal/maneuvers/chandelles"
+	"msfs2020-gopilot/internal/maneuvers/lazyeights"
+	"msfs2020-gopilot/internal/maneuvers/slowflight"
+	"msfs2020-gopilot/internal/maneuvers/stalls"
+	"msfs2020-gopilot/internal/maneuvers/steepturns"
+)
+
+func TestEvaluationLimit(t *testing.T) {
+	app := newTestApp(t, nil)
+	client := dialTestApp(t, serveTestApp(t, app))
+
+	// Starting an evaluation again does not add another one.
+	client.send("evaluate", "lesson", map[string]interface{}{"maneuver": steepturns.Maneuver})
+	client.send("evaluate", "lesson", map[string]interface{}{"maneuver": steepturns.Maneuver})
+	for _, name := range []string{slowflight.Maneuver, stalls.Maneuver, chandelles.Maneuver} {
+		client.send("evaluate", "lesson", map[string]interface{}{"maneuver": name})
+	}
+	waitFor(t, "the evaluations to start", func() bool { return len(evaluationFeeds(app)) == maxClientEvaluations })
+
+	client.send("evaluate", "lesson", map[string]interface{}{"maneuver": lazyeights.Maneuver})
+	result := client.receive("result")["data"].(map[string]interface{})
+	if result["request"] != "evaluate" || result["ok"] != false || result["error"] != "at most 4 evaluations per client" {
+		t.Errorf("result %v, want an error", result)
+	}
+	if n := len(evaluationFeeds(app)); n != maxClientEvaluations {
+		t.Errorf("%d evaluations, want %d", n, maxClientEvaluations)
+	}
+
+	client.send("evaluate", "lesson", map[string]interface{}{"maneuver": steepturns.Maneuver, "action": "stop"})
+	waitFor(t, "the evaluation to stop", func() bool { return len(evaluationFeeds(app)) == maxClientEvaluations-1 })
+	client.send("evaluate", "lesson", map[string]interface{}{"maneuver": "barrel_roll"})
+	if result := client.receive("result")["data"].(map[string]interface{}); result["ok"] != false {
+		t.Errorf("result %v for an unknown maneuver", result)
+	}
+	client.send("evaluate", "lesson", map[string]interface{}{"maneuver": lazyeights.Maneuver})
+	waitFor(t, "the evaluation to start", func() bool { return len(evaluationFeeds(app)) == maxClientEvaluations })
+}
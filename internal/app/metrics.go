@@ -0,0 +1,93 @@
+package app
+
+import (
+	"msfs2020-gopilot/internal/metrics"
+
+	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	metricsNamespace = "gopilot_"
+
+	dropReasonMalformed        = "malformed"
+	dropReasonUnknownType      = "unknown_type"
+	dropReasonUnknownRecipient = "unknown_recipient"
+	dropReasonEncodingFailed   = "encoding_failed"
+)
+
+type appMetrics struct {
+	registry          *metrics.Registry
+	messagesIn        *metrics.CounterVec
+	messagesOut       *metrics.CounterVec
+	messagesDropped   *metrics.CounterVec
+	slowClients       *metrics.Counter
+	dataReadyDuration *metrics.Histogram
+	airportQueries    *metrics.Histogram
+}
+
+func newAppMetrics(app *App) *appMetrics {
+	m := &appMetrics{
+		registry: metrics.NewRegistry(),
+		messagesIn: metrics.NewCounterVec(metricsNamespace+"messages_received_total",
+			"Number of WebSocket messages received, by message type.", "type"),
+		messagesOut: metrics.NewCounterVec(metricsNamespace+"messages_sent_total",
+			"Number of WebSocket messages sent, by message type.", "type"),
+		messagesDropped: metrics.NewCounterVec(metricsNamespace+"messages_dropped_total",
+			"Number of WebSocket messages that were dropped, by reason.", "reason"),
+		slowClients: metrics.NewCounter(metricsNamespace+"websocket_slow_clients_total",
+			"Number of WebSocket clients disconnected because they could not keep up."),
+		dataReadyDuration: metrics.NewHistogram(metricsNamespace+"data_ready_duration_seconds",
+			"Time spent distributing SimVar values to clients in OnDataReady.", metrics.DefaultBuckets),
+		airportQueries: metrics.NewHistogram(metricsNamespace+"airport_query_duration_seconds",
+			"Time spent searching the airport database.", metrics.DefaultBuckets),
+	}
+
+	collectors := []metrics.Collector{
+		m.messagesIn,
+		m.messagesOut,
+		m.messagesDropped,
+		m.slowClients,
+		m.dataReadyDuration,
+		m.airportQueries,
+		metrics.NewGaugeFunc(metricsNamespace+"websocket_clients", "Number of connected WebSocket clients.", func() float64 {
+			if app.socket == nil {
+				return 0
+			}
+			return float64(app.socket.ConnectionCount())
+		}),
+		metrics.NewGaugeFunc(metricsNamespace+"simvars_registered", "Number of SimVars registered with SimConnect.", func() float64 {
+			if app.mate == nil {
+				return 0
+			}
+			return float64(len(app.mate.SimVarDump("")))
+		}),
+		metrics.NewGaugeFunc(metricsNamespace+"requests_active", "Number of active SimVar requests.", func() float64 {
+			return float64(app.requestManager.RequestCount())
+		}),
+		metrics.NewGaugeFunc(metricsNamespace+"simconnect_initialized", "Whether the SimConnect library has been loaded (1) or not (0).", func() float64 {
+			return boolToFloat(simconnect.IsInitialized())
+		}),
+		metrics.NewGaugeFunc(metricsNamespace+"simconnect_connected", "Whether GoPilot is connected to the simulator (1) or not (0).", func() float64 {
+			if app.mate == nil {
+				return 0
+			}
+			return boolToFloat(app.mate.IsConnected())
+		}),
+	}
+	// Metrics which cannot be registered still count, they are just not
+	// exposed.
+	for _, c := range collectors {
+		if err := m.registry.Register(c); err != nil {
+			log.Error(err)
+		}
+	}
+	return m
+}
+
+func boolToFloat(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"msfs2020-gopilot/internal/mqttbridge"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	mqttRetryInterval     = 5    // seconds
+	mqttDisconnectTimeout = 250  // milliseconds
+	mqttPublishTimeout    = 1000 // milliseconds
+)
+
+type mqttService struct {
+	client    paho.Client
+	publisher *mqttbridge.Publisher
+}
+
+func (app *App) initMQTT() {
+	cfg := app.cfg.MQTT
+	publisher, err := mqttbridge.NewPublisher(cfg.TopicPrefix, cfg.SimVars)
+	if err != nil {
+		log.Error("MQTT bridge will not be available: ", err)
+		return
+	}
+	qos := byte(cfg.QoS)
+	statusTopic := mqttbridge.StatusTopic(cfg.TopicPrefix)
+
+	opts := paho.NewClientOptions().
+		AddBroker(cfg.Broker).
+		SetClientID(cfg.ClientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(mqttRetryInterval*time.Second).
+		SetWill(statusTopic, mqttbridge.StatusOffline, qos, true)
+	opts.SetOnConnectHandler(func(client paho.Client) {
+		log.Info("Connected to MQTT broker ", cfg.Broker)
+		client.Publish(statusTopic, qos, true, mqttbridge.StatusOnline)
+		for _, command := range []string{mqttbridge.CommandSetData, mqttbridge.CommandTeleport, mqttbridge.CommandEvent} {
+			topic := mqttbridge.CommandTopic(cfg.TopicPrefix, command)
+			client.Subscribe(topic, qos, app.mqttCommandHandler(command))
+		}
+		// Publish all values again, the broker may have lost the retained ones.
+		publisher.Reset()
+	})
+	opts.SetConnectionLostHandler(func(client paho.Client, err error) {
+		log.Warn("Lost connection to MQTT broker: ", err)
+	})
+
+	app.mqtt = &mqttService{
+		client:    paho.NewClient(opts),
+		publisher: publisher,
+	}
+	if len(publisher.Vars()) > 0 {
+		app.AddConsumer("mqtt", publisher)
+	}
+}
+
+// runMQTT connects to the broker and publishes the changed SimVars at the
+// configured interval until ctx is cancelled.
+func (app *App) runMQTT(ctx context.Context) {
+	cfg := app.cfg.MQTT
+	client := app.mqtt.client
+	// Connect keeps retrying in the background.
+	client.Connect()
+	defer func() {
+		if client.IsConnectionOpen() {
+			token := client.Publish(mqttbridge.StatusTopic(cfg.TopicPrefix), byte(cfg.QoS), true, mqttbridge.StatusOffline)
+			token.WaitTimeout(mqttPublishTimeout * time.Millisecond)
+		}
+		client.Disconnect(mqttDisconnectTimeout)
+	}()
+
+	ticker := time.NewTicker(time.Duration(cfg.Interval) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if !client.IsConnectionOpen() {
+				continue
+			}
+			for _, msg := range app.mqtt.publisher.Changes() {
+				client.Publish(msg.Topic, byte(cfg.QoS), cfg.Retain, msg.Payload)
+			}
+		}
+	}
+}
+
+// mqttCommandHandler bridges a command topic to the handler of the
+// corresponding WebSocket message. The payload is the message's data, e.g.
+// {"name": "PARKING_BRAKES"}. Events may also be given by name only.
+func (app *App) mqttCommandHandler(command string) paho.MessageHandler {
+	return func(client paho.Client, m paho.Message) {
+		msg := &Message{Type: command, Data: make(map[string]interface{})}
+		if err := json.Unmarshal(m.Payload(), &msg.Data); err != nil {
+			name := strings.TrimSpace(string(m.Payload()))
+			if command != mqttbridge.CommandEvent || name == "" || strings.ContainsAny(name, "{[\"") {
+				log.Warnf("Received malformed MQTT command on %s: %s", m.Topic(), err)
+				app.metrics.messagesDropped.Inc(dropReasonMalformed)
+				return
+			}
+			msg.Data = map[string]interface{}{"name": name}
+		}
+		log.Debug("MQTT command ", m.Topic(), msg.Data)
+		app.metrics.messagesIn.Inc(command)
+
+		var err error
+		switch command {
+		case mqttbridge.CommandSetData:
+			err = app.handleSetDataMessage(msg)
+
+		case mqttbridge.CommandTeleport:
+			err = app.handleTeleportMessage(msg)
+
+		case mqttbridge.CommandEvent:
+			err = app.handleEventMessage(msg)
+		}
+		if err != nil {
+			log.Warnf("Failed to handle MQTT command on %s: %s", m.Topic(), err)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"msfs2020-gopilot/internal/config"
+	"msfs2020-gopilot/internal/mqttbridge"
+	"msfs2020-gopilot/internal/telemetry"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+type fakeToken struct{}
+
+func (fakeToken) Wait() bool                     { return true }
+func (fakeToken) WaitTimeout(time.Duration) bool { return true }
+func (fakeToken) Error() error                   { return nil }
+func (fakeToken) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+type fakePublish struct {
+	topic    string
+	retained bool
+	payload  string
+}
+
+// fakeMQTTClient is a paho.Client which is always connected and records what
+// is published.
+type fakeMQTTClient struct {
+	published    []fakePublish
+	disconnected bool
+	mutex        sync.Mutex
+}
+
+func (c *fakeMQTTClient) IsConnected() bool      { return true }
+func (c *fakeMQTTClient) IsConnectionOpen() bool { return true }
+func (c *fakeMQTTClient) Connect() paho.Token    { return fakeToken{} }
+
+func (c *fakeMQTTClient) Disconnect(quiesce uint) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.disconnected = true
+}
+
+func (c *fakeMQTTClient) Publish(topic string, qos byte, retained bool, payload interface{}) paho.Token {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.published = append(c.published, fakePublish{topic: topic, retained: retained, payload: payload.(string)})
+	return fakeToken{}
+}
+
+func (c *fakeMQTTClient) Subscribe(topic string, qos byte, callback paho.MessageHandler) paho.Token {
+	return fakeToken{}
+}
+
+func (c *fakeMQTTClient) SubscribeMultiple(filters map[string]byte, callback paho.MessageHandler) paho.Token {
+	return fakeToken{}
+}
+
+func (c *fakeMQTTClient) Unsubscribe(topics ...string) paho.Token             { return fakeToken{} }
+func (c *fakeMQTTClient) AddRoute(topic string, callback paho.MessageHandler) {}
+func (c *fakeMQTTClient) OptionsReader() paho.ClientOptionsReader {
+	return paho.ClientOptionsReader{}
+}
+
+func (c *fakeMQTTClient) publishes() []fakePublish {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]fakePublish(nil), c.published...)
+}
+
+func TestMQTTPublishesChanges(t *testing.T) {
+	for _, retain := range []bool{false, true} {
+		app := newTestApp(t, func(cfg *config.Config) {
+			cfg.MQTT.Enabled = true
+			cfg.MQTT.SimVars = []string{"PLANE ALTITUDE:feet"}
+			cfg.MQTT.Interval = 10
+			cfg.MQTT.Retain = retain
+		})
+		client := &fakeMQTTClient{}
+		app.mqtt.client = client
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			app.runMQTT(ctx)
+			close(done)
+		}()
+
+		sample := telemetry.NewSample(time.Now())
+		sample.Set("PLANE ALTITUDE", 1500.0)
+		app.mqtt.publisher.Consume(sample)
+		topic := mqttbridge.SimVarTopic(app.cfg.MQTT.TopicPrefix, "PLANE ALTITUDE")
+		waitFor(t, "the altitude to be published", func() bool {
+			return len(client.publishes()) > 0
+		})
+		cancel()
+		<-done
+
+		published := client.publishes()
+		if got := published[0]; got.topic != topic || got.payload != "1500" || got.retained != retain {
+			t.Errorf("retain %t: published %+v, want %s = 1500", retain, got, topic)
+		}
+		last := published[len(published)-1]
+		if last.topic != mqttbridge.StatusTopic(app.cfg.MQTT.TopicPrefix) || last.payload != mqttbridge.StatusOffline || !last.retained {
+			t.Errorf("retain %t: last message %+v, want the retained offline status", retain, last)
+		}
+		if !client.disconnected {
+			t.Errorf("retain %t: client was not disconnected", retain)
+		}
+	}
+}
+
+type fakeMessage struct {
+	topic   string
+	payload string
+}
+
+func (m fakeMessage) Duplicate() bool   { return false }
+func (m fakeMessage) Qos() byte         { return 0 }
+func (m fakeMessage) Retained() bool    { return false }
+func (m fakeMessage) Topic() string     { return m.topic }
+func (m fakeMessage) MessageID() uint16 { return 0 }
+func (m fakeMessage) Payload() []byte   { return []byte(m.payload) }
+func (m fakeMessage) Ack()              {}
+
+// TestMQTTCommands checks how command payloads reach the handlers. The test app
+// is not connected to the simulator, so a command which made it through is
+// rejected with errNotConnected.
+func TestMQTTCommands(t *testing.T) {
+	hook := logtest.NewGlobal()
+	t.Cleanup(func() {
+		log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+	})
+
+	tests := []struct {
+		command string
+		payload string
+		err     string // logged; empty if the payload is dropped
+		dropped bool
+	}{
+		{command: mqttbridge.CommandSetData, payload: `{"name": "PLANE ALTITUDE", "unit": "feet", "value": 3000}`, err: errNotConnected.Error()},
+		{command: mqttbridge.CommandSetData, payload: `{"name": "PLANE ALTITUDE", "unit": "feet"}`, err: "missing or invalid value"},
+		{command: mqttbridge.CommandSetData, payload: `{"name": "PLANE ALTITUDE", "unit": "feet", "value": "high"}`, err: "missing or invalid value"},
+		{command: mqttbridge.CommandTeleport, payload: `{"latitude": 51.2895, "longitude": 6.7668, "altitude": 3000, "heading": 230, "airspeed": 110}`, err: errNotConnected.Error()},
+		{command: mqttbridge.CommandTeleport, payload: `{"latitude": 51.2895, "longitude": 6.7668}`, err: "missing or invalid altitude"},
+		{command: mqttbridge.CommandEvent, payload: `{"name": "AP_ALT_VAR_SET_ENGLISH", "data": 5000}`, err: errNotConnected.Error()},
+		{command: mqttbridge.CommandEvent, payload: ` PARKING_BRAKES` + "\n", err: errNotConnected.Error()},
+		{command: mqttbridge.CommandEvent, payload: `{"data": 1}`, err: "missing or invalid name"},
+		{command: mqttbridge.CommandEvent, payload: `{"name": "PARKING_BRAKES"`, dropped: true},
+		{command: mqttbridge.CommandEvent, payload: ``, dropped: true},
+		{command: mqttbridge.CommandSetData, payload: `PLANE ALTITUDE`, dropped: true},
+		{command: mqttbridge.CommandTeleport, payload: `[51.2895, 6.7668]`, dropped: true},
+	}
+	for _, test := range tests {
+		app := newTestApp(t, nil)
+		hook.Reset()
+		topic := mqttbridge.CommandTopic(app.cfg.MQTT.TopicPrefix, test.command)
+		app.mqttCommandHandler(test.command)(&fakeMQTTClient{}, fakeMessage{topic: topic, payload: test.payload})
+
+		dropped := app.metrics.messagesDropped.Value(dropReasonMalformed)
+		received := app.metrics.messagesIn.Value(test.command)
+		if test.dropped {
+			if dropped != 1 || received != 0 {
+				t.Errorf("%s %q: %g dropped, %g received, want dropped", test.command, test.payload, dropped, received)
+			}
+			continue
+		}
+		if dropped != 0 || received != 1 {
+			t.Errorf("%s %q: %g dropped, %g received, want received", test.command, test.payload, dropped, received)
+		}
+		want := "Failed to handle MQTT command on " + topic + ": " + test.err
+		if entry := hook.LastEntry(); entry == nil || entry.Level != log.WarnLevel || entry.Message != want {
+			t.Errorf("%s %q: logged %+v, want %q", test.command, test.payload, entry, want)
+		}
+	}
+}
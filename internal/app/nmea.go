@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"msfs2020-gopilot/internal/nmea"
+	"msfs2020-gopilot/internal/ownship"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	nmeaWriteTimeout = 1 // seconds
+)
+
+type nmeaService struct {
+	tracker  *ownship.Tracker
+	listener net.Listener
+	udp      net.Conn
+	device   *os.File
+	clients  map[net.Conn]bool
+	mutex    sync.Mutex
+}
+
+func (app *App) initNMEA() {
+	cfg := app.cfg.NMEA
+	service := &nmeaService{clients: make(map[net.Conn]bool)}
+	if cfg.TCPAddress != "" {
+		listener, err := net.Listen("tcp", cfg.TCPAddress)
+		if err != nil {
+			log.Warn("NMEA will not be served over TCP: ", err)
+		} else {
+			service.listener = listener
+			log.Infof("Serving NMEA on tcp://%s", cfg.TCPAddress)
+		}
+	}
+	if cfg.UDPAddress != "" {
+		conn, err := net.Dial("udp", cfg.UDPAddress)
+		if err != nil {
+			log.Warn("NMEA will not be sent over UDP: ", err)
+		} else {
+			service.udp = conn
+			log.Infof("Sending NMEA to udp://%s", cfg.UDPAddress)
+		}
+	}
+	if cfg.Device != "" {
+		device, err := os.OpenFile(cfg.Device, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			log.Warn("NMEA will not be written to the device: ", err)
+		} else {
+			service.device = device
+			log.Infof("Writing NMEA to %s", cfg.Device)
+		}
+	}
+	if service.listener == nil && service.udp == nil && service.device == nil {
+		return
+	}
+	service.tracker = app.ownshipTracker()
+	app.nmea = service
+}
+
+func (app *App) runNMEA(ctx context.Context) {
+	service := app.nmea
+	if service.listener != nil {
+		go service.accept()
+	}
+	defer service.close()
+
+	ticker := time.NewTicker(time.Second / time.Duration(app.cfg.NMEA.Rate))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			state, ok := service.tracker.State()
+			if !ok {
+				continue
+			}
+			fix := &nmea.Fix{
+				Time:             state.Time,
+				Latitude:         state.Latitude,
+				Longitude:        state.Longitude,
+				Altitude:         state.Altitude,
+				GroundSpeed:      state.GroundSpeed,
+				Track:            state.Track,
+				MagneticVariance: state.MagneticVariance,
+			}
+			service.write([]byte(strings.Join(nmea.Sentences(fix), "")))
+		}
+	}
+}
+
+func (service *nmeaService) accept() {
+	for {
+		conn, err := service.listener.Accept()
+		if err != nil {
+			// The listener was closed.
+			return
+		}
+		log.Info("NMEA client connected: ", conn.RemoteAddr())
+		service.mutex.Lock()
+		service.clients[conn] = true
+		service.mutex.Unlock()
+	}
+}
+
+func (service *nmeaService) write(buf []byte) {
+	service.mutex.Lock()
+	for conn := range service.clients {
+		conn.SetWriteDeadline(time.Now().Add(nmeaWriteTimeout * time.Second))
+		if _, err := conn.Write(buf); err != nil {
+			log.Info("NMEA client disconnected: ", conn.RemoteAddr())
+			conn.Close()
+			delete(service.clients, conn)
+		}
+	}
+	service.mutex.Unlock()
+
+	if service.udp != nil {
+		if _, err := service.udp.Write(buf); err != nil {
+			log.Debug("Unable to send NMEA: ", err)
+		}
+	}
+	if service.device != nil {
+		if _, err := service.device.Write(buf); err != nil {
+			log.Error("Unable to write NMEA to the device, giving up: ", err)
+			service.device.Close()
+			service.device = nil
+		}
+	}
+}
+
+func (service *nmeaService) close() {
+	if service.listener != nil {
+		service.listener.Close()
+	}
+	service.mutex.Lock()
+	for conn := range service.clients {
+		conn.Close()
+	}
+	service.clients = make(map[net.Conn]bool)
+	service.mutex.Unlock()
+	if service.udp != nil {
+		service.udp.Close()
+	}
+	if service.device != nil {
+		service.device.Close()
+	}
+}
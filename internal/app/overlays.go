@@ -0,0 +1,58 @@
+package app
+
+import (
+	"net/http"
+
+	"msfs2020-gopilot/internal/overlay"
+	"msfs2020-gopilot/internal/webserver"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+func (app *App) overlayRoutes(htmlHeaders, jsonHeaders map[string]string) []webserver.Route {
+	overlays := overlay.New(app.cfg.Overlays.Dir)
+	return []webserver.Route{
+		{Pattern: "/overlay/{name}", Handler: app.overlayHandler(htmlHeaders, overlays)},
+		{Pattern: "/api/overlays", Handler: app.overlayListHandler(jsonHeaders, overlays)},
+	}
+}
+
+func (app *App) overlayHandler(headers map[string]string, overlays *overlay.Overlays) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		name := mux.Vars(r)["name"]
+		for key, value := range headers {
+			w.Header().Set(key, value)
+		}
+		if err := overlays.Render(w, name, r.URL.Query()); err != nil {
+			if err == overlay.ErrNotFound {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+			log.Warnf("Unable to render overlay %s: %s", name, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+func (app *App) overlayListHandler(headers map[string]string, overlays *overlay.Overlays) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		names, err := overlays.Names()
+		if err != nil {
+			writeJSON(w, headers, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		if names == nil {
+			names = []string{}
+		}
+		writeJSON(w, headers, http.StatusOK, names)
+	}
+}
@@ -0,0 +1,16 @@
+package app
+
+import (
+	"msfs2020-gopilot/internal/ownship"
+)
+
+// ownshipTracker returns the tracker of the user aircraft shared by the
+// navigation outputs. It is registered as a consumer on first use, so it has
+// to be called before Run.
+func (app *App) ownshipTracker() *ownship.Tracker {
+	if app.ownship == nil {
+		app.ownship = ownship.NewTracker()
+		app.AddConsumer("ownship", app.ownship)
+	}
+	return app.ownship
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"msfs2020-gopilot/internal/flightlog"
+	"msfs2020-gopilot/internal/ownship"
+	"msfs2020-gopilot/internal/presence"
+	"msfs2020-gopilot/internal/webserver"
+
+	alphafoxtrot "github.com/grumpypixel/go-airport-finder"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	presenceAirportRadius = 20 * 1000.0 // meters
+	presenceHTTPTimeout   = 5           // seconds
+)
+
+type presenceService struct {
+	composer *presence.Composer
+	aircraft *presence.Aircraft
+	ownship  *ownship.Tracker
+	flights  *flightlog.Tracker
+	sinks    []presence.Sink
+	status   string
+	data     *presence.Data
+	mutex    sync.Mutex
+}
+
+func (app *App) initPresence() {
+	cfg := app.cfg.Presence
+	text := cfg.Template
+	if cfg.TemplateFile != "" {
+		buf, err := ioutil.ReadFile(cfg.TemplateFile)
+		if err != nil {
+			log.Error("Status will not be published: ", err)
+			return
+		}
+		text = string(buf)
+	}
+	if text == "" {
+		text = presence.DefaultTemplate
+	}
+	composer, err := presence.NewComposer(text)
+	if err != nil {
+		log.Error("Status will not be published: ", err)
+		return
+	}
+
+	service := &presenceService{
+		composer: composer,
+		aircraft: presence.NewAircraft(),
+		ownship:  app.ownshipTracker(),
+		flights:  app.flightTracker(),
+	}
+	app.AddConsumer("presence", service.aircraft)
+
+	if cfg.File != "" {
+		service.sinks = append(service.sinks, presence.NewFileSink(cfg.File))
+	}
+	if cfg.OBSFile != "" {
+		service.sinks = append(service.sinks, presence.NewOBSSink(cfg.OBSFile))
+	}
+	if cfg.HTTPURL != "" {
+		service.sinks = append(service.sinks, presence.NewHTTPSink(cfg.HTTPURL, presenceHTTPTimeout*time.Second))
+	}
+	app.presence = service
+}
+
+// runPresence composes the status at the configured interval and publishes it
+// to all sinks whenever it changed.
+func (app *App) runPresence(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(app.cfg.Presence.Interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			data := app.presenceData()
+			status, err := app.presence.composer.Compose(data)
+			if err != nil {
+				log.Warn("Unable to compose status: ", err)
+				continue
+			}
+			app.presence.mutex.Lock()
+			changed := status != app.presence.status
+			app.presence.status = status
+			app.presence.data = data
+			app.presence.mutex.Unlock()
+			if !changed {
+				continue
+			}
+			log.Debug("Status: ", status)
+			for _, sink := range app.presence.sinks {
+				if err := sink.Publish(status, data); err != nil {
+					log.Warnf("Unable to publish status to %s: %s", sink.Name(), err)
+				}
+			}
+		}
+	}
+}
+
+func (app *App) presenceData() *presence.Data {
+	service := app.presence
+	data := &presence.Data{Time: time.Now()}
+	state, ok := service.ownship.State()
+	data.Connected = ok && app.mate.IsConnected()
+	if ok {
+		data.ATCID = state.ATCID
+		data.Latitude = state.Latitude
+		data.Longitude = state.Longitude
+		data.Altitude = state.Altitude
+		data.PressureAltitude = state.PressureAltitude
+		data.GroundSpeed = state.GroundSpeed
+		data.Heading = state.MagneticHeading
+		if app.airportFinder != nil {
+			airport := app.airportFinder.FindNearestAirport(state.Latitude, state.Longitude, presenceAirportRadius, alphafoxtrot.AirportTypeActive)
+			if airport != nil {
+				data.NearestAirport = airport.ICAOCode
+				data.NearestAirportName = airport.Name
+			}
+		}
+	}
+	status := service.flights.Status()
+	data.Phase = string(status.Phase)
+	data.Airborne = status.Phase.Airborne()
+	if flight := status.Flight; flight != nil {
+		if flight.Departure != nil {
+			data.Departure = flight.Departure.ICAO
+		}
+		if flight.Arrival != nil {
+			data.Arrival = flight.Arrival.ICAO
+		}
+	}
+	service.aircraft.Fill(data)
+	return data
+}
+
+func (app *App) presenceRoutes(headers map[string]string) []webserver.Route {
+	if app.presence == nil {
+		return nil
+	}
+	return []webserver.Route{
+		{Pattern: "/api/presence", Handler: app.presenceHandler(headers)},
+	}
+}
+
+func (app *App) presenceHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		app.presence.mutex.Lock()
+		resp := map[string]interface{}{
+			"status": app.presence.status,
+			"data":   app.presence.data,
+		}
+		app.presence.mutex.Unlock()
+		writeJSON(w, headers, http.StatusOK, resp)
+	}
+}
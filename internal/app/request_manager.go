@@ -3,27 +3,46 @@ package app
 import (
 	"sync"
 
+	"github.com/google/uuid"
 	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect"
 )
 
 type Var struct {
 	Name, Moniker string
+	// Index of the moniker in the moniker table of the request.
+	Index int
 }
 
 type Request struct {
-	ClientID string
-	Meta     string
-	Vars     map[simconnect.DWord]*Var
+	ID   string
+	Meta string
+	Vars map[simconnect.DWord]*Var
+	// Monikers is sent to clients with a binary encoding, which receive the
+	// values keyed by the index in this table rather than by moniker.
+	Monikers []string
+	// The client changes when a session is resumed, see ClientID.
+	clientID    string
+	clientMutex sync.RWMutex
 }
 
 func NewRequest(clientID string, meta string) *Request {
 	return &Request{
-		ClientID: clientID,
+		ID:       uuid.New().String(),
 		Meta:     meta,
 		Vars:     make(map[simconnect.DWord]*Var),
+		clientID: clientID,
 	}
 }
 
+// ClientID returns the client the request belongs to. Everything that is sent
+// for the request has to look it up rather than keep it, since a client which
+// resumes its session takes the request over.
+func (req *Request) ClientID() string {
+	req.clientMutex.RLock()
+	defer req.clientMutex.RUnlock()
+	return req.clientID
+}
+
 func (req *Request) Add(defineID simconnect.DWord, name, moniker string) bool {
 	if len(name) == 0 {
 		return false
@@ -31,7 +50,13 @@ func (req *Request) Add(defineID simconnect.DWord, name, moniker string) bool {
 	if len(moniker) == 0 {
 		moniker = name
 	}
-	req.Vars[defineID] = &Var{name, moniker}
+	if v, exists := req.Vars[defineID]; exists {
+		v.Name, v.Moniker = name, moniker
+		req.Monikers[v.Index] = moniker
+		return true
+	}
+	req.Vars[defineID] = &Var{Name: name, Moniker: moniker, Index: len(req.Monikers)}
+	req.Monikers = append(req.Monikers, moniker)
 	return true
 }
 
@@ -42,33 +67,74 @@ func (req *Request) GetVar(defineID simconnect.DWord) (string, string, bool) {
 	return "", "", false
 }
 
+// RequestManager holds the active requests. Apart from its client, a request
+// must not be changed once it was added, so the requests returned by All can be
+// used without holding a lock.
 type RequestManager struct {
-	Requests []*Request
+	requests []*Request
 	mutex    sync.Mutex
 }
 
 func NewRequestManager() *RequestManager {
 	mgr := &RequestManager{
-		Requests: make([]*Request, 0),
+		requests: make([]*Request, 0),
 	}
 	return mgr
 }
 
 func (mgr *RequestManager) RequestCount() int {
-	return len(mgr.Requests)
+	mgr.mutex.Lock()
+	defer mgr.mutex.Unlock()
+	return len(mgr.requests)
+}
+
+// All returns a snapshot of the requests.
+func (mgr *RequestManager) All() []*Request {
+	mgr.mutex.Lock()
+	defer mgr.mutex.Unlock()
+	requests := make([]*Request, len(mgr.requests))
+	copy(requests, mgr.requests)
+	return requests
 }
 
 func (mgr *RequestManager) AddRequest(request *Request) {
 	mgr.mutex.Lock()
 	defer mgr.mutex.Unlock()
-	mgr.Requests = append(mgr.Requests, request)
+	mgr.requests = append(mgr.requests, request)
+}
+
+func (mgr *RequestManager) RemoveRequest(requestID string) (*Request, bool) {
+	removed := mgr.RemoveRequests(func(request *Request) bool {
+		return request.ID == requestID
+	})
+	if len(removed) == 0 {
+		return nil, false
+	}
+	return removed[0], true
+}
+
+// RemoveRequests removes the requests which match and returns them.
+func (mgr *RequestManager) RemoveRequests(match func(request *Request) bool) []*Request {
+	mgr.mutex.Lock()
+	defer mgr.mutex.Unlock()
+	kept := make([]*Request, 0, len(mgr.requests))
+	removed := make([]*Request, 0)
+	for _, request := range mgr.requests {
+		if match(request) {
+			removed = append(removed, request)
+		} else {
+			kept = append(kept, request)
+		}
+	}
+	mgr.requests = kept
+	return removed
 }
 
 func (mgr *RequestManager) RefCount(simVarName string) int {
 	mgr.mutex.Lock()
 	defer mgr.mutex.Unlock()
 	count := 0
-	for _, request := range mgr.Requests {
+	for _, request := range mgr.requests {
 		for _, v := range request.Vars {
 			if v.Name == simVarName {
 				count++
@@ -77,3 +143,21 @@ func (mgr *RequestManager) RefCount(simVarName string) int {
 	}
 	return count
 }
+
+// Reassign hands the requests of a client over to another client. The
+// requests are changed in place, so that feeds and evaluations which hold on
+// to them follow the new client.
+func (mgr *RequestManager) Reassign(fromClientID, toClientID string) []*Request {
+	mgr.mutex.Lock()
+	defer mgr.mutex.Unlock()
+	reassigned := make([]*Request, 0)
+	for _, request := range mgr.requests {
+		request.clientMutex.Lock()
+		if request.clientID == fromClientID {
+			request.clientID = toClientID
+			reassigned = append(reassigned, request)
+		}
+		request.clientMutex.Unlock()
+	}
+	return reassigned
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect"
+)
+
+func TestRequestAdd(t *testing.T) {
+	request := NewRequest("client", "meta")
+	if request.Add(1, "", "empty") {
+		t.Error("added a SimVar without a name")
+	}
+	request.Add(1, "PLANE ALTITUDE", "")
+	request.Add(2, "AIRSPEED INDICATED", "speed")
+	request.Add(1, "PLANE ALTITUDE", "altitude")
+
+	if name, moniker, ok := request.GetVar(1); !ok || name != "PLANE ALTITUDE" || moniker != "altitude" {
+		t.Errorf("GetVar(1) = %q, %q, %t", name, moniker, ok)
+	}
+	want := []string{"altitude", "speed"}
+	if fmt.Sprint(request.Monikers) != fmt.Sprint(want) {
+		t.Errorf("Monikers = %v, want %v", request.Monikers, want)
+	}
+	if request.Vars[2].Index != 1 {
+		t.Errorf("index of speed = %d, want 1", request.Vars[2].Index)
+	}
+}
+
+func TestRequestManager(t *testing.T) {
+	mgr := NewRequestManager()
+	a := NewRequest("a", "")
+	a.Add(1, "PLANE ALTITUDE", "")
+	b := NewRequest("b", "")
+	b.Add(1, "PLANE ALTITUDE", "")
+	mgr.AddRequest(a)
+	mgr.AddRequest(b)
+
+	if n := mgr.RefCount("PLANE ALTITUDE"); n != 2 {
+		t.Errorf("RefCount = %d, want 2", n)
+	}
+	if _, ok := mgr.RemoveRequest(a.ID); !ok {
+		t.Error("RemoveRequest did not find the request")
+	}
+	if _, ok := mgr.RemoveRequest(a.ID); ok {
+		t.Error("request was removed twice")
+	}
+	if n := mgr.RefCount("PLANE ALTITUDE"); n != 1 {
+		t.Errorf("RefCount = %d, want 1", n)
+	}
+	if n := mgr.RequestCount(); n != 1 {
+		t.Errorf("RequestCount = %d, want 1", n)
+	}
+}
+
+// Registrations, removals, reassignments and ticks from many goroutines, as
+// they happen with clients connecting while the simulator sends data. Run
+// with -race.
+func TestRequestManagerConcurrency(t *testing.T) {
+	mgr := NewRequestManager()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				clientID := fmt.Sprintf("client-%d-%d", i, j)
+				request := NewRequest(clientID, "")
+				request.Add(simconnect.DWord(j), "PLANE ALTITUDE", "")
+				mgr.AddRequest(request)
+				mgr.Reassign(clientID, clientID+"-resumed")
+				if j%2 == 0 {
+					mgr.RemoveRequests(func(request *Request) bool {
+						return request.ClientID() == clientID+"-resumed"
+					})
+				}
+			}
+		}(i)
+	}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				for _, request := range mgr.All() {
+					request.ClientID()
+					for defineID := range request.Vars {
+						request.GetVar(defineID)
+					}
+				}
+				mgr.RefCount("PLANE ALTITUDE")
+				mgr.RequestCount()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := mgr.RequestCount(); n != 8*50 {
+		t.Errorf("RequestCount = %d, want %d", n, 8*50)
+	}
+}
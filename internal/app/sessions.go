@@ -0,0 +1,114 @@
+package app
+
+import (
+	"encoding/json"
+	"time"
+
+	"msfs2020-gopilot/internal/session"
+	"msfs2020-gopilot/internal/util"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Messages which are kept for clients with a suspended session. Everything
+// else describes the current state and is sent again anyway.
+var bufferedMessageTypes = map[string]bool{
+	"alert":      true,
+	"evaluation": true,
+	"landing":    true,
+}
+
+func (app *App) initSessions() {
+	cfg := app.cfg.Sessions
+	grace := time.Duration(cfg.GracePeriod) * time.Second
+	app.sessions = session.NewStore(grace, int(cfg.MaxBuffered), func(clientID string) {
+		log.Info("Session expired: ", clientID)
+		app.removeRequests(clientID)
+	})
+}
+
+// openSession issues a session token to a newly connected client.
+func (app *App) openSession(connID string) {
+	token, err := app.sessions.Open(connID)
+	if err != nil {
+		log.Error("Unable to open a session: ", err)
+		return
+	}
+	app.sendSession(connID, token, false, "")
+}
+
+// closeSession releases the requests of a disconnected client, unless its
+// session can still be resumed.
+func (app *App) closeSession(connID string) {
+	if app.sessions != nil && app.sessions.Suspend(connID) {
+		log.Debugf("Session of %s suspended for %d s", connID, app.cfg.Sessions.GracePeriod)
+		return
+	}
+	app.removeRequests(connID)
+}
+
+// handleResumeMessage hands the requests of a suspended session over to the
+// sender and delivers the messages it missed. If the session can't be resumed,
+// the client has to register again.
+func (app *App) handleResumeMessage(msg *Message, connID string) {
+	if app.sessions == nil {
+		log.Warn("Sessions are disabled, ignoring resume message from ", connID)
+		return
+	}
+	token, _ := util.StringFromJson("token", msg.Data)
+	previous, buffered, ok := app.sessions.Resume(token, connID)
+	if !ok {
+		log.Info("Client could not resume session: ", connID)
+		token, _ = app.sessions.Token(connID)
+		app.sendSession(connID, token, false, msg.Meta)
+		return
+	}
+	// The previous connection may not have noticed yet that it is dead.
+	app.socket.Disconnect(previous)
+	requests := app.requestManager.Reassign(previous, connID)
+	log.Infof("Client %s resumed the session of %s (%d requests, %d missed messages)", connID, previous, len(requests), len(buffered))
+
+	app.sendSession(connID, token, true, msg.Meta)
+	for _, request := range requests {
+		app.sendMonikers(request)
+	}
+	for _, message := range buffered {
+		app.send(connID, message.Type, message.Data)
+	}
+}
+
+func (app *App) sendSession(connID, token string, resumed bool, meta string) {
+	msg := map[string]interface{}{
+		"type": "session",
+		"meta": meta,
+		"data": map[string]interface{}{
+			"token":       token,
+			"resumed":     resumed,
+			"gracePeriod": app.cfg.Sessions.GracePeriod,
+		},
+	}
+	if buf, err := json.Marshal(msg); err == nil {
+		app.send(connID, "session", buf)
+	} else {
+		app.metrics.messagesDropped.Inc(dropReasonEncodingFailed)
+	}
+}
+
+// keepForSession buffers a message for a client whose session is suspended.
+// It returns false if the client is gone for good.
+func (app *App) keepForSession(connID, msgType string, buf []byte) bool {
+	if app.sessions == nil || !app.sessions.IsSuspended(connID) {
+		return false
+	}
+	if bufferedMessageTypes[msgType] {
+		app.sessions.BufferFor(connID, session.Message{Type: msgType, Data: buf})
+	}
+	return true
+}
+
+// keepForSessions buffers a broadcast message for all suspended sessions.
+func (app *App) keepForSessions(msgType string, buf []byte) {
+	if app.sessions != nil && bufferedMessageTypes[msgType] {
+		app.sessions.Buffer(session.Message{Type: msgType, Data: buf})
+	}
+}
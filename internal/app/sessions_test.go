@@ -0,0 +1,139 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"msfs2020-gopilot/internal/config"
+	"msfs2020-gopilot/internal/maneuvers"
+	"msfs2020-gopilot/internal/maneuvers/steepturns"
+
+	"github.com/gorilla/websocket"
+)
+
+// serveTestApp serves the WebSocket of the app and handles its messages until
+// the test ends.
+func serveTestApp(t *testing.T, app *App) string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	go app.handleSocketMessages(ctx)
+	server := httptest.NewServer(http.HandlerFunc(app.socket.Serve))
+	t.Cleanup(func() {
+		shutdown, done := context.WithTimeout(context.Background(), time.Second)
+		defer done()
+		app.socket.Shutdown(shutdown, "test finished")
+		server.Close()
+		cancel()
+	})
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+type testClient struct {
+	t    *testing.T
+	conn *websocket.Conn
+}
+
+func dialTestApp(t *testing.T, url string) *testClient {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &testClient{t: t, conn: conn}
+}
+
+func (c *testClient) send(msgType, meta string, data map[string]interface{}) {
+	c.t.Helper()
+	msg := map[string]interface{}{"type": msgType, "meta": meta, "data": data}
+	if err := c.conn.WriteJSON(msg); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// receive skips messages of other types.
+func (c *testClient) receive(msgType string) map[string]interface{} {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, buf, err := c.conn.ReadMessage()
+		if err != nil {
+			c.t.Fatalf("waiting for %s: %s", msgType, err)
+		}
+		msg := make(map[string]interface{})
+		if err := json.Unmarshal(buf, &msg); err != nil {
+			c.t.Fatal(err)
+		}
+		if msg["type"] == msgType {
+			return msg
+		}
+	}
+}
+
+func (c *testClient) token() string {
+	c.t.Helper()
+	msg := c.receive("session")
+	return msg["data"].(map[string]interface{})["token"].(string)
+}
+
+func evaluationFeeds(app *App) []*feed {
+	feeds := make([]*feed, 0)
+	for _, f := range app.feeds.all() {
+		if _, ok := f.consumer.(*maneuvers.Session); ok {
+			feeds = append(feeds, f)
+		}
+	}
+	return feeds
+}
+
+// A client which resumes its session on a new connection gets the evaluations
+// of the maneuvers it started before and can stop them.
+func TestResumeEvaluateStop(t *testing.T) {
+	app := newTestApp(t, func(cfg *config.Config) {
+		cfg.Sessions.Enabled = true
+	})
+	url := serveTestApp(t, app)
+
+	first := dialTestApp(t, url)
+	token := first.token()
+	first.send("evaluate", "turns", map[string]interface{}{"maneuver": steepturns.Maneuver})
+	waitFor(t, "the evaluation to start", func() bool { return len(evaluationFeeds(app)) == 1 })
+	first.conn.Close()
+	waitFor(t, "the first connection to go", func() bool { return app.socket.ConnectionCount() == 0 })
+
+	second := dialTestApp(t, url)
+	second.token()
+	second.send("resume", "", map[string]interface{}{"token": token})
+	resumed := second.receive("session")
+	if !resumed["data"].(map[string]interface{})["resumed"].(bool) {
+		t.Fatal("session was not resumed")
+	}
+
+	f := evaluationFeeds(app)[0]
+	vars := f.consumer.Vars()
+	sample := func(bank float64) map[string]interface{} {
+		return map[string]interface{}{
+			vars[0].Moniker: 3000.0,
+			vars[1].Moniker: 100.0,
+			vars[2].Moniker: bank,
+			vars[3].Moniker: 90.0,
+		}
+	}
+	now := time.Now()
+	f.consume(now, sample(0))
+	f.consume(now.Add(time.Second), sample(45))
+	evaluation := second.receive("evaluation")
+	if evaluation["meta"] != "turns" {
+		t.Errorf("evaluation has meta %v, want turns", evaluation["meta"])
+	}
+
+	second.send("evaluate", "turns", map[string]interface{}{"maneuver": steepturns.Maneuver, "action": "stop"})
+	waitFor(t, "the evaluation to stop", func() bool {
+		return len(evaluationFeeds(app)) == 0 && app.requestManager.RequestCount() == 0
+	})
+}
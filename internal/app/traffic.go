@@ -0,0 +1,219 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"msfs2020-gopilot/internal/traffic"
+	"msfs2020-gopilot/internal/webserver"
+
+	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	trafficConnectionSuffix = " Traffic"
+	trafficDispatchInterval = 10 // milliseconds
+)
+
+// SimMate only keeps one value per SimVar, so traffic is requested on a
+// connection of its own with a single data definition. The fields must match
+// the order and types of trafficVars.
+type trafficData struct {
+	simconnect.RecvSimObjectDataByType
+	Latitude      float64
+	Longitude     float64
+	Altitude      float64
+	Heading       float64
+	GroundSpeed   float64
+	VerticalSpeed float64
+	OnGround      float64
+	IsUser        float64
+	ATCID         [64]byte
+	Title         [256]byte
+}
+
+var trafficVars = []struct {
+	name     string
+	unit     string
+	dataType simconnect.DWord
+}{
+	{"PLANE LATITUDE", "degrees", simconnect.DataTypeFloat64},
+	{"PLANE LONGITUDE", "degrees", simconnect.DataTypeFloat64},
+	{"PLANE ALTITUDE", "feet", simconnect.DataTypeFloat64},
+	{"PLANE HEADING DEGREES TRUE", "degrees", simconnect.DataTypeFloat64},
+	{"GROUND VELOCITY", "knot", simconnect.DataTypeFloat64},
+	{"VERTICAL SPEED", "ft/min", simconnect.DataTypeFloat64},
+	{"SIM ON GROUND", "bool", simconnect.DataTypeFloat64},
+	{"IS USER SIM", "bool", simconnect.DataTypeFloat64},
+	{"ATC ID", "", simconnect.DataTypeString64},
+	{"TITLE", "", simconnect.DataTypeString256},
+}
+
+var trafficCategories = map[simconnect.DWord]string{
+	simconnect.SimObjectTypeAircraft:   traffic.CategoryAircraft,
+	simconnect.SimObjectTypeHelicopter: traffic.CategoryHelicopter,
+}
+
+type trafficService struct {
+	table *traffic.Table
+	// Request IDs of the current and the previous round, mapped to the
+	// category, so that late replies are not lost.
+	requests     map[simconnect.DWord]string
+	lastRequests map[simconnect.DWord]string
+}
+
+func (app *App) initTraffic() {
+	expiry := time.Duration(app.cfg.Traffic.Expiry) * time.Second
+	app.traffic = &trafficService{
+		table:        traffic.NewTable(expiry),
+		requests:     make(map[simconnect.DWord]string),
+		lastRequests: make(map[simconnect.DWord]string),
+	}
+}
+
+// runTraffic opens the traffic connection and polls the objects around the
+// user aircraft until ctx is cancelled.
+func (app *App) runTraffic(ctx context.Context) {
+	conn := simconnect.NewSimConnect()
+	if err := conn.Open(app.cfg.ConnectionName + trafficConnectionSuffix); err != nil {
+		log.Error("Traffic will not be available: ", err)
+		return
+	}
+	defer conn.Close()
+
+	defineID := simconnect.NewDefineID()
+	for _, v := range trafficVars {
+		if err := conn.AddToDataDefinition(defineID, v.name, v.unit, v.dataType); err != nil {
+			log.Error("Traffic will not be available: ", err)
+			return
+		}
+	}
+	log.Infof("Requesting traffic within %d meters", app.cfg.Traffic.Radius)
+
+	requestTicker := time.NewTicker(time.Duration(app.cfg.Traffic.Interval) * time.Millisecond)
+	defer requestTicker.Stop()
+	dispatchTicker := time.NewTicker(trafficDispatchInterval * time.Millisecond)
+	defer dispatchTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-requestTicker.C:
+			app.traffic.lastRequests = app.traffic.requests
+			app.traffic.requests = make(map[simconnect.DWord]string)
+			for objectType, category := range trafficCategories {
+				requestID := simconnect.NewRequestID()
+				app.traffic.requests[requestID] = category
+				conn.RequestDataOnSimObjectType(requestID, defineID, simconnect.DWord(app.cfg.Traffic.Radius), objectType)
+			}
+			app.broadcastTraffic(app.traffic.table.Expire(time.Now()))
+
+		case <-dispatchTicker.C:
+			if !app.dispatchTraffic(conn, defineID) {
+				app.traffic.table.Clear()
+				return
+			}
+		}
+	}
+}
+
+// dispatchTraffic handles all pending messages. It returns false once the
+// connection is gone.
+func (app *App) dispatchTraffic(conn *simconnect.SimConnect, defineID simconnect.DWord) bool {
+	for {
+		ppData, r1, err := conn.GetNextDispatch()
+		if r1 < 0 {
+			if uint32(r1) != simconnect.EFail {
+				log.Error("Traffic connection lost: ", err)
+				return false
+			}
+			return true
+		}
+		if ppData == nil {
+			return true
+		}
+
+		recv := *(*simconnect.Recv)(ppData)
+		switch recv.ID {
+		case simconnect.RecvIDQuit:
+			return false
+
+		case simconnect.RecvIDException:
+			log.Debug("Traffic exception: ", (*simconnect.RecvException)(ppData).Exception)
+
+		case simconnect.RecvIDSimObjectDataByType:
+			data := (*trafficData)(ppData)
+			category, ok := app.traffic.requests[data.RequestID]
+			if !ok {
+				category, ok = app.traffic.lastRequests[data.RequestID]
+			}
+			if !ok || data.DefineID != defineID || data.IsUser != 0 {
+				continue
+			}
+			app.traffic.table.Update(traffic.Target{
+				ID:            uint32(data.ObjectID),
+				Category:      category,
+				ATCID:         cString(data.ATCID[:]),
+				Title:         cString(data.Title[:]),
+				Latitude:      data.Latitude,
+				Longitude:     data.Longitude,
+				Altitude:      data.Altitude,
+				Heading:       data.Heading,
+				GroundSpeed:   data.GroundSpeed,
+				VerticalSpeed: data.VerticalSpeed,
+				OnGround:      data.OnGround != 0,
+				LastSeen:      time.Now(),
+			})
+		}
+	}
+}
+
+func (app *App) broadcastTraffic(removed []uint32) {
+	if app.socket.ConnectionCount() == 0 {
+		return
+	}
+	msg := map[string]interface{}{
+		"type": "traffic",
+		"data": map[string]interface{}{
+			"targets": app.traffic.table.Targets(),
+			"removed": removed,
+		},
+	}
+	if buf, err := json.Marshal(msg); err == nil {
+		app.broadcast("traffic", buf)
+	} else {
+		app.metrics.messagesDropped.Inc(dropReasonEncodingFailed)
+	}
+}
+
+func (app *App) trafficRoutes(headers map[string]string) []webserver.Route {
+	if app.traffic == nil {
+		return nil
+	}
+	return []webserver.Route{
+		{Pattern: "/api/traffic", Handler: app.trafficHandler(headers)},
+	}
+}
+
+func (app *App) trafficHandler(headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, headers, http.StatusOK, app.traffic.table.Targets())
+	}
+}
+
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
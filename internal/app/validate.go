@@ -0,0 +1,53 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"msfs2020-gopilot/internal/config"
+	"msfs2020-gopilot/internal/mqttbridge"
+	"msfs2020-gopilot/internal/presence"
+	"msfs2020-gopilot/internal/webhooks"
+)
+
+// The config package does not know the features, so the settings only they
+// can check are validated here.
+func init() {
+	config.RegisterValidator(validateFeatures)
+}
+
+func validateFeatures(cfg *config.Config) []*config.FieldError {
+	errs := make([]*config.FieldError, 0)
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, &config.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	if cfg.MQTT.Enabled {
+		for _, simVar := range cfg.MQTT.SimVars {
+			if _, err := mqttbridge.ParseVar(simVar); err != nil {
+				fail("mqtt.simvars", "%s", err)
+			}
+		}
+	}
+	if cfg.Webhooks.Enabled {
+		for _, event := range cfg.Webhooks.Events {
+			if !knownWebhookEvent(event) {
+				fail("webhooks.events", "unknown event %q (expected one of %s)", event, strings.Join(webhooks.Events, ", "))
+			}
+		}
+	}
+	if cfg.Presence.Enabled && cfg.Presence.Template != "" && cfg.Presence.TemplateFile == "" {
+		if _, err := presence.NewComposer(cfg.Presence.Template); err != nil {
+			fail("presence.template", "%s", err)
+		}
+	}
+	return errs
+}
+
+func knownWebhookEvent(event string) bool {
+	for _, e := range webhooks.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
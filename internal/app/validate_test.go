@@ -0,0 +1,34 @@
+package app
+
+import (
+	"testing"
+
+	"msfs2020-gopilot/internal/config"
+)
+
+func TestValidateFeatures(t *testing.T) {
+	cfg, err := config.Load(config.LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.MQTT.Enabled = true
+	cfg.MQTT.SimVars = []string{"PLANE ALTITUDE:feet", ":feet"}
+	cfg.Webhooks.Enabled = true
+	cfg.Webhooks.URLs = []string{"http://localhost/hook"}
+	cfg.Webhooks.Events = []string{"landing", "explosion"}
+	cfg.Presence.Enabled = true
+	cfg.Presence.Template = "{{.Aircraft"
+
+	err = cfg.Validate()
+	errs, ok := err.(config.ValidationError)
+	if !ok {
+		t.Fatalf("Validate = %v, want a ValidationError", err)
+	}
+	fields := make(map[string]int)
+	for _, e := range errs {
+		fields[e.Field]++
+	}
+	if len(errs) != 3 || fields["mqtt.simvars"] != 1 || fields["webhooks.events"] != 1 || fields["presence.template"] != 1 {
+		t.Errorf("Validate = %v, want one error each for mqtt.simvars, webhooks.events and presence.template", errs)
+	}
+}
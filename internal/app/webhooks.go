@@ -0,0 +1,64 @@
+package app
+
+import (
+	"time"
+
+	"msfs2020-gopilot/internal/webhooks"
+	"msfs2020-gopilot/internal/websockets"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	webhooksDataDir = "webhooks"
+)
+
+type webhookClient struct {
+	ID             string    `json:"id"`
+	RemoteAddress  string    `json:"remoteAddress"`
+	ConnectedSince time.Time `json:"connectedSince"`
+}
+
+func (app *App) initWebhooks() {
+	cfg := app.cfg.Webhooks
+	dir, err := app.cfg.DataPath(webhooksDataDir)
+	if err != nil {
+		log.Error("Webhooks will not be available: ", err)
+		return
+	}
+	dispatcher, err := webhooks.NewDispatcher(dir, webhooks.Options{
+		URLs:        cfg.URLs,
+		Secret:      cfg.Secret,
+		Events:      cfg.Events,
+		MaxAttempts: int(cfg.MaxAttempts),
+		Timeout:     time.Duration(cfg.Timeout) * time.Second,
+	})
+	if err != nil {
+		log.Error("Webhooks will not be available: ", err)
+		return
+	}
+	if pending := dispatcher.Pending(); pending > 0 {
+		log.Infof("Resuming %d queued webhook deliveries", pending)
+	}
+	app.webhooks = dispatcher
+	// Takeoffs and landings come from the phase detection.
+	app.flightTracker()
+}
+
+// notify queues a webhook event if webhooks are enabled.
+func (app *App) notify(eventType string, data interface{}) {
+	if app.webhooks == nil {
+		return
+	}
+	if err := app.webhooks.Send(eventType, data); err != nil {
+		log.Errorf("Unable to queue webhook %s: %s", eventType, err)
+	}
+}
+
+func clientInfo(conn *websockets.Connection) *webhookClient {
+	return &webhookClient{
+		ID:             conn.UUID(),
+		RemoteAddress:  conn.RemoteAddr(),
+		ConnectedSince: conn.ConnectedSince(),
+	}
+}
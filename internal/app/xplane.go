@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"msfs2020-gopilot/internal/ownship"
+	"msfs2020-gopilot/internal/xplane"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type xplaneService struct {
+	conn    net.Conn
+	tracker *ownship.Tracker
+}
+
+func (app *App) initXPlane() {
+	conn, err := net.Dial("udp", app.cfg.XPlane.Address)
+	if err != nil {
+		log.Warn("X-Plane output will not be available: ", err)
+		return
+	}
+	app.xplane = &xplaneService{
+		conn:    conn,
+		tracker: app.ownshipTracker(),
+	}
+	log.Infof("Sending X-Plane data to %s", app.cfg.XPlane.Address)
+}
+
+// runXPlane sends XGPS and XATT, each as a datagram of its own, at the
+// configured rate.
+func (app *App) runXPlane(ctx context.Context) {
+	defer app.xplane.conn.Close()
+	ticker := time.NewTicker(time.Second / time.Duration(app.cfg.XPlane.Rate))
+	defer ticker.Stop()
+
+	sim := app.cfg.XPlane.SimName
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			state, ok := app.xplane.tracker.State()
+			if !ok {
+				continue
+			}
+			s := &xplane.State{
+				Latitude:    state.Latitude,
+				Longitude:   state.Longitude,
+				Altitude:    state.Altitude,
+				Track:       state.Track,
+				GroundSpeed: state.GroundSpeed,
+				Heading:     state.Heading,
+				Pitch:       state.Pitch,
+				Bank:        state.Bank,
+			}
+			for _, msg := range [][]byte{xplane.XGPS(sim, s), xplane.XATT(sim, s)} {
+				if _, err := app.xplane.conn.Write(msg); err != nil {
+					log.Debug("Unable to send X-Plane data: ", err)
+				}
+			}
+		}
+	}
+}
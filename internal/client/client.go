@@ -0,0 +1,205 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Client speaks GoPilot's WebSocket protocol (/ws). Each frame sent by the
+// server carries one or more newline-separated JSON messages.
+
+type Message struct {
+	Type string          `json:"type"`
+	Meta string          `json:"meta"`
+	Data json.RawMessage `json:"data"`
+}
+
+type outgoingMessage struct {
+	Type  string      `json:"type"`
+	Meta  string      `json:"meta"`
+	Data  interface{} `json:"data"`
+	Debug string      `json:"debug"`
+}
+
+// Result is the data of the result message the server replies to messages
+// without another reply, e.g. setdata and teleport.
+type Result struct {
+	Request string `json:"request"`
+	OK      bool   `json:"ok"`
+	Error   string `json:"error"`
+}
+
+// Err returns the error the server reported.
+func (r *Result) Err() error {
+	if r.OK {
+		return nil
+	}
+	return errors.New(r.Error)
+}
+
+type SimVar struct {
+	Name    string `json:"name"`
+	Unit    string `json:"unit"`
+	Type    string `json:"type"`
+	Moniker string `json:"moniker"`
+}
+
+type Client struct {
+	conn     *websocket.Conn
+	messages chan *Message
+	err      error
+	mutex    sync.Mutex
+}
+
+const (
+	wsPath       = "/ws"
+	writeTimeout = 10 * time.Second
+	closeTimeout = time.Second
+)
+
+func Dial(address string, timeout time.Duration) (*Client, error) {
+	u := url.URL{Scheme: "ws", Host: address, Path: wsPath}
+	dialer := websocket.Dialer{HandshakeTimeout: timeout}
+	conn, _, err := dialer.Dial(u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to %s: %s", u.String(), err.Error())
+	}
+	client := &Client{
+		conn:     conn,
+		messages: make(chan *Message, 256),
+	}
+	go client.receiver()
+	return client, nil
+}
+
+// Messages returns the channel of received messages. It is closed when the
+// connection is lost; Err tells why.
+func (client *Client) Messages() <-chan *Message {
+	return client.messages
+}
+
+func (client *Client) Err() error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	return client.err
+}
+
+func (client *Client) Send(msgType string, data interface{}, meta string) error {
+	buf, err := json.Marshal(&outgoingMessage{Type: msgType, Meta: meta, Data: data})
+	if err != nil {
+		return err
+	}
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	client.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return client.conn.WriteMessage(websocket.TextMessage, buf)
+}
+
+func (client *Client) Register(vars []SimVar, meta string) error {
+	return client.Send("register", vars, meta)
+}
+
+// WaitFor returns the first message of the given type received within timeout.
+// Messages of other types are discarded.
+func (client *Client) WaitFor(msgType string, timeout time.Duration) (*Message, error) {
+	return client.WaitForAny(timeout, msgType)
+}
+
+// WaitForAny returns the first message of one of the given types received
+// within timeout.
+func (client *Client) WaitForAny(timeout time.Duration, msgTypes ...string) (*Message, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case msg, ok := <-client.messages:
+			if !ok {
+				if err := client.Err(); err != nil {
+					return nil, err
+				}
+				return nil, fmt.Errorf("connection closed")
+			}
+			for _, msgType := range msgTypes {
+				if msg.Type == msgType {
+					return msg, nil
+				}
+			}
+		case <-timer.C:
+			return nil, fmt.Errorf("no %s message received within %s", strings.Join(msgTypes, " or "), timeout)
+		}
+	}
+}
+
+// Call sends a message which the server answers with a result message and
+// returns the error the server reported.
+func (client *Client) Call(msgType string, data interface{}, meta string, timeout time.Duration) error {
+	if err := client.Send(msgType, data, meta); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		msg, err := client.WaitFor("result", time.Until(deadline))
+		if err != nil {
+			return err
+		}
+		result, err := ParseResult(msg)
+		if err != nil {
+			return err
+		}
+		if result.Request == msgType && msg.Meta == meta {
+			return result.Err()
+		}
+	}
+}
+
+// ParseResult parses the data of a result message.
+func ParseResult(msg *Message) (*Result, error) {
+	result := &Result{}
+	if err := json.Unmarshal(msg.Data, result); err != nil {
+		return nil, fmt.Errorf("malformed result: %s", err)
+	}
+	return result, nil
+}
+
+// Close sends a close frame and closes the connection.
+func (client *Client) Close() error {
+	client.mutex.Lock()
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	client.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(closeTimeout))
+	client.mutex.Unlock()
+	return client.conn.Close()
+}
+
+func (client *Client) receiver() {
+	defer close(client.messages)
+	for {
+		_, data, err := client.conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				client.mutex.Lock()
+				client.err = err
+				client.mutex.Unlock()
+			}
+			return
+		}
+		for _, line := range bytes.Split(data, []byte{'\n'}) {
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				continue
+			}
+			msg := &Message{}
+			if err := json.Unmarshal(line, msg); err != nil {
+				continue
+			}
+			client.messages <- msg
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// startServer serves a WebSocket which answers each message with the frames
+// reply returns. A frame may hold several newline-separated messages.
+func startServer(t *testing.T, reply func(msg *Message) []string) string {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			msg := &Message{}
+			if err := json.Unmarshal(data, msg); err != nil {
+				t.Errorf("malformed message %s", data)
+				return
+			}
+			for _, frame := range reply(msg) {
+				conn.WriteMessage(websocket.TextMessage, []byte(frame))
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func dial(t *testing.T, address string) *Client {
+	t.Helper()
+	c, err := Dial(address, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestCall(t *testing.T) {
+	address := startServer(t, func(msg *Message) []string {
+		switch msg.Type {
+		case "setdata":
+			// Results of other requests and other messages come first.
+			return []string{
+				`{"type": "simvars", "meta": "hud", "data": {}}` + "\n" + `{"type": "result", "meta": "other", "data": {"request": "setdata", "ok": false, "error": "not for us"}}`,
+				`{"type": "result", "meta": "` + msg.Meta + `", "data": {"request": "setdata", "ok": true}}`,
+			}
+		case "teleport":
+			return []string{`{"type": "result", "meta": "` + msg.Meta + `", "data": {"request": "teleport", "ok": false, "error": "not connected to SimConnect"}}`}
+		case "garbage":
+			return []string{`{"type": "result", "meta": "` + msg.Meta + `", "data": []}`}
+		}
+		return nil
+	})
+	c := dial(t, address)
+
+	if err := c.Call("setdata", map[string]interface{}{"name": "PLANE ALTITUDE"}, "cli", time.Second); err != nil {
+		t.Errorf("setdata: %s", err)
+	}
+	if err := c.Call("teleport", nil, "cli", time.Second); err == nil || err.Error() != "not connected to SimConnect" {
+		t.Errorf("teleport: error %v, want the reported error", err)
+	}
+	if err := c.Call("garbage", nil, "cli", time.Second); err == nil || !strings.Contains(err.Error(), "malformed result") {
+		t.Errorf("garbage: error %v, want a malformed result", err)
+	}
+	// No reply.
+	if err := c.Call("ping", nil, "cli", 50*time.Millisecond); err == nil || !strings.Contains(err.Error(), "no result message received") {
+		t.Errorf("ping: error %v, want a timeout", err)
+	}
+}
+
+func TestWaitForAny(t *testing.T) {
+	address := startServer(t, func(msg *Message) []string {
+		return []string{`{"type": "pong"}`, `{"type": "result", "data": {"request": "airports", "ok": false, "error": "airports database not available"}}`}
+	})
+	c := dial(t, address)
+
+	if err := c.Send("airports", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := c.WaitForAny(time.Second, "airports", "result")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ParseResult(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := result.Err(); err == nil || result.Request != "airports" {
+		t.Errorf("result %+v", result)
+	}
+
+	_, err = c.WaitForAny(50*time.Millisecond, "airports", "status")
+	if err == nil || err.Error() != "no airports or status message received within 50ms" {
+		t.Errorf("error %v, want a timeout", err)
+	}
+}
@@ -1,29 +1,274 @@
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"msfs2020-gopilot/internal/util"
+
 	"github.com/ilyakaznacheev/cleanenv"
 	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
 )
 
 type Config struct {
-	ConnectionName      string `yaml:"connection_name" env:"CONNECTION_NAME" env-default:"MyConnection"`
-	ConnectionTimeout   int64  `yaml:"connection_timeout" env:"CONNECTION_TIMEOUT" env-default:"600"`
-	SimConnectDLLPath   string `yaml:"simconnect_dll_path" env:"SIMCONNECT_DLL_PATH" env-default:"."`
-	ServerAddress       string `yaml:"server_address" env:"SERVER_ADDRESS" env-default:"0.0.0.0:8888"`
-	DataRequestInterval int64  `yaml:"data_request_interval" env:"DATA_REQUEST_INTERVAL" env-default:"200"`
-	LogLevel            string `yaml:"log_level" env:"LOG_LEVEL" env-default:"info"`
+	ConnectionName      string           `yaml:"connection_name" env:"CONNECTION_NAME" env-default:"GoPilot" env-description:"Name of the SimConnect client connection"`
+	ConnectionTimeout   int64            `yaml:"connection_timeout" env:"CONNECTION_TIMEOUT" env-default:"600" env-description:"Seconds to wait for the simulator before giving up"`
+	SimConnectDLLPath   string           `yaml:"simconnect_dll_path" env:"SIMCONNECT_DLL_PATH" env-default:"." env-description:"Additional search path for SimConnect.dll"`
+	ServerAddress       string           `yaml:"server_address" env:"SERVER_ADDRESS" env-default:"0.0.0.0:8888" env-description:"Address the web server listens on"`
+	DataRequestInterval int64            `yaml:"data_request_interval" env:"DATA_REQUEST_INTERVAL" env-default:"200" env-description:"Milliseconds between two SimVar data requests"`
+	LogLevel            string           `yaml:"log_level" env:"LOG_LEVEL" env-default:"info" env-description:"Log level (error, warn, info, debug, trace)"`
+	AssetsOverrideDir   string           `yaml:"assets_override_dir" env:"ASSETS_OVERRIDE_DIR" env-default:"" env-description:"Directory containing assets/ and data/ to use instead of the embedded files"`
+	DataDir             string           `yaml:"data_dir" env:"DATA_DIR" env-default:"" env-description:"Directory for persisted data such as maneuver reports (default: <user config dir>/gopilot)"`
+	AdminToken          string           `yaml:"admin_token" secret:"true" env:"ADMIN_TOKEN" env-default:"" env-description:"Bearer token which allows the debug endpoints that change state to be used from other hosts (optional)"`
+	SteepTurns          SteepTurnsConfig `yaml:"steep_turns"`
+	Maneuvers           ManeuversConfig  `yaml:"maneuvers"`
+	Logbook             LogbookConfig    `yaml:"logbook"`
+	Landings            LandingsConfig   `yaml:"landings"`
+	Alerts              AlertsConfig     `yaml:"alerts"`
+	Traffic             TrafficConfig    `yaml:"traffic"`
+	GDL90               GDL90Config      `yaml:"gdl90"`
+	NMEA                NMEAConfig       `yaml:"nmea"`
+	XPlane              XPlaneConfig     `yaml:"xplane"`
+	MQTT                MQTTConfig       `yaml:"mqtt"`
+	GRPC                GRPCConfig       `yaml:"grpc"`
+	Webhooks            WebhooksConfig   `yaml:"webhooks"`
+	Presence            PresenceConfig   `yaml:"presence"`
+	Overlays            OverlaysConfig   `yaml:"overlays"`
+	Sessions            SessionsConfig   `yaml:"sessions"`
+	WebSocket           WebSocketConfig  `yaml:"websocket"`
+	Daemon              DaemonConfig     `yaml:"daemon"`
 }
 
-func NewConfigFromFile(path string) (*Config, error) {
+type SteepTurnsConfig struct {
+	Enabled bool `yaml:"enabled" env:"STEEP_TURNS_ENABLED" env-default:"false" env-description:"Evaluate steep turns on the server and store the attempts"`
+}
+
+type LogbookConfig struct {
+	Enabled bool `yaml:"enabled" env:"LOGBOOK_ENABLED" env-default:"false" env-description:"Detect flight phases and write a logbook entry per flight"`
+}
+
+type LandingsConfig struct {
+	Enabled     bool `yaml:"enabled" env:"LANDINGS_ENABLED" env-default:"false" env-description:"Analyze touchdowns and send landing reports to all clients"`
+	HistorySize int  `yaml:"history_size" env:"LANDINGS_HISTORY_SIZE" env-default:"100" env-description:"Number of landing reports kept in memory"`
+}
+
+type AlertsConfig struct {
+	Enabled    bool   `yaml:"enabled" env:"ALERTS_ENABLED" env-default:"false" env-description:"Evaluate the alert rules and send alerts to all clients"`
+	RulesFile  string `yaml:"rules_file" env:"ALERTS_RULES_FILE" env-default:"configs/alerts.yml" env-description:"YAML file with the alert rules (reloaded on change)"`
+	WebhookURL string `yaml:"webhook_url" secret:"url" env:"ALERTS_WEBHOOK_URL" env-default:"" env-description:"URL every alert is POSTed to as JSON (optional)"`
+}
+
+type TrafficConfig struct {
+	Enabled  bool  `yaml:"enabled" env:"TRAFFIC_ENABLED" env-default:"false" env-description:"Track AI and multiplayer aircraft and send them to all clients"`
+	Radius   int64 `yaml:"radius" env:"TRAFFIC_RADIUS" env-default:"30000" env-description:"Meters around the user aircraft to request traffic for (max. 200000)"`
+	Interval int64 `yaml:"interval" env:"TRAFFIC_INTERVAL" env-default:"1000" env-description:"Milliseconds between two traffic requests"`
+	Expiry   int64 `yaml:"expiry" env:"TRAFFIC_EXPIRY" env-default:"10" env-description:"Seconds after which a target which was not updated is removed"`
+}
+
+type GDL90Config struct {
+	Enabled     bool   `yaml:"enabled" env:"GDL90_ENABLED" env-default:"false" env-description:"Send ownship and traffic as GDL90 over UDP (e.g. for EFB apps)"`
+	Address     string `yaml:"address" env:"GDL90_ADDRESS" env-default:"255.255.255.255:4000" env-description:"UDP address (host:port) the GDL90 messages are sent to"`
+	OwnshipRate int64  `yaml:"ownship_rate" env:"GDL90_OWNSHIP_RATE" env-default:"1" env-description:"Ownship reports per second (1-10)"`
+}
+
+type NMEAConfig struct {
+	Enabled    bool   `yaml:"enabled" env:"NMEA_ENABLED" env-default:"false" env-description:"Provide the aircraft position as NMEA 0183 GPS sentences"`
+	TCPAddress string `yaml:"tcp_address" env:"NMEA_TCP_ADDRESS" env-default:"0.0.0.0:10110" env-description:"Address (host:port) to serve NMEA on over TCP (empty: disabled)"`
+	UDPAddress string `yaml:"udp_address" env:"NMEA_UDP_ADDRESS" env-default:"" env-description:"UDP address (host:port) to send NMEA to (empty: disabled)"`
+	Device     string `yaml:"device" env:"NMEA_DEVICE" env-default:"" env-description:"File or (virtual) serial port to write NMEA to, e.g. COM5 (empty: disabled)"`
+	Rate       int64  `yaml:"rate" env:"NMEA_RATE" env-default:"1" env-description:"Updates per second (1-10)"`
+}
+
+type XPlaneConfig struct {
+	Enabled bool   `yaml:"enabled" env:"XPLANE_ENABLED" env-default:"false" env-description:"Send position and attitude as X-Plane XGPS/XATT over UDP"`
+	Address string `yaml:"address" env:"XPLANE_ADDRESS" env-default:"255.255.255.255:49002" env-description:"UDP address (host:port) the X-Plane messages are sent to"`
+	Rate    int64  `yaml:"rate" env:"XPLANE_RATE" env-default:"5" env-description:"Updates per second (1-20)"`
+	SimName string `yaml:"sim_name" env:"XPLANE_SIM_NAME" env-default:"MSFS" env-description:"Simulator name included in the messages"`
+}
+
+type MQTTConfig struct {
+	Enabled     bool     `yaml:"enabled" env:"MQTT_ENABLED" env-default:"false" env-description:"Publish SimVars to an MQTT broker and accept commands from it"`
+	Broker      string   `yaml:"broker" env:"MQTT_BROKER" env-default:"tcp://localhost:1883" env-description:"URL of the MQTT broker (tcp://, ssl://, ws:// or wss://)"`
+	ClientID    string   `yaml:"client_id" env:"MQTT_CLIENT_ID" env-default:"gopilot" env-description:"MQTT client ID"`
+	Username    string   `yaml:"username" env:"MQTT_USERNAME" env-default:"" env-description:"MQTT user name (optional)"`
+	Password    string   `yaml:"password" secret:"true" env:"MQTT_PASSWORD" env-default:"" env-description:"MQTT password (optional)"`
+	TopicPrefix string   `yaml:"topic_prefix" env:"MQTT_TOPIC_PREFIX" env-default:"gopilot" env-description:"Prefix of all topics, e.g. gopilot/sim/PLANE_ALTITUDE"`
+	QoS         int64    `yaml:"qos" env:"MQTT_QOS" env-default:"0" env-description:"Quality of service of published messages and subscriptions (0-2)"`
+	Retain      bool     `yaml:"retain" env:"MQTT_RETAIN" env-default:"false" env-description:"Publish SimVars as retained messages"`
+	Interval    int64    `yaml:"interval" env:"MQTT_INTERVAL" env-default:"500" env-description:"Milliseconds between two publishes of the changed SimVars"`
+	SimVars     []string `yaml:"simvars" env:"MQTT_SIMVARS" env-default:"" env-description:"SimVars to publish as NAME:unit (numbers) or NAME (strings), comma separated"`
+}
+
+type GRPCConfig struct {
+	Enabled bool   `yaml:"enabled" env:"GRPC_ENABLED" env-default:"false" env-description:"Serve the gRPC API (see api/gopilot.proto)"`
+	Address string `yaml:"address" env:"GRPC_ADDRESS" env-default:"0.0.0.0:8889" env-description:"Address the gRPC server listens on"`
+}
+
+type WebhooksConfig struct {
+	Enabled     bool     `yaml:"enabled" env:"WEBHOOKS_ENABLED" env-default:"false" env-description:"POST connect, client, takeoff, landing and teleport events to webhook URLs"`
+	URLs        []string `yaml:"urls" secret:"url" env:"WEBHOOKS_URLS" env-default:"" env-description:"URLs the events are POSTed to, comma separated"`
+	Secret      string   `yaml:"secret" secret:"true" env:"WEBHOOKS_SECRET" env-default:"" env-description:"Secret the X-GoPilot-Signature header (HMAC-SHA256) is computed with (optional)"`
+	Events      []string `yaml:"events" env:"WEBHOOKS_EVENTS" env-default:"" env-description:"Events to send, comma separated (default: all)"`
+	MaxAttempts int64    `yaml:"max_attempts" env:"WEBHOOKS_MAX_ATTEMPTS" env-default:"10" env-description:"Delivery attempts before an event is dropped"`
+	Timeout     int64    `yaml:"timeout" env:"WEBHOOKS_TIMEOUT" env-default:"10" env-description:"Seconds to wait for a webhook to respond"`
+}
+
+type PresenceConfig struct {
+	Enabled      bool   `yaml:"enabled" env:"PRESENCE_ENABLED" env-default:"false" env-description:"Compose a status text from the flight state and publish it"`
+	Template     string `yaml:"template" env:"PRESENCE_TEMPLATE" env-default:"" env-description:"Go template of the status text (default: built-in template)"`
+	TemplateFile string `yaml:"template_file" env:"PRESENCE_TEMPLATE_FILE" env-default:"" env-description:"File the status template is read from (overrides template)"`
+	Interval     int64  `yaml:"interval" env:"PRESENCE_INTERVAL" env-default:"5" env-description:"Seconds between status updates"`
+	File         string `yaml:"file" env:"PRESENCE_FILE" env-default:"" env-description:"File every status change is appended to (optional)"`
+	OBSFile      string `yaml:"obs_file" env:"PRESENCE_OBS_FILE" env-default:"" env-description:"File holding only the current status, e.g. for an OBS text source (optional)"`
+	HTTPURL      string `yaml:"http_url" secret:"url" env:"PRESENCE_HTTP_URL" env-default:"" env-description:"URL every status change is POSTed to (optional)"`
+}
+
+type OverlaysConfig struct {
+	Dir string `yaml:"dir" env:"OVERLAYS_DIR" env-default:"configs/overlays" env-description:"Directory with the overlay templates served at /overlay/{name} (<name>.html)"`
+}
+
+type SessionsConfig struct {
+	Enabled     bool  `yaml:"enabled" env:"SESSIONS_ENABLED" env-default:"false" env-description:"Let WebSocket clients resume their session after a reconnect"`
+	GracePeriod int64 `yaml:"grace_period" env:"SESSIONS_GRACE_PERIOD" env-default:"60" env-description:"Seconds the subscriptions of a disconnected client are kept"`
+	MaxBuffered int64 `yaml:"max_buffered" env:"SESSIONS_MAX_BUFFERED" env-default:"100" env-description:"Alert, landing and evaluation messages kept for a disconnected client"`
+}
+
+type DaemonConfig struct {
+	PIDFile       string `yaml:"pid_file" env:"DAEMON_PID_FILE" env-default:"gopilot.pid" env-description:"File holding the process ID in daemon mode; prevents a second instance from starting"`
+	LogFile       string `yaml:"log_file" env:"DAEMON_LOG_FILE" env-default:"logs/gopilot.log" env-description:"File the JSON log is written to in daemon mode (empty: standard output)"`
+	LogMaxSize    int64  `yaml:"log_max_size" env:"DAEMON_LOG_MAX_SIZE" env-default:"10" env-description:"Megabytes after which the log file is rotated"`
+	LogMaxBackups int64  `yaml:"log_max_backups" env:"DAEMON_LOG_MAX_BACKUPS" env-default:"5" env-description:"Number of rotated log files kept"`
+}
+
+type WebSocketConfig struct {
+	QueueSize         int64 `yaml:"queue_size" env:"WEBSOCKET_QUEUE_SIZE" env-default:"256" env-description:"Messages queued per client before SimVar, status and traffic updates are dropped"`
+	SlowClientTimeout int64 `yaml:"slow_client_timeout" env:"WEBSOCKET_SLOW_CLIENT_TIMEOUT" env-default:"10" env-description:"Seconds a client may stay behind before it is disconnected"`
+}
+
+type ManeuversConfig struct {
+	Dir string `yaml:"dir" env:"MANEUVERS_DIR" env-default:"configs/maneuvers" env-description:"Directory with tolerance files for the maneuver evaluators (<maneuver>.yml)"`
+}
+
+// LoadOptions describe where a configuration is read from. The layers are
+// applied in the following order, later layers overriding earlier ones:
+// defaults, config file, environment variables, command-line flags.
+type LoadOptions struct {
+	// Path of the YAML config file. Optional.
+	Path string
+	// Required makes a missing config file an error rather than skipping the file layer.
+	Required bool
+	// Flags registered with RegisterFlags. Only flags which were set explicitly are applied.
+	Flags *flag.FlagSet
+}
+
+func Load(opts LoadOptions) (*Config, error) {
 	cfg := &Config{}
-	if err := cleanenv.ReadConfig(path, cfg); err == nil {
-		return cfg, nil
-	} else {
-		log.Errorf("Config error: %s", err.Error())
-	}
-	log.Info("Creating config from environment variables")
-	if err := cleanenv.ReadEnv(cfg); err != nil {
+	if opts.Path != "" {
+		if _, err := os.Stat(opts.Path); err == nil {
+			if err := cleanenv.ReadConfig(opts.Path, cfg); err != nil {
+				return nil, fmt.Errorf("%s: %s", opts.Path, err.Error())
+			}
+		} else if opts.Required || !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		} else {
+			log.Infof("Config file {%s} not found. Using defaults and environment variables", opts.Path)
+			if err := cleanenv.ReadEnv(cfg); err != nil {
+				return nil, err
+			}
+		}
+	} else if err := cleanenv.ReadEnv(cfg); err != nil {
 		return nil, err
 	}
+	if opts.Flags != nil {
+		if err := applyFlags(cfg, opts.Flags); err != nil {
+			return nil, err
+		}
+	}
 	return cfg, nil
 }
+
+func NewConfigFromFile(path string) (*Config, error) {
+	return Load(LoadOptions{Path: path, Required: true})
+}
+
+func (cfg *Config) YAML() (string, error) {
+	buf, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// Redacted returns a copy of the configuration which can be logged: the values
+// of the fields tagged secret:"true" are masked, and URLs tagged secret:"url"
+// only show scheme and host.
+func (cfg *Config) Redacted() *Config {
+	redacted := *cfg
+	walkFields(reflect.ValueOf(&redacted).Elem(), "", func(name string, field reflect.StructField, value reflect.Value) {
+		switch field.Tag.Get("secret") {
+		case "true":
+			if value.Kind() == reflect.String && value.String() != "" {
+				value.SetString(util.Redacted)
+			}
+		case "url":
+			switch v := value.Interface().(type) {
+			case string:
+				value.SetString(util.RedactURL(v))
+			case []string:
+				if len(v) == 0 {
+					break
+				}
+				// The copy shares the slice with the original.
+				urls := make([]string, len(v))
+				for i, u := range v {
+					urls[i] = util.RedactURL(u)
+				}
+				value.Set(reflect.ValueOf(urls))
+			}
+		}
+	})
+	return &redacted
+}
+
+// DataPath returns the directory for persisted data of the given kind, e.g.
+// "steepturns".
+func (cfg *Config) DataPath(kind string) (string, error) {
+	dir := cfg.DataDir
+	if dir == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(configDir, "gopilot")
+	}
+	return filepath.Join(dir, kind), nil
+}
+
+func (cfg *Config) Level() log.Level {
+	level, err := ParseLogLevel(cfg.LogLevel)
+	if err != nil {
+		return log.InfoLevel
+	}
+	return level
+}
+
+func ParseLogLevel(level string) (log.Level, error) {
+	switch level {
+	case "error":
+		return log.ErrorLevel, nil
+	case "warn":
+		return log.WarnLevel, nil
+	case "info":
+		return log.InfoLevel, nil
+	case "debug":
+		return log.DebugLevel, nil
+	case "trace":
+		return log.TraceLevel, nil
+	}
+	return log.InfoLevel, fmt.Errorf("unknown log level %q", level)
+}
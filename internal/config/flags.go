@@ -0,0 +1,114 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Every config field can be overridden on the command line. The flag name is
+// derived from the YAML key, e.g. data_request_interval becomes
+// --data-request-interval. Fields of nested sections are prefixed with the
+// section's key, e.g. mqtt.broker becomes --mqtt-broker.
+
+func RegisterFlags(fs *flag.FlagSet) {
+	walkFields(reflect.ValueOf(&Config{}).Elem(), "", func(name string, field reflect.StructField, value reflect.Value) {
+		usage := field.Tag.Get("env-description")
+		if def, ok := field.Tag.Lookup("env-default"); ok && def != "" {
+			usage = fmt.Sprintf("%s (default %s)", usage, def)
+		}
+		fs.String(name, "", usage)
+	})
+}
+
+func applyFlags(cfg *Config, fs *flag.FlagSet) error {
+	set := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = f.Value.String()
+	})
+	var err error
+	walkFields(reflect.ValueOf(cfg).Elem(), "", func(name string, field reflect.StructField, value reflect.Value) {
+		raw, ok := set[name]
+		if !ok || err != nil {
+			return
+		}
+		if e := setValue(value, raw); e != nil {
+			err = fmt.Errorf("--%s: %s", name, e.Error())
+		}
+	})
+	return err
+}
+
+func walkFields(v reflect.Value, prefix string, fn func(name string, field reflect.StructField, value reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		name := prefix + strings.ReplaceAll(key, "_", "-")
+		value := v.Field(i)
+		if value.Kind() == reflect.Struct && value.Type() != reflect.TypeOf(time.Time{}) {
+			walkFields(value, name+"-", fn)
+			continue
+		}
+		fn(name, field, value)
+	}
+}
+
+func setValue(value reflect.Value, raw string) error {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", value.Type())
+		}
+		items := make([]string, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
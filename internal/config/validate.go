@@ -0,0 +1,219 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var mqttSchemes = map[string]bool{"tcp": true, "ssl": true, "tls": true, "mqtt": true, "mqtts": true, "ws": true, "wss": true}
+
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+type ValidationError []*FieldError
+
+func (errs ValidationError) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+// Validator checks settings which only a feature package can check, e.g. the
+// syntax of presence templates. Validators are registered by the packages
+// which wire up the features, so that config does not depend on them.
+type Validator func(cfg *Config) []*FieldError
+
+var validators []Validator
+
+// RegisterValidator adds a validator to Validate. It must be called before the
+// configuration is loaded, e.g. in an init function.
+func RegisterValidator(validator Validator) {
+	validators = append(validators, validator)
+}
+
+type validator struct {
+	errs ValidationError
+}
+
+func (v *validator) fail(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, &FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) address(field, address string) {
+	if _, err := ParsePort(address); err != nil {
+		v.fail(field, "%s", err.Error())
+	}
+}
+
+func (v *validator) httpURL(field, rawURL string) {
+	if u, err := url.Parse(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.fail(field, "%q is not an http(s) URL", rawURL)
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (cfg *Config) Validate() error {
+	v := &validator{}
+	if strings.TrimSpace(cfg.ConnectionName) == "" {
+		v.fail("connection_name", "must not be empty")
+	}
+	if cfg.ConnectionTimeout <= 0 {
+		v.fail("connection_timeout", "must be greater than 0 (got %d)", cfg.ConnectionTimeout)
+	}
+	v.address("server_address", cfg.ServerAddress)
+	if cfg.DataRequestInterval < 10 {
+		v.fail("data_request_interval", "must be at least 10 milliseconds (got %d)", cfg.DataRequestInterval)
+	}
+	if _, err := ParseLogLevel(cfg.LogLevel); err != nil {
+		v.fail("log_level", "%s, expected one of error, warn, info, debug, trace", err.Error())
+	}
+	if cfg.AssetsOverrideDir != "" {
+		if info, err := os.Stat(cfg.AssetsOverrideDir); err != nil || !info.IsDir() {
+			v.fail("assets_override_dir", "%q is not a directory", cfg.AssetsOverrideDir)
+		}
+	}
+	if cfg.Landings.HistorySize < 1 {
+		v.fail("landings.history_size", "must be at least 1 (got %d)", cfg.Landings.HistorySize)
+	}
+	if cfg.Traffic.Radius < 1 || cfg.Traffic.Radius > 200000 {
+		v.fail("traffic.radius", "must be between 1 and 200000 meters (got %d)", cfg.Traffic.Radius)
+	}
+	if cfg.Traffic.Interval < 100 {
+		v.fail("traffic.interval", "must be at least 100 milliseconds (got %d)", cfg.Traffic.Interval)
+	}
+	if cfg.Traffic.Expiry < 1 {
+		v.fail("traffic.expiry", "must be at least 1 second (got %d)", cfg.Traffic.Expiry)
+	}
+	if cfg.GDL90.Enabled {
+		v.address("gdl90.address", cfg.GDL90.Address)
+	}
+	if cfg.GDL90.OwnshipRate < 1 || cfg.GDL90.OwnshipRate > 10 {
+		v.fail("gdl90.ownship_rate", "must be between 1 and 10 (got %d)", cfg.GDL90.OwnshipRate)
+	}
+	if cfg.NMEA.Enabled {
+		if cfg.NMEA.TCPAddress == "" && cfg.NMEA.UDPAddress == "" && cfg.NMEA.Device == "" {
+			v.fail("nmea", "needs at least one of tcp_address, udp_address, device")
+		}
+		if cfg.NMEA.TCPAddress != "" {
+			v.address("nmea.tcp_address", cfg.NMEA.TCPAddress)
+		}
+		if cfg.NMEA.UDPAddress != "" {
+			v.address("nmea.udp_address", cfg.NMEA.UDPAddress)
+		}
+	}
+	if cfg.NMEA.Rate < 1 || cfg.NMEA.Rate > 10 {
+		v.fail("nmea.rate", "must be between 1 and 10 (got %d)", cfg.NMEA.Rate)
+	}
+	if cfg.XPlane.Enabled {
+		v.address("xplane.address", cfg.XPlane.Address)
+	}
+	if cfg.XPlane.Rate < 1 || cfg.XPlane.Rate > 20 {
+		v.fail("xplane.rate", "must be between 1 and 20 (got %d)", cfg.XPlane.Rate)
+	}
+	if strings.Contains(cfg.XPlane.SimName, ",") {
+		v.fail("xplane.sim_name", "must not contain commas")
+	}
+	if cfg.MQTT.Enabled {
+		if u, err := url.Parse(cfg.MQTT.Broker); err != nil || u.Host == "" {
+			v.fail("mqtt.broker", "must be a URL such as tcp://localhost:1883")
+		} else if !mqttSchemes[u.Scheme] {
+			v.fail("mqtt.broker", "unsupported scheme %q", u.Scheme)
+		}
+		if cfg.MQTT.ClientID == "" {
+			v.fail("mqtt.client_id", "must not be empty")
+		}
+	}
+	if cfg.MQTT.QoS < 0 || cfg.MQTT.QoS > 2 {
+		v.fail("mqtt.qos", "must be 0, 1 or 2 (got %d)", cfg.MQTT.QoS)
+	}
+	if cfg.MQTT.Interval < 100 {
+		v.fail("mqtt.interval", "must be at least 100 (got %d)", cfg.MQTT.Interval)
+	}
+	if cfg.GRPC.Enabled {
+		v.address("grpc.address", cfg.GRPC.Address)
+		if cfg.GRPC.Address == cfg.ServerAddress {
+			v.fail("grpc.address", "must differ from server_address")
+		}
+	}
+	if cfg.Webhooks.Enabled {
+		if len(cfg.Webhooks.URLs) == 0 {
+			v.fail("webhooks.urls", "needs at least one URL")
+		}
+		for _, u := range cfg.Webhooks.URLs {
+			v.httpURL("webhooks.urls", u)
+		}
+	}
+	if cfg.Webhooks.MaxAttempts < 1 {
+		v.fail("webhooks.max_attempts", "must be at least 1 (got %d)", cfg.Webhooks.MaxAttempts)
+	}
+	if cfg.Webhooks.Timeout < 1 {
+		v.fail("webhooks.timeout", "must be at least 1 (got %d)", cfg.Webhooks.Timeout)
+	}
+	if cfg.Presence.Enabled {
+		if cfg.Presence.HTTPURL != "" {
+			v.httpURL("presence.http_url", cfg.Presence.HTTPURL)
+		}
+	}
+	if cfg.Presence.Interval < 1 {
+		v.fail("presence.interval", "must be at least 1 (got %d)", cfg.Presence.Interval)
+	}
+	if cfg.Sessions.GracePeriod < 1 {
+		v.fail("sessions.grace_period", "must be at least 1 (got %d)", cfg.Sessions.GracePeriod)
+	}
+	if cfg.Sessions.MaxBuffered < 0 {
+		v.fail("sessions.max_buffered", "must not be negative (got %d)", cfg.Sessions.MaxBuffered)
+	}
+	if cfg.WebSocket.QueueSize < 1 {
+		v.fail("websocket.queue_size", "must be at least 1 (got %d)", cfg.WebSocket.QueueSize)
+	}
+	if cfg.WebSocket.SlowClientTimeout < 1 {
+		v.fail("websocket.slow_client_timeout", "must be at least 1 (got %d)", cfg.WebSocket.SlowClientTimeout)
+	}
+	if cfg.Daemon.PIDFile == "" {
+		v.fail("daemon.pid_file", "must not be empty")
+	}
+	if cfg.Daemon.LogMaxSize < 1 {
+		v.fail("daemon.log_max_size", "must be at least 1 (got %d)", cfg.Daemon.LogMaxSize)
+	}
+	if cfg.Daemon.LogMaxBackups < 0 {
+		v.fail("daemon.log_max_backups", "must not be negative (got %d)", cfg.Daemon.LogMaxBackups)
+	}
+	if cfg.Alerts.WebhookURL != "" {
+		v.httpURL("alerts.webhook_url", cfg.Alerts.WebhookURL)
+	}
+	for _, validate := range validators {
+		v.errs = append(v.errs, validate(cfg)...)
+	}
+	return v.err()
+}
+
+// ParsePort checks that address has the form host:port and returns the port.
+func ParsePort(address string) (int, error) {
+	_, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a valid host:port address", address)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 0 || port > 65535 {
+		return 0, fmt.Errorf("%q has an invalid port", address)
+	}
+	return port, nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Watcher polls the config file and reloads the configuration whenever the
+// file's modification time or size changes. Invalid configurations are
+// reported and ignored.
+type Watcher struct {
+	opts     LoadOptions
+	interval time.Duration
+	onChange func(cfg *Config)
+	modTime  time.Time
+	size     int64
+}
+
+func NewWatcher(opts LoadOptions, interval time.Duration, onChange func(cfg *Config)) *Watcher {
+	watcher := &Watcher{
+		opts:     opts,
+		interval: interval,
+		onChange: onChange,
+	}
+	watcher.modTime, watcher.size = watcher.stat()
+	return watcher
+}
+
+func (watcher *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(watcher.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			modTime, size := watcher.stat()
+			if modTime.Equal(watcher.modTime) && size == watcher.size {
+				continue
+			}
+			watcher.modTime, watcher.size = modTime, size
+			watcher.reload()
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (watcher *Watcher) reload() {
+	log.Infof("Config file {%s} changed. Reloading...", watcher.opts.Path)
+	cfg, err := Load(watcher.opts)
+	if err != nil {
+		log.Error("Unable to reload config: ", err)
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Error("Ignoring config change: ", err)
+		return
+	}
+	watcher.onChange(cfg)
+}
+
+func (watcher *Watcher) stat() (time.Time, int64) {
+	info, err := os.Stat(watcher.opts.Path)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
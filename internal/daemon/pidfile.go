@@ -0,0 +1,67 @@
+package daemon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PIDFile holds the process ID of the running instance. It doubles as a lock
+// file: as long as it names a running process, no other instance may start.
+type PIDFile struct {
+	path string
+}
+
+// AcquirePIDFile writes the process ID to path. A file left over by an
+// instance which is no longer running is replaced.
+func AcquirePIDFile(path string) (*PIDFile, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = fmt.Fprintf(file, "%d\n", os.Getpid())
+			if e := file.Close(); err == nil {
+				err = e
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, err
+			}
+			return &PIDFile{path: path}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if pid, ok := readPID(path); ok && pid != os.Getpid() && processRunning(pid) {
+			return nil, fmt.Errorf("GoPilot is already running (pid %d, see %s)", pid, path)
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("unable to acquire %s", path)
+}
+
+// Release removes the file unless another instance has taken it over.
+func (f *PIDFile) Release() error {
+	if pid, ok := readPID(f.path); !ok || pid != os.Getpid() {
+		return nil
+	}
+	return os.Remove(f.path)
+}
+
+func readPID(path string) (int, bool) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(buf)))
+	return pid, err == nil && pid > 0
+}
@@ -0,0 +1,103 @@
+package daemon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePID(t *testing.T, path string, pid int) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(fmt.Sprintf("%d\n", pid)), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// exitedPID returns the ID of a process which has exited.
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestProcessRunning(t *testing.T) {
+	if !processRunning(os.Getpid()) {
+		t.Error("this process is not running")
+	}
+	if pid := exitedPID(t); processRunning(pid) {
+		t.Errorf("exited process %d is running", pid)
+	}
+}
+
+func TestPIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "gopilot.pid")
+	f, err := AcquirePIDFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid, ok := readPID(path); !ok || pid != os.Getpid() {
+		t.Errorf("PID file contains %d, want %d", pid, os.Getpid())
+	}
+	// The same process may acquire it again, e.g. after a crash of a
+	// previous instance with the same PID.
+	if _, err := AcquirePIDFile(path); err != nil {
+		t.Error(err)
+	}
+	if err := f.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("PID file was not removed")
+	}
+}
+
+func TestPIDFileOfOtherInstance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gopilot.pid")
+	tests := []struct {
+		name    string
+		content string
+		running bool
+	}{
+		{name: "running", content: fmt.Sprint(os.Getppid()), running: true},
+		{name: "exited", content: fmt.Sprint(exitedPID(t))},
+		{name: "garbage", content: "gopilot"},
+		{name: "empty", content: ""},
+	}
+	for _, test := range tests {
+		if err := ioutil.WriteFile(path, []byte(test.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := AcquirePIDFile(path)
+		if test.running {
+			if err == nil || !strings.Contains(err.Error(), "already running") {
+				t.Errorf("%s: error %v, want already running", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		f.Release()
+	}
+
+	// A file taken over by another instance is kept.
+	f, err := AcquirePIDFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePID(t, path, os.Getppid())
+	if err := f.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if pid, _ := readPID(path); pid != os.Getppid() {
+		t.Error("PID file of another instance was removed")
+	}
+}
@@ -0,0 +1,202 @@
+package flightlog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const logbookFile = "logbook.jsonl"
+
+type Airport struct {
+	ICAO string `json:"icao"`
+	Name string `json:"name"`
+}
+
+type Entry struct {
+	ID           string     `json:"id"`
+	Aircraft     string     `json:"aircraft"`
+	Registration string     `json:"registration"`
+	Departure    *Airport   `json:"departure,omitempty"`
+	Arrival      *Airport   `json:"arrival,omitempty"`
+	Route        string     `json:"route"`
+	OffBlock     *time.Time `json:"offBlock,omitempty"`
+	Takeoff      time.Time  `json:"takeoff"`
+	Landing      time.Time  `json:"landing"`
+	OnBlock      time.Time  `json:"onBlock"`
+	BlockTime    float64    `json:"blockTime"` // minutes
+	AirTime      float64    `json:"airTime"`   // minutes
+	Distance     float64    `json:"distance"`  // nautical miles
+	Landings     int        `json:"landings"`
+}
+
+// Query filters logbook entries. Zero values match everything.
+type Query struct {
+	From     time.Time
+	To       time.Time
+	Airport  string // departure or arrival ICAO code
+	Aircraft string // substring of the aircraft title or registration
+	Limit    int
+}
+
+func (q *Query) matches(entry *Entry) bool {
+	if !q.From.IsZero() && entry.Takeoff.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !entry.Takeoff.Before(q.To) {
+		return false
+	}
+	if q.Airport != "" {
+		icao := strings.ToUpper(q.Airport)
+		if (entry.Departure == nil || entry.Departure.ICAO != icao) && (entry.Arrival == nil || entry.Arrival.ICAO != icao) {
+			return false
+		}
+	}
+	if q.Aircraft != "" {
+		needle := strings.ToLower(q.Aircraft)
+		if !strings.Contains(strings.ToLower(entry.Aircraft), needle) && !strings.Contains(strings.ToLower(entry.Registration), needle) {
+			return false
+		}
+	}
+	return true
+}
+
+// Logbook stores one JSON line per flight.
+type Logbook struct {
+	path  string
+	mutex sync.Mutex
+}
+
+func NewLogbook(dir string) (*Logbook, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Logbook{path: filepath.Join(dir, logbookFile)}, nil
+}
+
+func (lb *Logbook) Path() string {
+	return lb.path
+}
+
+func (lb *Logbook) Add(entry *Entry) error {
+	buf, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+	file, err := os.OpenFile(lb.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(buf, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Entries returns the entries matching the query, newest first.
+func (lb *Logbook) Entries(q Query) ([]*Entry, error) {
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+	entries := make([]*Entry, 0)
+	file, err := os.Open(lb.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		entry := &Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", lb.path, line, err.Error())
+		}
+		if q.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Takeoff.After(entries[j].Takeoff)
+	})
+	if q.Limit > 0 && len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+	}
+	return entries, nil
+}
+
+var csvHeader = []string{
+	"date", "aircraft", "registration", "departure", "arrival", "route",
+	"off_block", "takeoff", "landing", "on_block", "block_time", "air_time", "distance_nm", "landings",
+}
+
+// WriteCSV writes the entries as CSV. Times are UTC, durations hh:mm.
+func WriteCSV(w io.Writer, entries []*Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		offBlock := ""
+		if e.OffBlock != nil {
+			offBlock = formatTime(*e.OffBlock)
+		}
+		record := []string{
+			e.Takeoff.UTC().Format("2006-01-02"),
+			e.Aircraft,
+			e.Registration,
+			airportCode(e.Departure),
+			airportCode(e.Arrival),
+			e.Route,
+			offBlock,
+			formatTime(e.Takeoff),
+			formatTime(e.Landing),
+			formatTime(e.OnBlock),
+			formatMinutes(e.BlockTime),
+			formatMinutes(e.AirTime),
+			fmt.Sprintf("%.1f", e.Distance),
+			fmt.Sprint(e.Landings),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func airportCode(airport *Airport) string {
+	if airport == nil {
+		return ""
+	}
+	return airport.ICAO
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("15:04")
+}
+
+func formatMinutes(minutes float64) string {
+	m := int(minutes + 0.5)
+	return fmt.Sprintf("%d:%02d", m/60, m%60)
+}
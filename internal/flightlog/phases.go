@@ -0,0 +1,72 @@
+package flightlog
+
+type Phase string
+
+const (
+	PhaseUnknown     Phase = ""
+	PhaseParked      Phase = "parked"
+	PhaseTaxi        Phase = "taxi"
+	PhaseTakeoffRoll Phase = "takeoff_roll"
+	PhaseClimb       Phase = "climb"
+	PhaseCruise      Phase = "cruise"
+	PhaseDescent     Phase = "descent"
+	PhaseApproach    Phase = "approach"
+	PhaseLanding     Phase = "landing"
+	PhaseRollout     Phase = "rollout"
+)
+
+const (
+	movingSpeed      = 1.0    // knots
+	takeoffRollSpeed = 40.0   // knots
+	taxiSpeed        = 30.0   // knots; below this the takeoff or landing roll has ended
+	climbRate        = 300.0  // feet per minute
+	descentRate      = -300.0 // feet per minute
+	approachHeight   = 1500.0 // feet above ground
+	approachRate     = -200.0 // feet per minute
+	landingHeight    = 50.0   // feet above ground
+)
+
+// State is what the phase detection is based on.
+type State struct {
+	GroundSpeed   float64 // knots
+	VerticalSpeed float64 // feet per minute
+	OnGround      bool
+	Height        float64 // feet above ground
+}
+
+// NextPhase infers the flight phase from the current state and the previous
+// phase. The previous phase provides some hysteresis, e.g. an aircraft on
+// approach stays on approach while leveling off.
+func NextPhase(previous Phase, s State) Phase {
+	if s.OnGround {
+		switch {
+		case previous.Airborne() || previous == PhaseRollout && s.GroundSpeed >= taxiSpeed:
+			return PhaseRollout
+		case s.GroundSpeed >= takeoffRollSpeed || previous == PhaseTakeoffRoll && s.GroundSpeed >= taxiSpeed:
+			return PhaseTakeoffRoll
+		case s.GroundSpeed >= movingSpeed:
+			return PhaseTaxi
+		}
+		return PhaseParked
+	}
+
+	switch {
+	case s.Height < landingHeight && s.VerticalSpeed < 0 && (previous == PhaseApproach || previous == PhaseLanding):
+		return PhaseLanding
+	case s.Height < approachHeight && (s.VerticalSpeed < approachRate || previous == PhaseApproach && s.VerticalSpeed < climbRate):
+		return PhaseApproach
+	case s.VerticalSpeed > climbRate:
+		return PhaseClimb
+	case s.VerticalSpeed < descentRate:
+		return PhaseDescent
+	}
+	return PhaseCruise
+}
+
+func (p Phase) Airborne() bool {
+	switch p {
+	case PhaseClimb, PhaseCruise, PhaseDescent, PhaseApproach, PhaseLanding:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package flightlog
+
+import "testing"
+
+func TestNextPhase(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous Phase
+		state    State
+		want     Phase
+	}{
+		{name: "standing", previous: PhaseUnknown, state: State{OnGround: true}, want: PhaseParked},
+		{name: "creeping", previous: PhaseParked, state: State{OnGround: true, GroundSpeed: 0.5}, want: PhaseParked},
+		{name: "taxiing", previous: PhaseParked, state: State{OnGround: true, GroundSpeed: 15}, want: PhaseTaxi},
+		{name: "fast taxi", previous: PhaseTaxi, state: State{OnGround: true, GroundSpeed: 35}, want: PhaseTaxi},
+		{name: "takeoff roll", previous: PhaseTaxi, state: State{OnGround: true, GroundSpeed: 45}, want: PhaseTakeoffRoll},
+		{name: "rejected takeoff", previous: PhaseTakeoffRoll, state: State{OnGround: true, GroundSpeed: 35}, want: PhaseTakeoffRoll},
+		{name: "rejected takeoff ends", previous: PhaseTakeoffRoll, state: State{OnGround: true, GroundSpeed: 25}, want: PhaseTaxi},
+		{name: "lift off", previous: PhaseTakeoffRoll, state: State{VerticalSpeed: 800, Height: 20}, want: PhaseClimb},
+		{name: "level", previous: PhaseClimb, state: State{VerticalSpeed: 100, Height: 5000}, want: PhaseCruise},
+		{name: "shallow descent", previous: PhaseCruise, state: State{VerticalSpeed: -250, Height: 5000}, want: PhaseCruise},
+		{name: "descent", previous: PhaseCruise, state: State{VerticalSpeed: -700, Height: 5000}, want: PhaseDescent},
+		{name: "approach", previous: PhaseDescent, state: State{VerticalSpeed: -500, Height: 1400}, want: PhaseApproach},
+		{name: "level at pattern altitude", previous: PhaseCruise, state: State{VerticalSpeed: 0, Height: 1000}, want: PhaseCruise},
+		{name: "level on approach", previous: PhaseApproach, state: State{VerticalSpeed: 0, Height: 1000}, want: PhaseApproach},
+		{name: "go around", previous: PhaseApproach, state: State{VerticalSpeed: 500, Height: 300}, want: PhaseClimb},
+		{name: "short final", previous: PhaseApproach, state: State{VerticalSpeed: -400, Height: 40}, want: PhaseLanding},
+		{name: "flare", previous: PhaseLanding, state: State{VerticalSpeed: -100, Height: 5}, want: PhaseLanding},
+		{name: "low pass", previous: PhaseCruise, state: State{VerticalSpeed: -100, Height: 40}, want: PhaseCruise},
+		{name: "touchdown", previous: PhaseLanding, state: State{OnGround: true, GroundSpeed: 60}, want: PhaseRollout},
+		{name: "bounce", previous: PhaseClimb, state: State{OnGround: true, GroundSpeed: 50}, want: PhaseRollout},
+		{name: "rollout", previous: PhaseRollout, state: State{OnGround: true, GroundSpeed: 45}, want: PhaseRollout},
+		{name: "vacating", previous: PhaseRollout, state: State{OnGround: true, GroundSpeed: 20}, want: PhaseTaxi},
+		{name: "stopped on the runway", previous: PhaseRollout, state: State{OnGround: true}, want: PhaseParked},
+	}
+	for _, test := range tests {
+		if got := NextPhase(test.previous, test.state); got != test.want {
+			t.Errorf("%s: NextPhase(%q, %+v) = %q, want %q", test.name, test.previous, test.state, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package flightlog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"msfs2020-gopilot/internal/geo"
+	"msfs2020-gopilot/internal/telemetry"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventPhase   = "phase"
+	EventTakeoff = "takeoff"
+	EventLanding = "landing"
+	EventFlight  = "flight"
+
+	// A flight ends once the aircraft has been parked for this long after
+	// landing. Stopping at a holding point does not end it.
+	parkedDelay = 60 * time.Second
+	// Flights are closed (or dropped if they have not landed yet) if no sample
+	// was received for this long, e.g. after the simulator was restarted.
+	maxSampleGap = 2 * time.Minute
+	// Airports are searched within this radius around the takeoff and
+	// touchdown positions.
+	airportSearchRadius = 5000.0 // meters
+
+	unknownAirport = "????"
+
+	monikerGroundSpeed   = "groundSpeed"
+	monikerVerticalSpeed = "verticalSpeed"
+	monikerOnGround      = "onGround"
+	monikerHeight        = "height"
+	monikerLatitude      = "latitude"
+	monikerLongitude     = "longitude"
+	monikerTitle         = "title"
+	monikerATCID         = "atcId"
+)
+
+// AirportFinder returns the airport nearest to a position within the radius.
+type AirportFinder func(latitude, longitude, radius float64) (*Airport, bool)
+
+type Event struct {
+	Type   string    `json:"event"`
+	Phase  Phase     `json:"phase"`
+	Time   time.Time `json:"time"`
+	Flight *Flight   `json:"flight,omitempty"`
+	Entry  *Entry    `json:"entry,omitempty"`
+}
+
+// Flight is the flight in progress.
+type Flight struct {
+	Aircraft     string     `json:"aircraft"`
+	Registration string     `json:"registration"`
+	Departure    *Airport   `json:"departure,omitempty"`
+	Arrival      *Airport   `json:"arrival,omitempty"`
+	OffBlock     *time.Time `json:"offBlock,omitempty"`
+	Takeoff      *time.Time `json:"takeoff,omitempty"`
+	Landing      *time.Time `json:"landing,omitempty"`
+	Distance     float64    `json:"distance"` // nautical miles
+	Landings     int        `json:"landings"`
+}
+
+type Status struct {
+	Phase  Phase   `json:"phase"`
+	Flight *Flight `json:"flight,omitempty"`
+}
+
+// Tracker detects flight phases and turns every flight into a logbook entry,
+// which is reported with an EventFlight event.
+type Tracker struct {
+	findAirport AirportFinder
+	onEvent     func(event *Event)
+	phase       Phase
+	flight      *Flight
+	distance    float64 // meters
+	onGround    bool
+	lastSample  time.Time
+	lastLat     float64
+	lastLon     float64
+	parkedSince time.Time
+	mutex       sync.Mutex
+}
+
+func NewTracker(findAirport AirportFinder, onEvent func(event *Event)) *Tracker {
+	return &Tracker{
+		findAirport: findAirport,
+		onEvent:     onEvent,
+	}
+}
+
+func (tr *Tracker) Vars() []telemetry.Var {
+	return []telemetry.Var{
+		{Name: "GROUND VELOCITY", Unit: "knot", Type: "float64", Moniker: monikerGroundSpeed},
+		{Name: "VERTICAL SPEED", Unit: "ft/min", Type: "float64", Moniker: monikerVerticalSpeed},
+		{Name: "SIM ON GROUND", Unit: "bool", Type: "int32", Moniker: monikerOnGround},
+		{Name: "PLANE ALT ABOVE GROUND", Unit: "feet", Type: "float64", Moniker: monikerHeight},
+		{Name: "PLANE LATITUDE", Unit: "degrees", Type: "float64", Moniker: monikerLatitude},
+		{Name: "PLANE LONGITUDE", Unit: "degrees", Type: "float64", Moniker: monikerLongitude},
+		{Name: "TITLE", Unit: "", Type: "string256", Moniker: monikerTitle},
+		{Name: "ATC ID", Unit: "", Type: "string64", Moniker: monikerATCID},
+	}
+}
+
+func (tr *Tracker) Status() Status {
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+	return Status{Phase: tr.phase, Flight: tr.snapshot()}
+}
+
+func (tr *Tracker) Consume(sample *telemetry.Sample) {
+	if !sample.Has(monikerGroundSpeed, monikerVerticalSpeed, monikerOnGround, monikerHeight, monikerLatitude, monikerLongitude) {
+		return
+	}
+	tr.mutex.Lock()
+	events := tr.update(sample)
+	tr.mutex.Unlock()
+	if tr.onEvent != nil {
+		for _, event := range events {
+			tr.onEvent(event)
+		}
+	}
+}
+
+func (tr *Tracker) update(sample *telemetry.Sample) []*Event {
+	t := sample.Time
+	s := State{}
+	s.GroundSpeed, _ = sample.Float(monikerGroundSpeed)
+	s.VerticalSpeed, _ = sample.Float(monikerVerticalSpeed)
+	s.OnGround, _ = sample.Bool(monikerOnGround)
+	s.Height, _ = sample.Float(monikerHeight)
+	lat, _ := sample.Float(monikerLatitude)
+	lon, _ := sample.Float(monikerLongitude)
+
+	events := make([]*Event, 0, 2)
+	first := tr.lastSample.IsZero()
+	if !first && t.Sub(tr.lastSample) > maxSampleGap {
+		if event := tr.finish(tr.lastSample); event != nil {
+			events = append(events, event)
+		}
+		tr.phase = PhaseUnknown
+		first = true
+	}
+
+	phase := NextPhase(tr.phase, s)
+
+	if tr.flight == nil && phase != PhaseParked {
+		tr.start(t, s.OnGround, sample)
+	}
+	if tr.flight != nil {
+		if !first {
+			tr.distance += geo.Distance(tr.lastLat, tr.lastLon, lat, lon)
+			tr.flight.Distance = round1(geo.MetersToNauticalMiles(tr.distance))
+		}
+		switch {
+		case !first && tr.onGround && !s.OnGround && tr.flight.Takeoff == nil:
+			tr.flight.Takeoff = timePtr(t)
+			tr.flight.Departure = tr.airport(lat, lon)
+			events = append(events, &Event{Type: EventTakeoff, Phase: phase, Time: t, Flight: tr.snapshot()})
+		case !first && !tr.onGround && s.OnGround && tr.flight.Takeoff != nil:
+			tr.flight.Landing = timePtr(t)
+			tr.flight.Arrival = tr.airport(lat, lon)
+			tr.flight.Landings++
+			events = append(events, &Event{Type: EventLanding, Phase: phase, Time: t, Flight: tr.snapshot()})
+		}
+	}
+
+	if phase == PhaseParked {
+		if tr.parkedSince.IsZero() {
+			tr.parkedSince = t
+		}
+		if tr.flight != nil && t.Sub(tr.parkedSince) >= parkedDelay {
+			if event := tr.finish(tr.parkedSince); event != nil {
+				events = append(events, event)
+			}
+		}
+	} else {
+		tr.parkedSince = time.Time{}
+	}
+
+	if phase != tr.phase {
+		events = append(events, &Event{Type: EventPhase, Phase: phase, Time: t, Flight: tr.snapshot()})
+	}
+	tr.phase = phase
+	tr.onGround = s.OnGround
+	tr.lastSample = t
+	tr.lastLat = lat
+	tr.lastLon = lon
+	return events
+}
+
+// start begins a new flight. If GoPilot was started in flight, the takeoff
+// time is the time of the first sample and the departure is unknown.
+func (tr *Tracker) start(t time.Time, onGround bool, sample *telemetry.Sample) {
+	aircraft, _ := sample.String(monikerTitle)
+	registration, _ := sample.String(monikerATCID)
+	tr.flight = &Flight{
+		Aircraft:     aircraft,
+		Registration: registration,
+	}
+	if onGround {
+		tr.flight.OffBlock = timePtr(t)
+	} else {
+		tr.flight.Takeoff = timePtr(t)
+	}
+	tr.distance = 0
+}
+
+// finish closes the flight in progress. Flights which have not landed (e.g.
+// taxiing around) are dropped.
+func (tr *Tracker) finish(onBlock time.Time) *Event {
+	f := tr.flight
+	tr.flight = nil
+	if f == nil || f.Takeoff == nil || f.Landing == nil {
+		return nil
+	}
+	blockStart := *f.Takeoff
+	if f.OffBlock != nil {
+		blockStart = *f.OffBlock
+	}
+	entry := &Entry{
+		ID:           fmt.Sprintf("%s-%s", f.Takeoff.Format("20060102-150405"), uuid.New().String()[:8]),
+		Aircraft:     f.Aircraft,
+		Registration: f.Registration,
+		Departure:    f.Departure,
+		Arrival:      f.Arrival,
+		Route:        fmt.Sprintf("%s-%s", airportCodeOrUnknown(f.Departure), airportCodeOrUnknown(f.Arrival)),
+		OffBlock:     f.OffBlock,
+		Takeoff:      *f.Takeoff,
+		Landing:      *f.Landing,
+		OnBlock:      onBlock,
+		BlockTime:    round1(onBlock.Sub(blockStart).Minutes()),
+		AirTime:      round1(f.Landing.Sub(*f.Takeoff).Minutes()),
+		Distance:     f.Distance,
+		Landings:     f.Landings,
+	}
+	return &Event{Type: EventFlight, Phase: tr.phase, Time: onBlock, Entry: entry}
+}
+
+func (tr *Tracker) airport(lat, lon float64) *Airport {
+	if tr.findAirport == nil {
+		return nil
+	}
+	if airport, ok := tr.findAirport(lat, lon, airportSearchRadius); ok {
+		return airport
+	}
+	return nil
+}
+
+func (tr *Tracker) snapshot() *Flight {
+	if tr.flight == nil {
+		return nil
+	}
+	f := *tr.flight
+	return &f
+}
+
+func airportCodeOrUnknown(airport *Airport) string {
+	if airport == nil || airport.ICAO == "" {
+		return unknownAirport
+	}
+	return airport.ICAO
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func round1(value float64) float64 {
+	return float64(int64(value*10+0.5)) / 10
+}
@@ -0,0 +1,235 @@
+package flightlog
+
+import (
+	"testing"
+	"time"
+
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+var start = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// step is a sample at the given second. The aircraft flies east from EDDF,
+// one arc minute of longitude (about 0.64 NM) per step in the air.
+type step struct {
+	second int
+	State
+}
+
+func parked(second int) step {
+	return step{second, State{OnGround: true}}
+}
+
+func taxi(second int, speed float64) step {
+	return step{second, State{OnGround: true, GroundSpeed: speed}}
+}
+
+func flying(second int, verticalSpeed, height float64) step {
+	return step{second, State{GroundSpeed: 100, VerticalSpeed: verticalSpeed, Height: height}}
+}
+
+type trackerTest struct {
+	tracker *Tracker
+	events  []*Event
+	lon     float64
+}
+
+func newTrackerTest() *trackerTest {
+	tt := &trackerTest{lon: 8.57}
+	tt.tracker = NewTracker(func(latitude, longitude, radius float64) (*Airport, bool) {
+		if longitude < 8.6 {
+			return &Airport{ICAO: "EDDF", Name: "Frankfurt"}, true
+		}
+		return nil, false
+	}, func(event *Event) {
+		tt.events = append(tt.events, event)
+	})
+	return tt
+}
+
+func (tt *trackerTest) run(steps ...step) {
+	for _, s := range steps {
+		if !s.OnGround {
+			tt.lon += 1.0 / 60
+		}
+		sample := telemetry.NewSample(start.Add(time.Duration(s.second) * time.Second))
+		sample.Set(monikerGroundSpeed, s.GroundSpeed)
+		sample.Set(monikerVerticalSpeed, s.VerticalSpeed)
+		sample.Set(monikerOnGround, s.OnGround)
+		sample.Set(monikerHeight, s.Height)
+		sample.Set(monikerLatitude, 50.03)
+		sample.Set(monikerLongitude, tt.lon)
+		sample.Set(monikerTitle, "Cessna Skyhawk")
+		sample.Set(monikerATCID, "D-EFGH")
+		tt.tracker.Consume(sample)
+	}
+}
+
+// eventTimes returns the seconds at which events of the type were sent.
+func (tt *trackerTest) eventTimes(eventType string) []int {
+	times := make([]int, 0)
+	for _, event := range tt.events {
+		if event.Type == eventType {
+			times = append(times, int(event.Time.Sub(start)/time.Second))
+		}
+	}
+	return times
+}
+
+func (tt *trackerTest) entries() []*Entry {
+	entries := make([]*Entry, 0)
+	for _, event := range tt.events {
+		if event.Type == EventFlight {
+			entries = append(entries, event.Entry)
+		}
+	}
+	return entries
+}
+
+func at(second int) time.Time {
+	return start.Add(time.Duration(second) * time.Second)
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTouchAndGoFlight(t *testing.T) {
+	tt := newTrackerTest()
+	tt.run(
+		parked(0),
+		taxi(10, 10),
+		parked(20), // holding point
+		parked(50),
+		taxi(60, 45),
+		flying(70, 800, 50),
+		flying(120, 0, 3000),
+		flying(180, -500, 1000),
+		flying(190, -400, 30),
+		taxi(200, 60), // touch and go
+		flying(210, 700, 40),
+		flying(300, -500, 800),
+		taxi(310, 55),
+		taxi(320, 10),
+		parked(330),
+		parked(360),
+	)
+	if entries := tt.entries(); len(entries) != 0 {
+		t.Fatalf("flight closed after %s parked", at(360).Sub(at(330)))
+	}
+	tt.run(parked(390))
+
+	if got := tt.eventTimes(EventTakeoff); !equalInts(got, []int{70}) {
+		t.Errorf("takeoffs at %v, want 70", got)
+	}
+	if got := tt.eventTimes(EventLanding); !equalInts(got, []int{200, 310}) {
+		t.Errorf("landings at %v, want 200 and 310", got)
+	}
+	entries := tt.entries()
+	if len(entries) != 1 {
+		t.Fatalf("%d logbook entries, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.Landings != 2 {
+		t.Errorf("%d landings, want 2", entry.Landings)
+	}
+	if entry.OffBlock == nil || !entry.OffBlock.Equal(at(10)) || !entry.Takeoff.Equal(at(70)) ||
+		!entry.Landing.Equal(at(310)) || !entry.OnBlock.Equal(at(330)) {
+		t.Errorf("times %v %s %s %s", entry.OffBlock, entry.Takeoff, entry.Landing, entry.OnBlock)
+	}
+	if entry.BlockTime != 5.3 || entry.AirTime != 4.0 {
+		t.Errorf("block time %g, air time %g, want 5.3 and 4.0", entry.BlockTime, entry.AirTime)
+	}
+	if entry.Route != "EDDF-????" || entry.Aircraft != "Cessna Skyhawk" || entry.Registration != "D-EFGH" {
+		t.Errorf("entry %+v", entry)
+	}
+	// Six arc minutes of longitude at 50° N.
+	if entry.Distance < 3.8 || entry.Distance > 4.0 {
+		t.Errorf("distance %g NM, want 3.9", entry.Distance)
+	}
+	if status := tt.tracker.Status(); status.Phase != PhaseParked || status.Flight != nil {
+		t.Errorf("status %+v after the flight", status)
+	}
+}
+
+func TestSampleGap(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []step
+		entries int
+		onBlock int
+	}{
+		{
+			name: "landed",
+			steps: []step{
+				taxi(0, 45),
+				flying(10, 800, 100),
+				taxi(60, 50),
+				taxi(70, 10),
+				taxi(191, 10),
+			},
+			entries: 1,
+			onBlock: 70,
+		},
+		{
+			name: "not landed",
+			steps: []step{
+				taxi(0, 45),
+				flying(10, 800, 100),
+				flying(131, 0, 3000),
+			},
+		},
+		{
+			name: "short gap",
+			steps: []step{
+				taxi(0, 45),
+				flying(10, 800, 100),
+				taxi(60, 50),
+				taxi(70, 10),
+				parked(190),
+			},
+		},
+	}
+	for _, test := range tests {
+		tt := newTrackerTest()
+		tt.run(test.steps...)
+		entries := tt.entries()
+		if len(entries) != test.entries {
+			t.Errorf("%s: %d logbook entries, want %d", test.name, len(entries), test.entries)
+			continue
+		}
+		if test.entries > 0 && !entries[0].OnBlock.Equal(at(test.onBlock)) {
+			t.Errorf("%s: on block %s, want %s", test.name, entries[0].OnBlock, at(test.onBlock))
+		}
+	}
+}
+
+// After a gap in the air, e.g. when GoPilot was restarted, a new flight begins
+// with the first sample as the takeoff time and an unknown departure.
+func TestStartInFlight(t *testing.T) {
+	tt := newTrackerTest()
+	tt.run(
+		taxi(0, 45),
+		flying(10, 800, 100),
+		flying(200, 0, 3000),
+	)
+	status := tt.tracker.Status()
+	if status.Phase != PhaseCruise || status.Flight == nil {
+		t.Fatalf("status %+v, want a flight in cruise", status)
+	}
+	flight := status.Flight
+	if flight.OffBlock != nil || flight.Takeoff == nil || !flight.Takeoff.Equal(at(200)) || flight.Departure != nil {
+		t.Errorf("flight %+v, want a takeoff at 200 s from an unknown airport", flight)
+	}
+	if got := tt.eventTimes(EventTakeoff); !equalInts(got, []int{10}) {
+		t.Errorf("takeoffs at %v, want 10 only", got)
+	}
+}
@@ -0,0 +1,231 @@
+package gdl90
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"msfs2020-gopilot/internal/geo"
+)
+
+// Messages as described in the "GDL 90 Data Interface Specification"
+// (560-1058-00 Rev A) and the ForeFlight GDL 90 extensions.
+const (
+	MessageHeartbeat          = 0x00
+	MessageOwnship            = 0x0A
+	MessageOwnshipGeoAltitude = 0x0B
+	MessageTraffic            = 0x14
+	MessageForeFlight         = 0x65
+
+	flagByte    = 0x7E
+	controlByte = 0x7D
+	escapeXOR   = 0x20
+
+	AddressTypeICAO         = 0
+	AddressTypeSelfAssigned = 1
+
+	EmitterNone       = 0
+	EmitterLight      = 1
+	EmitterLarge      = 3
+	EmitterRotorcraft = 7
+
+	// Navigation integrity and accuracy categories. The simulator position is
+	// exact, so the best categories are used.
+	nic  = 11
+	nacp = 11
+
+	invalidAltitude = 0xFFF
+	invalidSpeed    = 0xFFF
+	maxVertical     = 0x1FE // 32640 fpm
+	vfomMeters      = 10
+)
+
+var crcTable [256]uint16
+
+func init() {
+	for i := range crcTable {
+		crc := uint16(i) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+		crcTable[i] = crc
+	}
+}
+
+// CRC returns the CRC-16-CCITT of a message (message ID and data).
+func CRC(msg []byte) uint16 {
+	var crc uint16
+	for _, b := range msg {
+		crc = crcTable[crc>>8] ^ crc<<8 ^ uint16(b)
+	}
+	return crc
+}
+
+// Frame appends the CRC (LSB first), escapes flag and control bytes and adds
+// the flag bytes at both ends.
+func Frame(msg []byte) []byte {
+	crc := CRC(msg)
+	data := append(append([]byte{}, msg...), byte(crc), byte(crc>>8))
+	frame := make([]byte, 0, len(data)+4)
+	frame = append(frame, flagByte)
+	for _, b := range data {
+		if b == flagByte || b == controlByte {
+			frame = append(frame, controlByte, b^escapeXOR)
+		} else {
+			frame = append(frame, b)
+		}
+	}
+	return append(frame, flagByte)
+}
+
+// Heartbeat returns the heartbeat message. The timestamp is the number of
+// seconds since 0000Z.
+func Heartbeat(t time.Time, positionValid bool) []byte {
+	t = t.UTC()
+	seconds := t.Hour()*3600 + t.Minute()*60 + t.Second()
+	status1 := byte(0x01) // UAT initialized
+	if positionValid {
+		status1 |= 0x80
+	}
+	status2 := byte(0x01) // UTC OK
+	if seconds&0x10000 != 0 {
+		status2 |= 0x80
+	}
+	return []byte{
+		MessageHeartbeat,
+		status1,
+		status2,
+		byte(seconds), byte(seconds >> 8),
+		0, 0, // message counts
+	}
+}
+
+// Report is the content of an ownship or traffic report.
+type Report struct {
+	Address       uint32 // 24 bits
+	AddressType   byte
+	Latitude      float64 // degrees
+	Longitude     float64 // degrees
+	Altitude      float64 // pressure altitude in feet
+	Airborne      bool
+	GroundSpeed   float64 // knots
+	VerticalSpeed float64 // feet per minute
+	Track         float64 // degrees true
+	Emitter       byte
+	Callsign      string
+}
+
+func OwnshipReport(report *Report) []byte {
+	return encodeReport(MessageOwnship, report)
+}
+
+func TrafficReport(report *Report) []byte {
+	return encodeReport(MessageTraffic, report)
+}
+
+func encodeReport(id byte, r *Report) []byte {
+	msg := make([]byte, 28)
+	msg[0] = id
+	msg[1] = r.AddressType & 0x0F
+	put24(msg[2:], r.Address)
+	put24(msg[5:], uint32(encodeDegrees(r.Latitude)))
+	put24(msg[8:], uint32(encodeDegrees(r.Longitude)))
+
+	altitude := int(invalidAltitude)
+	if !math.IsNaN(r.Altitude) {
+		altitude = clamp(int(math.Round((r.Altitude+1000)/25)), 0, invalidAltitude-1)
+	}
+	misc := byte(0x01) // updated report, true track
+	if r.Airborne {
+		misc |= 0x08
+	}
+	msg[11] = byte(altitude >> 4)
+	msg[12] = byte(altitude&0x0F)<<4 | misc
+	msg[13] = nic<<4 | nacp
+
+	horizontal := clamp(int(math.Round(r.GroundSpeed)), 0, invalidSpeed-1)
+	vertical := clamp(int(math.Round(r.VerticalSpeed/64)), -maxVertical, maxVertical) & 0xFFF
+	msg[14] = byte(horizontal >> 4)
+	msg[15] = byte(horizontal&0x0F)<<4 | byte(vertical>>8)
+	msg[16] = byte(vertical)
+	msg[17] = byte(int(math.Round(geo.NormalizeHeading(r.Track)*256/360)) & 0xFF)
+	msg[18] = r.Emitter
+	copy(msg[19:27], callsign(r.Callsign))
+	msg[27] = 0 // no emergency
+	return msg
+}
+
+// OwnshipGeoAltitude returns the ownship geometric altitude message.
+func OwnshipGeoAltitude(altitude float64) []byte {
+	value := clamp(int(math.Round(altitude/5)), math.MinInt16, math.MaxInt16)
+	return []byte{
+		MessageOwnshipGeoAltitude,
+		byte(value >> 8), byte(value),
+		byte(vfomMeters >> 8), byte(vfomMeters),
+	}
+}
+
+// ForeFlightID returns the ForeFlight device identification message, which
+// makes EFBs show the device name. The geometric altitude is declared as MSL.
+func ForeFlightID(name, longName string) []byte {
+	msg := make([]byte, 39)
+	msg[0] = MessageForeFlight
+	msg[1] = 0 // ID message
+	msg[2] = 1 // version
+	for i := 3; i < 11; i++ {
+		msg[i] = 0xFF // serial number not available
+	}
+	copy(msg[11:19], padded(name, 8, 0))
+	copy(msg[19:35], padded(longName, 16, 0))
+	msg[38] = 0x01 // capabilities: geometric altitude datum is MSL
+	return msg
+}
+
+// encodeDegrees converts a latitude or longitude to a 24-bit two's complement
+// value with a resolution of 180/2^23 degrees. Like in the examples of the
+// specification, the value is truncated.
+func encodeDegrees(deg float64) int32 {
+	value := int32(deg * (1 << 23) / 180)
+	return value & 0xFFFFFF
+}
+
+func put24(b []byte, value uint32) {
+	b[0] = byte(value >> 16)
+	b[1] = byte(value >> 8)
+	b[2] = byte(value)
+}
+
+// callsign returns the callsign as 8 characters (0-9, A-Z, space padded).
+func callsign(s string) []byte {
+	s = strings.ToUpper(s)
+	var b strings.Builder
+	for _, c := range s {
+		if (c >= '0' && c <= '9') || (c >= 'A' && c <= 'Z') {
+			b.WriteRune(c)
+		}
+	}
+	return padded(b.String(), 8, ' ')
+}
+
+func padded(s string, size int, pad byte) []byte {
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = pad
+	}
+	copy(b, s)
+	return b
+}
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
@@ -0,0 +1,208 @@
+package gdl90
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestCRC(t *testing.T) {
+	tests := []struct {
+		msg string
+		crc uint16
+	}{
+		// Heartbeat example of the specification, section 2.2.3.
+		{msg: "00 81 41 DB D0 08 02", crc: 0x8BB3},
+		{msg: "", crc: 0},
+	}
+	for _, test := range tests {
+		if got := CRC(unhex(t, test.msg)); got != test.crc {
+			t.Errorf("CRC(%s) = %04X, want %04X", test.msg, got, test.crc)
+		}
+	}
+}
+
+func TestFrame(t *testing.T) {
+	tests := []struct {
+		name  string
+		msg   string
+		frame string
+	}{
+		{
+			name:  "spec heartbeat",
+			msg:   "00 81 41 DB D0 08 02",
+			frame: "7E 00 81 41 DB D0 08 02 B3 8B 7E",
+		},
+		{
+			name:  "flag and control bytes",
+			msg:   "7E 7D 01",
+			frame: "7E 7D 5E 7D 5D 01" + crcBytes(t, "7E 7D 01") + " 7E",
+		},
+	}
+	for _, test := range tests {
+		if got, want := Frame(unhex(t, test.msg)), unhex(t, test.frame); !bytes.Equal(got, want) {
+			t.Errorf("%s: Frame = % X, want % X", test.name, got, want)
+		}
+	}
+}
+
+// crcBytes returns the CRC of a message as it is framed, escaped if necessary.
+func crcBytes(t *testing.T, msg string) string {
+	crc := CRC(unhex(t, msg))
+	var b strings.Builder
+	for _, c := range []byte{byte(crc), byte(crc >> 8)} {
+		if c == flagByte || c == controlByte {
+			b.WriteString(" " + hex.EncodeToString([]byte{controlByte, c ^ escapeXOR}))
+		} else {
+			b.WriteString(" " + hex.EncodeToString([]byte{c}))
+		}
+	}
+	return b.String()
+}
+
+// Whatever the content, a frame has flag bytes only at both ends and the
+// unescaped content ends with the CRC of the message.
+func TestFrameEscapesCRC(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		msg := []byte{MessageHeartbeat, byte(i), flagByte, controlByte}
+		frame := Frame(msg)
+		if frame[0] != flagByte || frame[len(frame)-1] != flagByte {
+			t.Fatalf("% X is not delimited by flag bytes", frame)
+		}
+		var data []byte
+		for j := 1; j < len(frame)-1; j++ {
+			switch frame[j] {
+			case flagByte:
+				t.Fatalf("% X has a flag byte inside", frame)
+			case controlByte:
+				j++
+				data = append(data, frame[j]^escapeXOR)
+			default:
+				data = append(data, frame[j])
+			}
+		}
+		crc := CRC(msg)
+		if want := append(msg, byte(crc), byte(crc>>8)); !bytes.Equal(data, want) {
+			t.Fatalf("% X unescapes to % X, want % X", frame, data, want)
+		}
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	tests := []struct {
+		time          time.Time
+		positionValid bool
+		msg           string
+	}{
+		// 14:51:07Z is 53467 (0xD0DB) seconds since 0000Z.
+		{time: time.Date(2021, 6, 1, 14, 51, 7, 0, time.UTC), positionValid: true, msg: "00 81 01 DB D0 00 00"},
+		{time: time.Date(2021, 6, 1, 14, 51, 7, 0, time.UTC), positionValid: false, msg: "00 01 01 DB D0 00 00"},
+		// 18:12:16Z is 65536 seconds, bit 16 goes to status byte 2.
+		{time: time.Date(2021, 6, 1, 18, 12, 16, 0, time.UTC), positionValid: true, msg: "00 81 81 00 00 00 00"},
+		{time: time.Date(2021, 6, 1, 16, 51, 7, 0, time.FixedZone("CEST", 2*3600)), positionValid: true, msg: "00 81 01 DB D0 00 00"},
+	}
+	for _, test := range tests {
+		if got, want := Heartbeat(test.time, test.positionValid), unhex(t, test.msg); !bytes.Equal(got, want) {
+			t.Errorf("Heartbeat(%s, %t) = % X, want % X", test.time, test.positionValid, got, want)
+		}
+	}
+}
+
+func TestReports(t *testing.T) {
+	// Traffic report example of the specification, section 3.5.4, with the
+	// integrity and accuracy categories used for the simulator (byte 13).
+	example := &Report{
+		Address:       0xAB4549,
+		AddressType:   AddressTypeICAO,
+		Latitude:      44.90708,
+		Longitude:     -122.99488,
+		Altitude:      5000,
+		Airborne:      true,
+		GroundSpeed:   123,
+		VerticalSpeed: 64,
+		Track:         45,
+		Emitter:       EmitterLight,
+		Callsign:      "N825V",
+	}
+	tests := []struct {
+		name   string
+		encode func(*Report) []byte
+		report *Report
+		msg    string
+	}{
+		{
+			name:   "spec traffic",
+			encode: TrafficReport,
+			report: example,
+			msg:    "14 00 AB 45 49 1F EF 15 A8 89 78 0F 09 BB 07 B0 01 20 01 4E 38 32 35 56 20 20 20 00",
+		},
+		{
+			name:   "spec ownship",
+			encode: OwnshipReport,
+			report: example,
+			msg:    "0A 00 AB 45 49 1F EF 15 A8 89 78 0F 09 BB 07 B0 01 20 01 4E 38 32 35 56 20 20 20 00",
+		},
+		{
+			name:   "on ground, descending, southern hemisphere",
+			encode: TrafficReport,
+			report: &Report{
+				Address:       0x123456,
+				AddressType:   AddressTypeSelfAssigned,
+				Latitude:      -45,
+				Longitude:     90,
+				Altitude:      -1000,
+				GroundSpeed:   0,
+				VerticalSpeed: -640,
+				Track:         -90,
+				Emitter:       EmitterRotorcraft,
+				Callsign:      "d-ehxy 1",
+			},
+			msg: "14 01 12 34 56 E0 00 00 40 00 00 00 01 BB 00 0F F6 C0 07 44 45 48 58 59 31 20 20 00",
+		},
+		{
+			name:   "limits",
+			encode: TrafficReport,
+			report: &Report{
+				Altitude:      200000,
+				Airborne:      true,
+				GroundSpeed:   5000,
+				VerticalSpeed: 50000,
+				Track:         360,
+				Callsign:      "TOOLONGCALLSIGN",
+			},
+			msg: "14 00 00 00 00 00 00 00 00 00 00 FF E9 BB FF E1 FE 00 00 54 4F 4F 4C 4F 4E 47 43 00",
+		},
+	}
+	for _, test := range tests {
+		if got, want := test.encode(test.report), unhex(t, test.msg); !bytes.Equal(got, want) {
+			t.Errorf("%s:\n got % X\nwant % X", test.name, got, want)
+		}
+	}
+}
+
+func TestOwnshipGeoAltitude(t *testing.T) {
+	tests := []struct {
+		altitude float64
+		msg      string
+	}{
+		{altitude: 5000, msg: "0B 03 E8 00 0A"},
+		{altitude: -100, msg: "0B FF EC 00 0A"},
+		{altitude: 1e6, msg: "0B 7F FF 00 0A"},
+	}
+	for _, test := range tests {
+		if got, want := OwnshipGeoAltitude(test.altitude), unhex(t, test.msg); !bytes.Equal(got, want) {
+			t.Errorf("OwnshipGeoAltitude(%g) = % X, want % X", test.altitude, got, want)
+		}
+	}
+}
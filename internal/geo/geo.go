@@ -0,0 +1,73 @@
+package geo
+
+import (
+	"math"
+)
+
+const (
+	EarthRadius           = 6371008.8 // meters
+	MetersPerNauticalMile = 1852.0
+	MetersPerFoot         = 0.3048
+)
+
+func DegToRad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func RadToDeg(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// Distance returns the great-circle distance between two positions in meters.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := DegToRad(lat1)
+	phi2 := DegToRad(lat2)
+	dPhi := DegToRad(lat2 - lat1)
+	dLambda := DegToRad(lon2 - lon1)
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// Bearing returns the initial true bearing from the first to the second
+// position in degrees [0, 360).
+func Bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := DegToRad(lat1)
+	phi2 := DegToRad(lat2)
+	dLambda := DegToRad(lon2 - lon1)
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	return NormalizeHeading(RadToDeg(math.Atan2(y, x)))
+}
+
+// NormalizeHeading maps a heading to [0, 360).
+func NormalizeHeading(deg float64) float64 {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
+
+// AlongAcross splits the distance from a reference point to a position into a
+// component along the given course and a component across it, both in meters.
+// Positive across values are to the right of the course.
+func AlongAcross(refLat, refLon, course, lat, lon float64) (along, across float64) {
+	d := Distance(refLat, refLon, lat, lon)
+	if d == 0 {
+		return 0, 0
+	}
+	angle := DegToRad(Bearing(refLat, refLon, lat, lon) - course)
+	return d * math.Cos(angle), d * math.Sin(angle)
+}
+
+func MetersToNauticalMiles(m float64) float64 {
+	return m / MetersPerNauticalMile
+}
+
+func MetersToFeet(m float64) float64 {
+	return m / MetersPerFoot
+}
+
+func FeetToMeters(ft float64) float64 {
+	return ft * MetersPerFoot
+}
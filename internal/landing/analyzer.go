@@ -0,0 +1,208 @@
+package landing
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"msfs2020-gopilot/internal/telemetry"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// The G-force peak usually comes a moment after the on-ground flag is set,
+	// so it is taken from the samples within this time after the touchdown.
+	touchdownWindow = time.Second
+	// Touchdowns within this time after the previous one are bounces and are
+	// not reported separately. The report is sent once this time has passed
+	// without another touchdown.
+	bounceWindow = 5 * time.Second
+	// The last airborne sample has to be this recent, otherwise the aircraft
+	// was most likely placed on the ground (e.g. teleported).
+	maxAirborneAge = 2 * time.Second
+	// Runways are searched within this radius around the touchdown point.
+	runwaySearchRadius = 5000.0 // meters
+
+	RatingButter = "butter"
+	RatingSmooth = "smooth"
+	RatingFirm   = "firm"
+	RatingHard   = "hard"
+
+	monikerOnGround      = "onGround"
+	monikerVerticalSpeed = "verticalSpeed"
+	monikerGForce        = "gForce"
+	monikerBank          = "bank"
+	monikerPitch         = "pitch"
+	monikerGroundSpeed   = "groundSpeed"
+	monikerWindX         = "windX"
+	monikerWindZ         = "windZ"
+	monikerLatitude      = "latitude"
+	monikerLongitude     = "longitude"
+	monikerHeading       = "heading"
+	monikerTitle         = "title"
+)
+
+type Report struct {
+	ID            string      `json:"id"`
+	Time          time.Time   `json:"time"`
+	Aircraft      string      `json:"aircraft"`
+	Latitude      float64     `json:"latitude"`
+	Longitude     float64     `json:"longitude"`
+	Heading       float64     `json:"heading"`       // degrees true
+	VerticalSpeed float64     `json:"verticalSpeed"` // feet per minute, negative when descending
+	GForce        float64     `json:"gForce"`
+	Bank          float64     `json:"bank"`        // degrees, positive to the left
+	Pitch         float64     `json:"pitch"`       // degrees, positive nose up
+	GroundSpeed   float64     `json:"groundSpeed"` // knots
+	Crosswind     float64     `json:"crosswind"`   // knots, positive from the right
+	Headwind      float64     `json:"headwind"`    // knots, negative for tailwind
+	Bounces       int         `json:"bounces"`
+	Rating        string      `json:"rating"`
+	Runway        *RunwayInfo `json:"runway,omitempty"`
+}
+
+// Analyzer detects touchdowns from the on-ground transition and reports them.
+// Vertical speed, bank and pitch are taken from the last sample before the
+// touchdown, since SimConnect already reports ground values once the aircraft
+// is on the ground.
+type Analyzer struct {
+	findRunways RunwayFinder
+	onReport    func(report *Report)
+	airborne    *telemetry.Sample
+	onGround    bool
+	initialized bool
+	pending     *Report
+	lastTouch   time.Time // last touchdown of the pending report
+	mutex       sync.Mutex
+}
+
+func NewAnalyzer(findRunways RunwayFinder, onReport func(report *Report)) *Analyzer {
+	return &Analyzer{
+		findRunways: findRunways,
+		onReport:    onReport,
+	}
+}
+
+func (an *Analyzer) Vars() []telemetry.Var {
+	return []telemetry.Var{
+		{Name: "SIM ON GROUND", Unit: "bool", Type: "int32", Moniker: monikerOnGround},
+		{Name: "VERTICAL SPEED", Unit: "ft/min", Type: "float64", Moniker: monikerVerticalSpeed},
+		{Name: "G FORCE", Unit: "GForce", Type: "float64", Moniker: monikerGForce},
+		{Name: "PLANE BANK DEGREES", Unit: "degrees", Type: "float64", Moniker: monikerBank},
+		{Name: "PLANE PITCH DEGREES", Unit: "degrees", Type: "float64", Moniker: monikerPitch},
+		{Name: "GROUND VELOCITY", Unit: "knot", Type: "float64", Moniker: monikerGroundSpeed},
+		{Name: "AIRCRAFT WIND X", Unit: "knot", Type: "float64", Moniker: monikerWindX},
+		{Name: "AIRCRAFT WIND Z", Unit: "knot", Type: "float64", Moniker: monikerWindZ},
+		{Name: "PLANE LATITUDE", Unit: "degrees", Type: "float64", Moniker: monikerLatitude},
+		{Name: "PLANE LONGITUDE", Unit: "degrees", Type: "float64", Moniker: monikerLongitude},
+		{Name: "PLANE HEADING DEGREES TRUE", Unit: "degrees", Type: "float64", Moniker: monikerHeading},
+		{Name: "TITLE", Unit: "", Type: "string256", Moniker: monikerTitle},
+	}
+}
+
+func (an *Analyzer) Consume(sample *telemetry.Sample) {
+	if !sample.Has(monikerOnGround, monikerVerticalSpeed, monikerGForce, monikerLatitude, monikerLongitude) {
+		return
+	}
+	an.mutex.Lock()
+	report := an.update(sample)
+	an.mutex.Unlock()
+	if report != nil && an.onReport != nil {
+		an.onReport(report)
+	}
+}
+
+func (an *Analyzer) update(sample *telemetry.Sample) *Report {
+	onGround, _ := sample.Bool(monikerOnGround)
+	gForce, _ := sample.Float(monikerGForce)
+	touchdown := an.initialized && !an.onGround && onGround &&
+		an.airborne != nil && sample.Time.Sub(an.airborne.Time) <= maxAirborneAge
+	an.initialized = true
+	an.onGround = onGround
+	if !onGround {
+		an.airborne = sample
+	}
+
+	if an.pending != nil {
+		if sample.Time.Sub(an.pending.Time) < touchdownWindow {
+			an.pending.GForce = math.Max(an.pending.GForce, round(gForce, 2))
+		}
+		if touchdown {
+			an.pending.Bounces++
+			an.lastTouch = sample.Time
+			return nil
+		}
+		if sample.Time.Sub(an.lastTouch) < bounceWindow {
+			return nil
+		}
+		report := an.pending
+		an.pending = nil
+		return report
+	}
+
+	if touchdown {
+		an.pending = an.newReport(sample)
+		an.lastTouch = sample.Time
+	}
+	return nil
+}
+
+func (an *Analyzer) newReport(sample *telemetry.Sample) *Report {
+	before := an.airborne
+	verticalSpeed, _ := before.Float(monikerVerticalSpeed)
+	bank, _ := before.Float(monikerBank)
+	pitch, _ := before.Float(monikerPitch)
+	gBefore, _ := before.Float(monikerGForce)
+	gForce, _ := sample.Float(monikerGForce)
+	groundSpeed, _ := sample.Float(monikerGroundSpeed)
+	windX, _ := sample.Float(monikerWindX)
+	windZ, _ := sample.Float(monikerWindZ)
+	latitude, _ := sample.Float(monikerLatitude)
+	longitude, _ := sample.Float(monikerLongitude)
+	heading, _ := sample.Float(monikerHeading)
+	aircraft, _ := sample.String(monikerTitle)
+
+	report := &Report{
+		ID:            fmt.Sprintf("%s-%s", sample.Time.Format("20060102-150405"), uuid.New().String()[:8]),
+		Time:          sample.Time,
+		Aircraft:      aircraft,
+		Latitude:      latitude,
+		Longitude:     longitude,
+		Heading:       round(heading, 1),
+		VerticalSpeed: round(verticalSpeed, 0),
+		GForce:        round(math.Max(gBefore, gForce), 2),
+		Bank:          round(bank, 1),
+		Pitch:         round(-pitch, 1),
+		GroundSpeed:   round(groundSpeed, 1),
+		// AIRCRAFT WIND X/Z are the components of the wind velocity, i.e. a
+		// wind from the right blows to the left.
+		Crosswind: round(-windX, 1),
+		Headwind:  round(-windZ, 1),
+		Rating:    Rate(verticalSpeed),
+	}
+	if an.findRunways != nil {
+		report.Runway = matchRunway(an.findRunways(latitude, longitude, runwaySearchRadius), latitude, longitude, heading)
+	}
+	return report
+}
+
+// Rate classifies a landing by its vertical speed at touchdown.
+func Rate(verticalSpeed float64) string {
+	rate := math.Abs(verticalSpeed)
+	switch {
+	case rate < 60:
+		return RatingButter
+	case rate < 240:
+		return RatingSmooth
+	case rate < 600:
+		return RatingFirm
+	}
+	return RatingHard
+}
+
+func round(value float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(value*p) / p
+}
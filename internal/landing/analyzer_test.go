@@ -0,0 +1,185 @@
+package landing
+
+import (
+	"testing"
+	"time"
+
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+var start = time.Date(2021, 9, 15, 14, 30, 0, 0, time.UTC)
+
+// point is a sample at the given number of milliseconds. Samples are 250 ms
+// apart, like with the default data request interval.
+type point struct {
+	ms            int
+	onGround      bool
+	verticalSpeed float64
+	gForce        float64
+}
+
+func air(ms int, verticalSpeed float64) point {
+	return point{ms: ms, verticalSpeed: verticalSpeed, gForce: 1}
+}
+
+func ground(ms int, gForce float64) point {
+	return point{ms: ms, onGround: true, gForce: gForce}
+}
+
+// samples returns the points every 250 ms from the first to the last one, each
+// repeating the previous point until the next one.
+func samples(points ...point) []*telemetry.Sample {
+	result := make([]*telemetry.Sample, 0)
+	for i, p := range points {
+		end := p.ms + 1
+		if i+1 < len(points) {
+			end = points[i+1].ms
+		}
+		for ms := p.ms; ms < end; ms += 250 {
+			sample := telemetry.NewSample(start.Add(time.Duration(ms) * time.Millisecond))
+			sample.Set(monikerOnGround, p.onGround)
+			sample.Set(monikerVerticalSpeed, p.verticalSpeed)
+			sample.Set(monikerGForce, p.gForce)
+			sample.Set(monikerBank, 1.5)
+			sample.Set(monikerPitch, -3.0)
+			sample.Set(monikerGroundSpeed, 110.0)
+			sample.Set(monikerWindX, -5.0)
+			sample.Set(monikerWindZ, 10.0)
+			sample.Set(monikerLatitude, 50.0)
+			sample.Set(monikerLongitude, 8.0)
+			sample.Set(monikerHeading, 90.0)
+			sample.Set(monikerTitle, "Cessna Skyhawk")
+			result = append(result, sample)
+		}
+	}
+	return result
+}
+
+type reportAt struct {
+	report *Report
+	at     time.Duration // when it was sent
+}
+
+func analyze(points ...point) []reportAt {
+	reports := make([]reportAt, 0)
+	var now time.Time
+	an := NewAnalyzer(nil, func(report *Report) {
+		reports = append(reports, reportAt{report: report, at: now.Sub(start)})
+	})
+	for _, sample := range samples(points...) {
+		now = sample.Time
+		an.Consume(sample)
+	}
+	return reports
+}
+
+func TestTouchdown(t *testing.T) {
+	reports := analyze(
+		air(0, -300),
+		air(2000, -180),
+		ground(3000, 1.4),
+		ground(3250, 1.6), // peak within a second
+		ground(3500, 1.2),
+		ground(4500, 2.5), // braking, too late for the touchdown
+		ground(4750, 1.0),
+		ground(10000, 1.0),
+	)
+	if len(reports) != 1 {
+		t.Fatalf("%d reports, want 1", len(reports))
+	}
+	r := reports[0].report
+	if !r.Time.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("touchdown at %s, want 3s", r.Time.Sub(start))
+	}
+	if reports[0].at != 8*time.Second {
+		t.Errorf("report sent at %s, want %s after the touchdown", reports[0].at, bounceWindow)
+	}
+	if r.VerticalSpeed != -180 || r.GForce != 1.6 || r.Bounces != 0 || r.Rating != RatingSmooth {
+		t.Errorf("report %+v, want -180 ft/min, 1.6 G, no bounces, smooth", r)
+	}
+	if r.Bank != 1.5 || r.Pitch != 3 || r.Crosswind != 5 || r.Headwind != -10 || r.Aircraft != "Cessna Skyhawk" {
+		t.Errorf("report %+v", r)
+	}
+	if r.Runway != nil {
+		t.Errorf("runway %+v without a runway finder", r.Runway)
+	}
+}
+
+func TestBounces(t *testing.T) {
+	reports := analyze(
+		air(0, -650),
+		ground(1000, 2.1),
+		air(1500, 200),
+		ground(4000, 1.5), // 3 s after the touchdown
+		air(4500, 100),
+		ground(8500, 1.2), // 4.5 s after the first bounce
+		ground(20000, 1.0),
+	)
+	if len(reports) != 1 {
+		t.Fatalf("%d reports, want 1", len(reports))
+	}
+	r := reports[0].report
+	if r.Bounces != 2 || r.VerticalSpeed != -650 || r.Rating != RatingHard || r.GForce != 2.1 {
+		t.Errorf("report %+v, want 2 bounces of a hard landing with 2.1 G", r)
+	}
+	if want := 8500*time.Millisecond + bounceWindow; reports[0].at != want {
+		t.Errorf("report sent at %s, want %s", reports[0].at, want)
+	}
+}
+
+func TestSeparateLandings(t *testing.T) {
+	reports := analyze(
+		air(0, -200),
+		ground(1000, 1.3),
+		air(2000, 500), // touch and go
+		air(30000, -400),
+		ground(31000, 1.8),
+		ground(40000, 1.0),
+	)
+	if len(reports) != 2 {
+		t.Fatalf("%d reports, want 2", len(reports))
+	}
+	if reports[0].report.Bounces != 0 || reports[1].report.Bounces != 0 || reports[1].report.VerticalSpeed != -400 {
+		t.Errorf("reports %+v, %+v", reports[0].report, reports[1].report)
+	}
+	if reports[0].report.ID == reports[1].report.ID {
+		t.Error("reports have the same ID")
+	}
+}
+
+// An aircraft placed on the ground, e.g. after a teleport or when starting on
+// the runway, has not landed.
+func TestNoTouchdown(t *testing.T) {
+	if reports := analyze(ground(0, 1), ground(10000, 1)); len(reports) != 0 {
+		t.Errorf("%d reports after starting on the ground", len(reports))
+	}
+
+	// Teleported from the air: the last airborne sample is older than
+	// maxAirborneAge.
+	an := NewAnalyzer(nil, func(report *Report) { t.Error("teleport reported as a landing") })
+	all := samples(air(0, -100), ground(5000, 1), ground(15000, 1))
+	an.Consume(all[0])
+	for _, sample := range all[len(all)-30:] {
+		an.Consume(sample)
+	}
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		verticalSpeed float64
+		rating        string
+	}{
+		{verticalSpeed: -59, rating: RatingButter},
+		{verticalSpeed: -60, rating: RatingSmooth},
+		{verticalSpeed: -239, rating: RatingSmooth},
+		{verticalSpeed: -240, rating: RatingFirm},
+		{verticalSpeed: -599, rating: RatingFirm},
+		{verticalSpeed: -600, rating: RatingHard},
+		{verticalSpeed: 50, rating: RatingButter},
+	}
+	for _, test := range tests {
+		if got := Rate(test.verticalSpeed); got != test.rating {
+			t.Errorf("Rate(%g) = %s, want %s", test.verticalSpeed, got, test.rating)
+		}
+	}
+}
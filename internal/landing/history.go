@@ -0,0 +1,156 @@
+package landing
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const historyFile = "landings.jsonl"
+
+// History keeps the most recent reports in memory and appends every report to
+// a JSON lines file. Once the file holds twice as many reports as are kept, it
+// is rewritten with the kept ones only.
+type History struct {
+	path    string
+	size    int
+	reports []*Report // oldest first
+	lines   int       // reports in the file
+	mutex   sync.Mutex
+}
+
+func NewHistory(dir string, size int) (*History, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	h := &History{
+		path:    filepath.Join(dir, historyFile),
+		size:    size,
+		reports: make([]*Report, 0, size),
+	}
+	if err := h.load(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *History) Path() string {
+	return h.path
+}
+
+func (h *History) Add(report *Report) error {
+	buf, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.push(report)
+	if h.lines >= 2*h.size {
+		return h.rewrite()
+	}
+	file, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(buf, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	h.lines++
+	return file.Close()
+}
+
+// rewrite replaces the file with the reports kept in memory.
+func (h *History) rewrite() error {
+	tmp := h.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, report := range h.reports {
+		if err = enc.Encode(report); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if e := file.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(tmp, h.path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	h.lines = len(h.reports)
+	return nil
+}
+
+// Reports returns up to limit reports, newest first. A limit <= 0 returns all
+// reports kept in memory.
+func (h *History) Reports(limit int) []*Report {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	n := len(h.reports)
+	if limit > 0 && limit < n {
+		n = limit
+	}
+	reports := make([]*Report, 0, n)
+	for i := len(h.reports) - 1; i >= 0 && len(reports) < n; i-- {
+		reports = append(reports, h.reports[i])
+	}
+	return reports
+}
+
+func (h *History) Get(id string) (*Report, bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for _, report := range h.reports {
+		if report.ID == id {
+			return report, true
+		}
+	}
+	return nil, false
+}
+
+func (h *History) push(report *Report) {
+	if len(h.reports) >= h.size {
+		h.reports = append(h.reports[:0], h.reports[1:]...)
+	}
+	h.reports = append(h.reports, report)
+}
+
+func (h *History) load() error {
+	file, err := os.Open(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		report := &Report{}
+		if err := json.Unmarshal(scanner.Bytes(), report); err != nil {
+			return fmt.Errorf("%s:%d: %s", h.path, line, err.Error())
+		}
+		h.push(report)
+		h.lines++
+	}
+	return scanner.Err()
+}
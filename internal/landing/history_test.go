@@ -0,0 +1,70 @@
+package landing
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines++
+	}
+	return lines
+}
+
+func TestHistory(t *testing.T) {
+	dir := t.TempDir()
+	h, err := NewHistory(dir, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 6; i++ {
+		if err := h.Add(&Report{ID: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := countLines(t, h.Path()); n != 6 {
+		t.Errorf("file has %d reports, want 6", n)
+	}
+	// The file is rewritten once it holds twice the history size.
+	if err := h.Add(&Report{ID: "7"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := countLines(t, h.Path()); n != 3 {
+		t.Errorf("file has %d reports after rewriting, want 3", n)
+	}
+
+	reports := h.Reports(2)
+	if len(reports) != 2 || reports[0].ID != "7" || reports[1].ID != "6" {
+		t.Errorf("Reports(2) = %v, want 7 and 6", reports)
+	}
+	if _, ok := h.Get("4"); ok {
+		t.Error("report 4 is still kept")
+	}
+
+	// A restart loads the kept reports.
+	h, err = NewHistory(dir, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports = h.Reports(0)
+	if len(reports) != 3 || reports[0].ID != "7" || reports[2].ID != "5" {
+		t.Errorf("reloaded reports %v, want 7, 6 and 5", reports)
+	}
+	if report, ok := h.Get("6"); !ok || report.ID != "6" {
+		t.Error("report 6 not found after reloading")
+	}
+	if _, err := os.Stat(h.Path() + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temporary file left over")
+	}
+}
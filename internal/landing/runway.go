@@ -0,0 +1,71 @@
+package landing
+
+import (
+	"math"
+
+	"msfs2020-gopilot/internal/geo"
+)
+
+// RunwayEnd is one direction of a runway.
+type RunwayEnd struct {
+	Airport            string
+	Ident              string
+	Latitude           float64
+	Longitude          float64
+	Heading            float64 // degrees true
+	DisplacedThreshold float64 // feet
+	Length             float64 // feet
+	Width              float64 // feet
+}
+
+// RunwayFinder returns the runway ends of the airports around a position.
+type RunwayFinder func(latitude, longitude, radius float64) []RunwayEnd
+
+type RunwayInfo struct {
+	Airport               string  `json:"airport"`
+	Ident                 string  `json:"ident"`
+	Heading               float64 `json:"heading"`
+	DistanceFromThreshold float64 `json:"distanceFromThreshold"` // feet
+	CenterlineOffset      float64 `json:"centerlineOffset"`      // feet, positive to the right
+}
+
+const (
+	maxHeadingDifference = 30.0  // degrees
+	maxCenterlineOffset  = 300.0 // feet
+	maxDistanceBefore    = 500.0 // feet before the runway end
+)
+
+// matchRunway finds the runway end the aircraft landed on: the one with the
+// smallest centerline offset among those roughly aligned with the aircraft.
+func matchRunway(ends []RunwayEnd, latitude, longitude, heading float64) *RunwayInfo {
+	var best *RunwayInfo
+	for _, end := range ends {
+		if end.Latitude == 0 && end.Longitude == 0 {
+			continue
+		}
+		diff := math.Abs(geo.NormalizeHeading(heading-end.Heading+180) - 180)
+		if diff > maxHeadingDifference {
+			continue
+		}
+		along, across := geo.AlongAcross(end.Latitude, end.Longitude, end.Heading, latitude, longitude)
+		alongFt := geo.MetersToFeet(along)
+		acrossFt := geo.MetersToFeet(across)
+		if math.Abs(acrossFt) > maxCenterlineOffset || alongFt < -maxDistanceBefore {
+			continue
+		}
+		if end.Length > 0 && alongFt > end.Length {
+			continue
+		}
+		if best != nil && math.Abs(acrossFt) >= math.Abs(best.CenterlineOffset) {
+			continue
+		}
+		best = &RunwayInfo{
+			Airport:               end.Airport,
+			Ident:                 end.Ident,
+			Heading:               end.Heading,
+			DistanceFromThreshold: math.Round(alongFt - end.DisplacedThreshold),
+			CenterlineOffset:      math.Round(acrossFt*10) / 10,
+		}
+	}
+	return best
+}
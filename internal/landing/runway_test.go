@@ -0,0 +1,92 @@
+package landing
+
+import (
+	"math"
+	"testing"
+
+	"msfs2020-gopilot/internal/geo"
+)
+
+// offset returns the position the given distances (in feet) east and north of
+// a position.
+func offset(lat, lon, east, north float64) (float64, float64) {
+	metersPerDegree := geo.EarthRadius * math.Pi / 180
+	return lat + geo.FeetToMeters(north)/metersPerDegree,
+		lon + geo.FeetToMeters(east)/(metersPerDegree*math.Cos(geo.DegToRad(lat)))
+}
+
+func TestMatchRunway(t *testing.T) {
+	// Runway 09/27, 10000 ft long, along a parallel.
+	lat09, lon09 := 50.0, 8.0
+	lat27, lon27 := offset(lat09, lon09, 10000, 0)
+	ends := []RunwayEnd{
+		{Airport: "EXXX", Ident: "09", Latitude: lat09, Longitude: lon09, Heading: 90, DisplacedThreshold: 300, Length: 10000},
+		{Airport: "EXXX", Ident: "27", Latitude: lat27, Longitude: lon27, Heading: 270, Length: 10000},
+		// Parallel runway 09R, 700 ft south.
+		{Airport: "EXXX", Ident: "09R", Latitude: lat09 - 700.0/364000, Longitude: lon09, Heading: 90, Length: 10000},
+		{Airport: "EXXX", Ident: "00", Heading: 90},
+	}
+
+	tests := []struct {
+		name       string
+		east       float64
+		north      float64
+		heading    float64
+		ident      string
+		threshold  float64
+		centerline float64
+	}{
+		{name: "09, right of the centerline", east: 2000, north: -20, heading: 92, ident: "09", threshold: 1700, centerline: 20},
+		{name: "09, left of the centerline", east: 1500, north: 30, heading: 88, ident: "09", threshold: 1200, centerline: -30},
+		{name: "before the displaced threshold", east: 100, heading: 90, ident: "09", threshold: -200, centerline: 0},
+		{name: "27", east: 8000, north: 10, heading: 271, ident: "27", threshold: 2000, centerline: 10},
+		{name: "09R", east: 3000, north: -650, heading: 90, ident: "09R"},
+		{name: "crosswise", east: 2000, heading: 180},
+		{name: "beside the runway", east: 2000, north: 350, heading: 90},
+		{name: "short of the runway", east: -600, heading: 90},
+		{name: "past the runway end", east: 10100, heading: 90},
+	}
+	for _, test := range tests {
+		lat, lon := offset(lat09, lon09, test.east, test.north)
+		info := matchRunway(ends, lat, lon, test.heading)
+		if test.ident == "" {
+			if info != nil {
+				t.Errorf("%s: matched %+v", test.name, info)
+			}
+			continue
+		}
+		if info == nil || info.Ident != test.ident {
+			t.Errorf("%s: matched %+v, want %s", test.name, info, test.ident)
+			continue
+		}
+		if test.ident == "09R" {
+			continue
+		}
+		if math.Abs(info.DistanceFromThreshold-test.threshold) > 2 || math.Abs(info.CenterlineOffset-test.centerline) > 1 {
+			t.Errorf("%s: %.0f ft from the threshold, %.1f ft from the centerline, want %.0f and %.1f",
+				test.name, info.DistanceFromThreshold, info.CenterlineOffset, test.threshold, test.centerline)
+		}
+	}
+}
+
+func TestAnalyzerFindsRunway(t *testing.T) {
+	lat, lon := 50.0, 7.99
+	var radius float64
+	an := NewAnalyzer(func(latitude, longitude, r float64) []RunwayEnd {
+		radius = r
+		return []RunwayEnd{{Airport: "EXXX", Ident: "09", Latitude: lat, Longitude: lon, Heading: 90, Length: 10000}}
+	}, nil)
+	all := samples(air(0, -120), ground(1000, 1.2))
+	an.Consume(all[0])
+	an.Consume(all[len(all)-1])
+	if an.pending == nil || an.pending.Runway == nil || an.pending.Runway.Ident != "09" {
+		t.Fatalf("pending report %+v, want one on runway 09", an.pending)
+	}
+	// 0.01° of longitude at 50° N.
+	if d := an.pending.Runway.DistanceFromThreshold; math.Abs(d-2350) > 5 {
+		t.Errorf("%.0f ft from the threshold, want 2350", d)
+	}
+	if radius != runwaySearchRadius {
+		t.Errorf("searched within %g m", radius)
+	}
+}
@@ -0,0 +1,190 @@
+package approach
+
+import (
+	"math"
+	"time"
+
+	"msfs2020-gopilot/internal/maneuvers"
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+const (
+	Maneuver = "stabilized_approach"
+
+	PhaseFinal = "final"
+
+	monikerHeight        = "height"
+	monikerAirspeed      = "airspeed"
+	monikerVerticalSpeed = "verticalSpeed"
+	monikerBank          = "bank"
+	monikerGear          = "gearDown"
+	monikerOnGround      = "onGround"
+)
+
+// DefaultSpec uses common stabilized approach criteria for light aircraft in
+// VMC: from 500 ft above ground the aircraft has to be on speed (Vref -5/+10),
+// descending at a normal rate, wings level and configured for landing.
+func DefaultSpec() *maneuvers.Spec {
+	return &maneuvers.Spec{
+		Name:        Maneuver,
+		Title:       "Stabilized Approach",
+		Description: "Final approach from the stabilization gate down to the flare",
+		Parameters: map[string]float64{
+			"gate_height":       500, // feet above ground
+			"end_height":        50,  // feet above ground
+			"vref":              65,  // knots
+			"target_sink_rate":  700, // feet per minute
+			"go_around_climb":   100, // feet above the gate at which the approach counts as abandoned
+			"gear_check":        1,   // 0 for aircraft with fixed gear
+			"min_approach_time": 10,  // seconds below the gate for a complete approach
+		},
+		Tolerances: map[string]maneuvers.Tolerance{
+			"airspeed":  {Unit: "knots", Below: 5, Above: 10},
+			"sink_rate": {Unit: "ft/min", Below: 500, Above: 300},
+			"bank":      maneuvers.Symmetric(10, "degrees"),
+			"gear":      {Unit: "", Below: 0, Above: 0},
+		},
+	}
+}
+
+type Details struct {
+	Vref       float64 `json:"vref"`
+	GateHeight float64 `json:"gateHeight"`
+	Height     float64 `json:"height"`
+}
+
+type attempt struct {
+	startedAt time.Time
+	airspeed  *maneuvers.Tracker
+	sinkRate  *maneuvers.Tracker
+	bank      *maneuvers.Tracker
+	gear      *maneuvers.Tracker
+	trace     *maneuvers.Trace
+}
+
+// Evaluator starts grading when the aircraft descends through the gate height
+// and stops at the end height or on touchdown. Climbing back above the gate
+// aborts the approach.
+type Evaluator struct {
+	spec        *maneuvers.Spec
+	gateHeight  float64
+	endHeight   float64
+	vref        float64
+	sinkRate    float64
+	goAround    float64
+	gearCheck   bool
+	minDuration time.Duration
+	lastHeight  float64
+	attempt     *attempt
+}
+
+func New(spec *maneuvers.Spec) maneuvers.Evaluator {
+	return &Evaluator{
+		spec:        spec,
+		gateHeight:  spec.Param("gate_height"),
+		endHeight:   spec.Param("end_height"),
+		vref:        spec.Param("vref"),
+		sinkRate:    spec.Param("target_sink_rate"),
+		goAround:    spec.Param("gate_height") + spec.Param("go_around_climb"),
+		gearCheck:   spec.Param("gear_check") != 0,
+		minDuration: time.Duration(spec.Param("min_approach_time") * float64(time.Second)),
+		lastHeight:  math.NaN(),
+	}
+}
+
+func (ev *Evaluator) Vars() []telemetry.Var {
+	return []telemetry.Var{
+		{Name: "PLANE ALT ABOVE GROUND", Unit: "feet", Type: "float64", Moniker: monikerHeight},
+		{Name: "AIRSPEED INDICATED", Unit: "knot", Type: "float64", Moniker: monikerAirspeed},
+		{Name: "VERTICAL SPEED", Unit: "ft/min", Type: "float64", Moniker: monikerVerticalSpeed},
+		{Name: "PLANE BANK DEGREES", Unit: "degrees", Type: "float64", Moniker: monikerBank},
+		{Name: "GEAR HANDLE POSITION", Unit: "bool", Type: "int32", Moniker: monikerGear},
+		{Name: "SIM ON GROUND", Unit: "bool", Type: "int32", Moniker: monikerOnGround},
+	}
+}
+
+func (ev *Evaluator) Reset() {
+	ev.attempt = nil
+	ev.lastHeight = math.NaN()
+}
+
+func (ev *Evaluator) Evaluate(sample *telemetry.Sample) *maneuvers.Evaluation {
+	if !sample.Has(monikerHeight, monikerAirspeed, monikerVerticalSpeed, monikerBank) {
+		return nil
+	}
+	height, _ := sample.Float(monikerHeight)
+	airspeed, _ := sample.Float(monikerAirspeed)
+	verticalSpeed, _ := sample.Float(monikerVerticalSpeed)
+	bank, _ := sample.Float(monikerBank)
+	gearDown, _ := sample.Bool(monikerGear)
+	onGround, _ := sample.Bool(monikerOnGround)
+
+	lastHeight := ev.lastHeight
+	ev.lastHeight = height
+
+	if ev.attempt == nil {
+		crossedGate := lastHeight > ev.gateHeight && height <= ev.gateHeight
+		if !crossedGate || onGround || verticalSpeed >= 0 {
+			return nil
+		}
+		ev.attempt = &attempt{
+			startedAt: sample.Time,
+			airspeed:  maneuvers.NewTracker(ev.spec, "airspeed"),
+			sinkRate:  maneuvers.NewTracker(ev.spec, "sink_rate"),
+			bank:      maneuvers.NewTracker(ev.spec, "bank"),
+			gear:      maneuvers.NewTracker(ev.spec, "gear"),
+			trace:     maneuvers.NewTrace(),
+		}
+	}
+
+	a := ev.attempt
+	if height > ev.goAround {
+		ev.attempt = nil
+		return &maneuvers.Evaluation{Phase: maneuvers.PhaseAborted, Details: ev.details(height)}
+	}
+	if onGround || height <= ev.endHeight {
+		ev.attempt = nil
+		criteria := []maneuvers.Criterion{
+			a.airspeed.Criterion(),
+			a.sinkRate.Criterion(),
+			a.bank.Criterion(),
+		}
+		if ev.gearCheck {
+			criteria = append(criteria, a.gear.Criterion())
+		}
+		complete := sample.Time.Sub(a.startedAt) >= ev.minDuration
+		result := maneuvers.NewResult(Maneuver, a.startedAt, sample.Time, complete, criteria)
+		result.Details = ev.details(height)
+		result.Trace = a.trace.Points()
+		return &maneuvers.Evaluation{Phase: maneuvers.PhaseCompleted, Details: result.Details, Result: result}
+	}
+
+	deviations := []maneuvers.Deviation{
+		a.airspeed.Add(airspeed - ev.vref),
+		a.sinkRate.Add(-verticalSpeed - ev.sinkRate),
+		a.bank.Add(bank),
+	}
+	gear := 0.0
+	if !gearDown {
+		gear = 1
+	}
+	if ev.gearCheck {
+		deviations = append(deviations, a.gear.Add(gear))
+	}
+	a.trace.Add(maneuvers.TracePoint{
+		"t":                  maneuvers.Round(sample.Time.Sub(a.startedAt).Seconds(), 2),
+		monikerHeight:        maneuvers.Round(height, 1),
+		monikerAirspeed:      maneuvers.Round(airspeed, 1),
+		monikerVerticalSpeed: maneuvers.Round(verticalSpeed, 0),
+		monikerBank:          maneuvers.Round(bank, 1),
+	})
+	return &maneuvers.Evaluation{Phase: PhaseFinal, Deviations: deviations, Details: ev.details(height)}
+}
+
+func (ev *Evaluator) details(height float64) *Details {
+	return &Details{
+		Vref:       ev.vref,
+		GateHeight: ev.gateHeight,
+		Height:     maneuvers.Round(height, 1),
+	}
+}
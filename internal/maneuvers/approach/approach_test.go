@@ -0,0 +1,113 @@
+package approach
+
+import (
+	"testing"
+
+	"msfs2020-gopilot/internal/maneuvers/maneuvertest"
+)
+
+func final(change func(s *maneuvertest.State)) *maneuvertest.Flight {
+	state := maneuvertest.State{Altitude: 1200, Height: 800, Airspeed: 65, VerticalSpeed: -700, Heading: 250, GearDown: true}
+	if change != nil {
+		change(&state)
+	}
+	return maneuvertest.NewFlight(state)
+}
+
+func TestStabilizedApproach(t *testing.T) {
+	// 750 ft at 700 ft/min down to the end height.
+	flight := final(nil).Hold(65)
+	phases, results := maneuvertest.Evaluate(New(DefaultSpec()), flight.Samples)
+
+	if want := []string{PhaseFinal, "completed"}; !maneuvertest.EqualStrings(phases, want) {
+		t.Errorf("phases %v, want %v", phases, want)
+	}
+	if len(results) != 1 {
+		t.Fatalf("%d results, want 1", len(results))
+	}
+	result := results[0]
+	if !result.Complete || !result.Passed || result.Score != 100 || len(result.Criteria) != 4 {
+		t.Errorf("result complete: %t, passed: %t, score %g, criteria %+v", result.Complete, result.Passed, result.Score, result.Criteria)
+	}
+	// From 500 ft down to 50 ft.
+	if result.Duration < 38 || result.Duration > 39 {
+		t.Errorf("duration %g s, want 38.6", result.Duration)
+	}
+}
+
+func TestUnstabilizedApproach(t *testing.T) {
+	tests := []struct {
+		name     string
+		flight   *maneuvertest.Flight
+		complete bool
+		failed   string
+	}{
+		{
+			name:     "gear up",
+			flight:   final(func(s *maneuvertest.State) { s.GearDown = false }).Hold(65),
+			complete: true,
+			failed:   "gear",
+		},
+		{
+			name:     "fast",
+			flight:   final(func(s *maneuvertest.State) { s.Airspeed = 80 }).Hold(65),
+			complete: true,
+			failed:   "airspeed",
+		},
+		{
+			name:     "steep",
+			flight:   final(func(s *maneuvertest.State) { s.VerticalSpeed = -1200 }).Hold(40),
+			complete: true,
+			failed:   "sink_rate",
+		},
+		{
+			name:     "banked",
+			flight:   final(nil).Hold(30).Roll(2, -15).Roll(2, 0).Hold(35),
+			complete: true,
+			failed:   "bank",
+		},
+		{
+			name:   "dive",
+			flight: final(func(s *maneuvertest.State) { s.VerticalSpeed = -3000 }).Hold(20),
+			failed: "sink_rate",
+		},
+	}
+	for _, test := range tests {
+		_, results := maneuvertest.Evaluate(New(DefaultSpec()), test.flight.Samples)
+		if len(results) != 1 {
+			t.Errorf("%s: %d results, want 1", test.name, len(results))
+			continue
+		}
+		result := results[0]
+		if result.Passed || result.Complete != test.complete {
+			t.Errorf("%s: result complete: %t, passed: %t, want failed and complete: %t", test.name, result.Complete, result.Passed, test.complete)
+		}
+		if maneuvertest.Criterion(result, test.failed).Passed {
+			t.Errorf("%s: %s passed: %+v", test.name, test.failed, result.Criteria)
+		}
+	}
+}
+
+func TestGoAround(t *testing.T) {
+	flight := final(nil).Hold(30).Set(func(s *maneuvertest.State) {
+		s.VerticalSpeed = 800
+	}).Hold(30)
+	phases, results := maneuvertest.Evaluate(New(DefaultSpec()), flight.Samples)
+	if want := []string{PhaseFinal, "aborted"}; !maneuvertest.EqualStrings(phases, want) || len(results) != 0 {
+		t.Errorf("phases %v, %d results, want %v without a result", phases, len(results), want)
+	}
+}
+
+// The aircraft has to descend through the gate; starting below it or a
+// low pass which never gets down to the gate is no approach.
+func TestNoApproach(t *testing.T) {
+	flights := []*maneuvertest.Flight{
+		final(func(s *maneuvertest.State) { s.Height = 400 }).Hold(30),
+		final(func(s *maneuvertest.State) { s.VerticalSpeed = 0 }).Hold(30),
+	}
+	for i, flight := range flights {
+		if phases, _ := maneuvertest.Evaluate(New(DefaultSpec()), flight.Samples); len(phases) != 0 {
+			t.Errorf("flight %d: phases %v", i, phases)
+		}
+	}
+}
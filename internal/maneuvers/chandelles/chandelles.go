@@ -0,0 +1,250 @@
+package chandelles
+
+import (
+	"math"
+	"time"
+
+	"msfs2020-gopilot/internal/maneuvers"
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+const (
+	Maneuver = "chandelle"
+
+	PhaseFirstHalf  = "first_half"
+	PhaseSecondHalf = "second_half"
+
+	DirectionLeft  = "left"
+	DirectionRight = "right"
+
+	monikerAltitude     = "altitude"
+	monikerAirspeed     = "airspeed"
+	monikerBank         = "bank"
+	monikerHeading      = "heading"
+	monikerStallWarning = "stallWarning"
+)
+
+// DefaultSpec uses the tolerances of the FAA Commercial Pilot ACS (Task V.B,
+// Chandelles). The rollout airspeed is just above the stall speed of the
+// aircraft and should be set in the tolerance file.
+func DefaultSpec() *maneuvers.Spec {
+	return &maneuvers.Spec{
+		Name:        Maneuver,
+		Title:       "Chandelles",
+		Description: "Maximum performance climbing turn of 180° at 30° bank, rolling out just above the stall speed",
+		Parameters: map[string]float64{
+			"target_bank":       30,  // degrees during the first 90° of the turn
+			"entry_bank":        15,  // degrees; rolling past this from wings level starts an attempt
+			"wings_level_bank":  5,   // degrees
+			"min_turn_angle":    150, // degrees; shorter turns are incomplete
+			"min_altitude_gain": 100, // feet; turns which gain less are incomplete
+			"rollout_airspeed":  60,  // knots
+		},
+		Tolerances: map[string]maneuvers.Tolerance{
+			"bank":             maneuvers.Symmetric(5, "degrees"),
+			"rollout_heading":  maneuvers.Symmetric(10, "degrees"),
+			"rollout_airspeed": {Unit: "knots", Below: 5, Above: 10},
+			"stall_warning":    {Unit: "", Below: 0, Above: 0},
+		},
+	}
+}
+
+type Reference struct {
+	Altitude float64 `json:"altitude"`
+	Airspeed float64 `json:"airspeed"`
+	Heading  float64 `json:"heading"`
+}
+
+type Details struct {
+	Direction    string    `json:"direction"`
+	Entry        Reference `json:"entry"`
+	TargetBank   float64   `json:"targetBank"`
+	TurnAngle    float64   `json:"turnAngle"`
+	AltitudeGain float64   `json:"altitudeGain"`
+}
+
+type attempt struct {
+	startedAt       time.Time
+	direction       float64 // -1: right, +1: left
+	entry           Reference
+	lastHeading     float64
+	turnAngle       float64
+	maxAltitude     float64
+	established     bool
+	bank            *maneuvers.Tracker
+	rolloutHeading  *maneuvers.Tracker
+	rolloutAirspeed *maneuvers.Tracker
+	stallWarning    *maneuvers.Tracker
+	trace           *maneuvers.Trace
+}
+
+// Evaluator detects chandelles like steep turns: the last wings-level sample
+// before the aircraft rolls into the turn is the reference, and the attempt ends
+// when the wings are level again. The bank is graded in the first 90° of the
+// turn, heading and airspeed at the rollout.
+type Evaluator struct {
+	spec            *maneuvers.Spec
+	targetBank      float64
+	entryBank       float64
+	establishedBank float64
+	wingsLevelBank  float64
+	minTurnAngle    float64
+	minGain         float64
+	rolloutAirspeed float64
+	attempt         *attempt
+	wingsLevel      *telemetry.Sample
+}
+
+func New(spec *maneuvers.Spec) maneuvers.Evaluator {
+	return &Evaluator{
+		spec:            spec,
+		targetBank:      spec.Param("target_bank"),
+		entryBank:       spec.Param("entry_bank"),
+		establishedBank: spec.Param("target_bank") - spec.Tolerance("bank").Below,
+		wingsLevelBank:  spec.Param("wings_level_bank"),
+		minTurnAngle:    spec.Param("min_turn_angle"),
+		minGain:         spec.Param("min_altitude_gain"),
+		rolloutAirspeed: spec.Param("rollout_airspeed"),
+	}
+}
+
+func (ev *Evaluator) Vars() []telemetry.Var {
+	return []telemetry.Var{
+		{Name: "INDICATED ALTITUDE", Unit: "feet", Type: "float64", Moniker: monikerAltitude},
+		{Name: "AIRSPEED INDICATED", Unit: "knot", Type: "float64", Moniker: monikerAirspeed},
+		{Name: "PLANE BANK DEGREES", Unit: "degrees", Type: "float64", Moniker: monikerBank},
+		{Name: "PLANE HEADING DEGREES MAGNETIC", Unit: "degrees", Type: "float64", Moniker: monikerHeading},
+		{Name: "STALL WARNING", Unit: "bool", Type: "int32", Moniker: monikerStallWarning},
+	}
+}
+
+func (ev *Evaluator) Reset() {
+	ev.attempt = nil
+	ev.wingsLevel = nil
+}
+
+func (ev *Evaluator) Evaluate(sample *telemetry.Sample) *maneuvers.Evaluation {
+	if !sample.Has(monikerAltitude, monikerAirspeed, monikerBank, monikerHeading) {
+		return nil
+	}
+	altitude, _ := sample.Float(monikerAltitude)
+	airspeed, _ := sample.Float(monikerAirspeed)
+	bank, _ := sample.Float(monikerBank)
+	heading, _ := sample.Float(monikerHeading)
+	stallWarning, _ := sample.Bool(monikerStallWarning)
+	absBank := math.Abs(bank)
+
+	if ev.attempt == nil {
+		if absBank < ev.wingsLevelBank {
+			ev.wingsLevel = sample
+			return nil
+		}
+		if absBank < ev.entryBank || ev.wingsLevel == nil {
+			return nil
+		}
+		ev.start(bank)
+	}
+
+	a := ev.attempt
+	a.turnAngle += a.direction * maneuvers.HeadingDelta(a.lastHeading, heading)
+	a.lastHeading = heading
+	a.maxAltitude = math.Max(a.maxAltitude, altitude)
+
+	if absBank < ev.wingsLevelBank {
+		result := ev.complete(sample.Time, airspeed)
+		ev.wingsLevel = sample
+		return &maneuvers.Evaluation{Phase: maneuvers.PhaseCompleted, Details: result.Details, Result: result}
+	}
+
+	phase := PhaseFirstHalf
+	if a.turnAngle >= 90 {
+		phase = PhaseSecondHalf
+	}
+	if !a.established && absBank >= ev.establishedBank && a.direction*bank > 0 {
+		a.established = true
+	}
+	warning := 0.0
+	if stallWarning {
+		warning = 1
+	}
+	deviations := []maneuvers.Deviation{a.stallWarning.Add(warning)}
+	// The bank is held constant in the first half and reduced while rolling
+	// out in the second half.
+	if phase == PhaseFirstHalf && a.established {
+		deviations = append(deviations, a.bank.Add(absBank-ev.targetBank))
+	} else {
+		deviations = append(deviations, a.bank.Deviation(absBank-ev.targetBank))
+	}
+	if phase == PhaseSecondHalf {
+		deviations = append(deviations,
+			a.rolloutHeading.Deviation(a.turnAngle-180),
+			a.rolloutAirspeed.Deviation(airspeed-ev.rolloutAirspeed),
+		)
+	}
+	a.trace.Add(maneuvers.TracePoint{
+		"t":                 maneuvers.Round(sample.Time.Sub(a.startedAt).Seconds(), 2),
+		monikerAltitude:     maneuvers.Round(altitude, 1),
+		monikerAirspeed:     maneuvers.Round(airspeed, 1),
+		monikerBank:         maneuvers.Round(bank, 1),
+		monikerHeading:      maneuvers.Round(heading, 1),
+		monikerStallWarning: warning,
+	})
+	return &maneuvers.Evaluation{Phase: phase, Deviations: deviations, Details: a.details(ev.targetBank)}
+}
+
+func (ev *Evaluator) start(bank float64) {
+	ref := ev.wingsLevel
+	altitude, _ := ref.Float(monikerAltitude)
+	airspeed, _ := ref.Float(monikerAirspeed)
+	heading, _ := ref.Float(monikerHeading)
+	direction := 1.0
+	if bank < 0 {
+		direction = -1.0
+	}
+	ev.attempt = &attempt{
+		startedAt:       ref.Time,
+		direction:       direction,
+		entry:           Reference{Altitude: altitude, Airspeed: airspeed, Heading: heading},
+		lastHeading:     heading,
+		maxAltitude:     altitude,
+		bank:            maneuvers.NewTracker(ev.spec, "bank"),
+		rolloutHeading:  maneuvers.NewTracker(ev.spec, "rollout_heading"),
+		rolloutAirspeed: maneuvers.NewTracker(ev.spec, "rollout_airspeed"),
+		stallWarning:    maneuvers.NewTracker(ev.spec, "stall_warning"),
+		trace:           maneuvers.NewTrace(),
+	}
+}
+
+func (ev *Evaluator) complete(t time.Time, airspeed float64) *maneuvers.Result {
+	a := ev.attempt
+	ev.attempt = nil
+
+	// Positive rollout deviations are overshoots, negative ones undershoots.
+	a.rolloutHeading.Add(a.turnAngle - 180)
+	a.rolloutAirspeed.Add(airspeed - ev.rolloutAirspeed)
+	criteria := []maneuvers.Criterion{
+		a.bank.Criterion(),
+		a.rolloutHeading.Criterion(),
+		a.rolloutAirspeed.Criterion(),
+		a.stallWarning.Criterion(),
+	}
+	complete := a.established && a.turnAngle >= ev.minTurnAngle && a.maxAltitude-a.entry.Altitude >= ev.minGain
+	result := maneuvers.NewResult(Maneuver, a.startedAt, t, complete, criteria)
+	result.Details = a.details(ev.targetBank)
+	result.Trace = a.trace.Points()
+	return result
+}
+
+func (a *attempt) details(targetBank float64) *Details {
+	direction := DirectionLeft
+	if a.direction < 0 {
+		direction = DirectionRight
+	}
+	return &Details{
+		Direction:    direction,
+		Entry:        a.entry,
+		TargetBank:   targetBank,
+		TurnAngle:    maneuvers.Round(a.turnAngle, 1),
+		AltitudeGain: maneuvers.Round(a.maxAltitude-a.entry.Altitude, 1),
+	}
+}
@@ -0,0 +1,101 @@
+package chandelles
+
+import (
+	"math"
+	"testing"
+
+	"msfs2020-gopilot/internal/geo"
+	"msfs2020-gopilot/internal/maneuvers/maneuvertest"
+)
+
+// technique describes how a chandelle to the left from heading 090 is flown.
+type technique struct {
+	bank          float64 // in the first half
+	rolloutAt     float64 // degrees of turn at which the wings would be level
+	endAirspeed   float64 // knots at 180°
+	verticalSpeed float64 // ft/min
+	stallWarning  bool    // in the second half
+}
+
+func (tq technique) fly() *maneuvertest.Flight {
+	flight := maneuvertest.NewFlight(maneuvertest.State{Altitude: 3000, Airspeed: 100, Heading: 90}).Hold(3)
+	return flight.Fly(60, func(s *maneuvertest.State, elapsed float64) {
+		turned := geo.NormalizeHeading(90 - s.Heading)
+		if turned > 270 {
+			turned = 0 // not yet turning
+		}
+		s.VerticalSpeed = tq.verticalSpeed
+		s.Airspeed = 100 - (100-tq.endAirspeed)*math.Min(turned, 180)/180
+		s.StallWarning = tq.stallWarning && turned > 120 && turned < 150
+		switch {
+		case elapsed <= 3:
+			s.Bank = tq.bank * elapsed / 3
+		case turned < 90:
+			s.Bank = tq.bank
+		default:
+			// The bank is reduced until the wings are level at rolloutAt.
+			s.Bank = math.Max(0, math.Min(tq.bank, tq.bank*(tq.rolloutAt-turned)/(tq.rolloutAt-90)))
+		}
+	})
+}
+
+var good = technique{bank: 30, rolloutAt: 195, endAirspeed: 62, verticalSpeed: 800}
+
+func TestChandelle(t *testing.T) {
+	phases, results := maneuvertest.Evaluate(New(DefaultSpec()), good.fly().Samples)
+
+	if want := []string{PhaseFirstHalf, PhaseSecondHalf, "completed"}; !maneuvertest.EqualStrings(phases, want) {
+		t.Errorf("phases %v, want %v", phases, want)
+	}
+	if len(results) != 1 {
+		t.Fatalf("%d results, want 1", len(results))
+	}
+	result := results[0]
+	if !result.Complete || !result.Passed {
+		t.Errorf("result complete: %t, passed: %t, criteria %+v, want a passed chandelle", result.Complete, result.Passed, result.Criteria)
+	}
+	details := result.Details.(*Details)
+	if details.Direction != DirectionLeft || details.TurnAngle < 170 || details.TurnAngle > 190 || details.AltitudeGain < 200 {
+		t.Errorf("details %+v", details)
+	}
+}
+
+func TestChandelleFailed(t *testing.T) {
+	steep := good
+	steep.bank = 40
+	fast := good
+	fast.endAirspeed = 80
+	overshoot := good
+	overshoot.rolloutAt = 220
+	stall := good
+	stall.stallWarning = true
+	level := good
+	level.verticalSpeed = 0
+
+	tests := []struct {
+		name      string
+		technique technique
+		complete  bool
+		failed    string
+	}{
+		{name: "steep", technique: steep, complete: true, failed: "bank"},
+		{name: "fast", technique: fast, complete: true, failed: "rollout_airspeed"},
+		{name: "overshoot", technique: overshoot, complete: true, failed: "rollout_heading"},
+		{name: "stall", technique: stall, complete: true, failed: "stall_warning"},
+		{name: "level turn", technique: level},
+	}
+	for _, test := range tests {
+		_, results := maneuvertest.Evaluate(New(DefaultSpec()), test.technique.fly().Samples)
+		if len(results) != 1 {
+			t.Errorf("%s: %d results, want 1", test.name, len(results))
+			continue
+		}
+		result := results[0]
+		if result.Passed || result.Complete != test.complete {
+			t.Errorf("%s: result complete: %t, passed: %t, want failed and complete: %t", test.name, result.Complete, result.Passed, test.complete)
+		}
+		if test.failed != "" && maneuvertest.Criterion(result, test.failed).Passed {
+			t.Errorf("%s: %s passed: %+v", test.name, test.failed, result.Criteria)
+		}
+	}
+}
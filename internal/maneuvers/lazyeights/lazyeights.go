@@ -0,0 +1,259 @@
+package lazyeights
+
+import (
+	"math"
+	"time"
+
+	"msfs2020-gopilot/internal/maneuvers"
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+const (
+	Maneuver = "lazy_eight"
+
+	PhaseFirstTurn  = "first_turn"
+	PhaseReversal   = "reversal"
+	PhaseSecondTurn = "second_turn"
+
+	DirectionLeft  = "left"
+	DirectionRight = "right"
+
+	monikerAltitude = "altitude"
+	monikerAirspeed = "airspeed"
+	monikerBank     = "bank"
+	monikerHeading  = "heading"
+)
+
+// DefaultSpec uses the tolerances of the FAA Commercial Pilot ACS (Task V.D,
+// Lazy Eights): at the 180° points the aircraft is back at the entry altitude
+// ±100 ft and airspeed ±10 kt on the reciprocal (or entry) heading ±10°, with a
+// maximum bank of about 30° at the 90° points.
+func DefaultSpec() *maneuvers.Spec {
+	return &maneuvers.Spec{
+		Name:        Maneuver,
+		Title:       "Lazy Eights",
+		Description: "Two 180° climbing and descending turns in opposite directions",
+		Parameters: map[string]float64{
+			"target_bank":       30,  // degrees; maximum bank of each turn
+			"entry_bank":        10,  // degrees; rolling past this from wings level starts a turn
+			"wings_level_bank":  5,   // degrees; the 180° points
+			"min_turn_angle":    150, // degrees per turn; shorter turns end the attempt
+			"max_reversal_time": 10,  // seconds with the wings level between the turns
+		},
+		Tolerances: map[string]maneuvers.Tolerance{
+			"altitude": maneuvers.Symmetric(100, "feet"),
+			"airspeed": maneuvers.Symmetric(10, "knots"),
+			"heading":  maneuvers.Symmetric(10, "degrees"),
+			"max_bank": maneuvers.Symmetric(5, "degrees"),
+		},
+	}
+}
+
+type Reference struct {
+	Altitude float64 `json:"altitude"`
+	Airspeed float64 `json:"airspeed"`
+	Heading  float64 `json:"heading"`
+}
+
+type Details struct {
+	Direction  string    `json:"direction"` // of the current turn
+	Entry      Reference `json:"entry"`
+	TargetBank float64   `json:"targetBank"`
+	Turns      int       `json:"turns"` // completed
+	TurnAngle  float64   `json:"turnAngle"`
+	MaxBank    float64   `json:"maxBank"`
+}
+
+type attempt struct {
+	startedAt     time.Time
+	entry         Reference
+	direction     float64 // of the current turn; -1: right, +1: left
+	lastHeading   float64
+	turnAngle     float64
+	maxBank       float64
+	turns         int
+	reversalSince time.Time // zero while turning
+	altitude      *maneuvers.Tracker
+	airspeed      *maneuvers.Tracker
+	heading       *maneuvers.Tracker
+	maxBankDev    *maneuvers.Tracker
+	trace         *maneuvers.Trace
+}
+
+// Evaluator takes the last wings-level sample before the first turn as the
+// reference. Altitude, airspeed, heading and the maximum bank are graded at the
+// end of each turn, when the wings pass through level. The second turn has to
+// start in the opposite direction within max_reversal_time.
+type Evaluator struct {
+	spec           *maneuvers.Spec
+	targetBank     float64
+	entryBank      float64
+	wingsLevelBank float64
+	minTurnAngle   float64
+	maxReversal    time.Duration
+	attempt        *attempt
+	wingsLevel     *telemetry.Sample
+}
+
+func New(spec *maneuvers.Spec) maneuvers.Evaluator {
+	return &Evaluator{
+		spec:           spec,
+		targetBank:     spec.Param("target_bank"),
+		entryBank:      spec.Param("entry_bank"),
+		wingsLevelBank: spec.Param("wings_level_bank"),
+		minTurnAngle:   spec.Param("min_turn_angle"),
+		maxReversal:    time.Duration(spec.Param("max_reversal_time") * float64(time.Second)),
+	}
+}
+
+func (ev *Evaluator) Vars() []telemetry.Var {
+	return []telemetry.Var{
+		{Name: "INDICATED ALTITUDE", Unit: "feet", Type: "float64", Moniker: monikerAltitude},
+		{Name: "AIRSPEED INDICATED", Unit: "knot", Type: "float64", Moniker: monikerAirspeed},
+		{Name: "PLANE BANK DEGREES", Unit: "degrees", Type: "float64", Moniker: monikerBank},
+		{Name: "PLANE HEADING DEGREES MAGNETIC", Unit: "degrees", Type: "float64", Moniker: monikerHeading},
+	}
+}
+
+func (ev *Evaluator) Reset() {
+	ev.attempt = nil
+	ev.wingsLevel = nil
+}
+
+func (ev *Evaluator) Evaluate(sample *telemetry.Sample) *maneuvers.Evaluation {
+	if !sample.Has(monikerAltitude, monikerAirspeed, monikerBank, monikerHeading) {
+		return nil
+	}
+	altitude, _ := sample.Float(monikerAltitude)
+	airspeed, _ := sample.Float(monikerAirspeed)
+	bank, _ := sample.Float(monikerBank)
+	heading, _ := sample.Float(monikerHeading)
+	absBank := math.Abs(bank)
+	direction := 1.0
+	if bank < 0 {
+		direction = -1.0
+	}
+
+	if ev.attempt == nil {
+		if absBank < ev.wingsLevelBank {
+			ev.wingsLevel = sample
+			return nil
+		}
+		if absBank < ev.entryBank || ev.wingsLevel == nil {
+			return nil
+		}
+		ev.start(direction)
+	}
+
+	a := ev.attempt
+	if !a.reversalSince.IsZero() {
+		if absBank >= ev.entryBank {
+			if direction == a.direction {
+				// Turned back the same way, which is no lazy eight.
+				return ev.complete(sample, false)
+			}
+			a.reversalSince = time.Time{}
+			a.direction = direction
+			a.turnAngle = 0
+			a.maxBank = 0
+		} else if sample.Time.Sub(a.reversalSince) > ev.maxReversal {
+			return ev.complete(sample, absBank < ev.wingsLevelBank)
+		}
+	}
+	a.turnAngle += a.direction * maneuvers.HeadingDelta(a.lastHeading, heading)
+	a.lastHeading = heading
+
+	if a.reversalSince.IsZero() {
+		a.maxBank = math.Max(a.maxBank, absBank)
+		if absBank < ev.wingsLevelBank {
+			if a.turnAngle < ev.minTurnAngle {
+				return ev.complete(sample, true)
+			}
+			// The 180° point.
+			a.altitude.Add(altitude - a.entry.Altitude)
+			a.airspeed.Add(airspeed - a.entry.Airspeed)
+			a.heading.Add(a.turnAngle - 180)
+			a.maxBankDev.Add(a.maxBank - ev.targetBank)
+			a.turns++
+			if a.turns == 2 {
+				return ev.complete(sample, true)
+			}
+			a.reversalSince = sample.Time
+		}
+	}
+
+	phase := PhaseFirstTurn
+	if !a.reversalSince.IsZero() {
+		phase = PhaseReversal
+	} else if a.turns > 0 {
+		phase = PhaseSecondTurn
+	}
+	deviations := []maneuvers.Deviation{
+		a.altitude.Deviation(altitude - a.entry.Altitude),
+		a.airspeed.Deviation(airspeed - a.entry.Airspeed),
+		a.maxBankDev.Deviation(a.maxBank - ev.targetBank),
+	}
+	a.trace.Add(maneuvers.TracePoint{
+		"t":             maneuvers.Round(sample.Time.Sub(a.startedAt).Seconds(), 2),
+		monikerAltitude: maneuvers.Round(altitude, 1),
+		monikerAirspeed: maneuvers.Round(airspeed, 1),
+		monikerBank:     maneuvers.Round(bank, 1),
+		monikerHeading:  maneuvers.Round(heading, 1),
+	})
+	return &maneuvers.Evaluation{Phase: phase, Deviations: deviations, Details: a.details(ev.targetBank)}
+}
+
+func (ev *Evaluator) start(direction float64) {
+	ref := ev.wingsLevel
+	altitude, _ := ref.Float(monikerAltitude)
+	airspeed, _ := ref.Float(monikerAirspeed)
+	heading, _ := ref.Float(monikerHeading)
+	ev.attempt = &attempt{
+		startedAt:   ref.Time,
+		entry:       Reference{Altitude: altitude, Airspeed: airspeed, Heading: heading},
+		direction:   direction,
+		lastHeading: heading,
+		altitude:    maneuvers.NewTracker(ev.spec, "altitude"),
+		airspeed:    maneuvers.NewTracker(ev.spec, "airspeed"),
+		heading:     maneuvers.NewTracker(ev.spec, "heading"),
+		maxBankDev:  maneuvers.NewTracker(ev.spec, "max_bank"),
+		trace:       maneuvers.NewTrace(),
+	}
+}
+
+// complete ends the attempt. The sample is the reference for the next attempt
+// if the wings are level.
+func (ev *Evaluator) complete(sample *telemetry.Sample, wingsLevel bool) *maneuvers.Evaluation {
+	a := ev.attempt
+	ev.attempt = nil
+	ev.wingsLevel = nil
+	if wingsLevel {
+		ev.wingsLevel = sample
+	}
+
+	criteria := []maneuvers.Criterion{
+		a.altitude.Criterion(),
+		a.airspeed.Criterion(),
+		a.heading.Criterion(),
+		a.maxBankDev.Criterion(),
+	}
+	result := maneuvers.NewResult(Maneuver, a.startedAt, sample.Time, a.turns == 2, criteria)
+	result.Details = a.details(ev.targetBank)
+	result.Trace = a.trace.Points()
+	return &maneuvers.Evaluation{Phase: maneuvers.PhaseCompleted, Details: result.Details, Result: result}
+}
+
+func (a *attempt) details(targetBank float64) *Details {
+	direction := DirectionLeft
+	if a.direction < 0 {
+		direction = DirectionRight
+	}
+	return &Details{
+		Direction:  direction,
+		Entry:      a.entry,
+		TargetBank: targetBank,
+		Turns:      a.turns,
+		TurnAngle:  maneuvers.Round(a.turnAngle, 1),
+		MaxBank:    maneuvers.Round(a.maxBank, 1),
+	}
+}
@@ -0,0 +1,107 @@
+package lazyeights
+
+import (
+	"math"
+	"testing"
+
+	"msfs2020-gopilot/internal/maneuvers"
+	"msfs2020-gopilot/internal/maneuvers/maneuvertest"
+)
+
+// technique describes how the turns of a lazy eight are flown: the bank
+// increases to its maximum at 90° and decreases until the wings are rolled level
+// at levelAt, while the aircraft climbs, slows down and descends back.
+type technique struct {
+	maxBank float64
+	levelAt float64 // degrees of turn
+	climb   float64 // ft/min at the beginning of the turn
+}
+
+var good = technique{maxBank: 30, levelAt: 180, climb: 1000}
+
+// turn flies a turn in the direction (1: left, -1: right).
+func (tq technique) turn(flight *maneuvertest.Flight, direction float64) {
+	turned := 0.0
+	last := flight.Heading
+	for turned < tq.levelAt {
+		flight.Fly(maneuvertest.Interval.Seconds(), func(s *maneuvertest.State, _ float64) {
+			turned += direction * maneuvers.HeadingDelta(last, s.Heading)
+			last = s.Heading
+			progress := math.Pi * math.Min(turned, tq.levelAt) / tq.levelAt
+			// The bank is at least 10.5° so the turn does not take forever.
+			s.Bank = direction * tq.maxBank * math.Max(math.Sin(progress), 0.35)
+			s.VerticalSpeed = tq.climb * math.Cos(progress)
+			s.Airspeed = 100 - 20*math.Sin(progress)
+		})
+	}
+	flight.Set(func(s *maneuvertest.State) {
+		s.VerticalSpeed = 0
+	}).Roll(0.5, 0)
+}
+
+func (tq technique) fly(directions ...float64) *maneuvertest.Flight {
+	flight := maneuvertest.NewFlight(maneuvertest.State{Altitude: 4000, Airspeed: 100, Heading: 0}).Hold(3)
+	for _, direction := range directions {
+		tq.turn(flight, direction)
+	}
+	return flight.Hold(1)
+}
+
+func TestLazyEight(t *testing.T) {
+	phases, results := maneuvertest.Evaluate(New(DefaultSpec()), good.fly(1, -1).Samples)
+
+	want := []string{PhaseFirstTurn, PhaseReversal, PhaseSecondTurn, "completed"}
+	if !maneuvertest.EqualStrings(phases, want) {
+		t.Errorf("phases %v, want %v", phases, want)
+	}
+	if len(results) != 1 {
+		t.Fatalf("%d results, want 1", len(results))
+	}
+	result := results[0]
+	if !result.Complete || !result.Passed {
+		t.Errorf("result complete: %t, passed: %t, criteria %+v, want a passed lazy eight", result.Complete, result.Passed, result.Criteria)
+	}
+	details := result.Details.(*Details)
+	if details.Turns != 2 || details.Direction != DirectionRight || math.Abs(details.MaxBank-30) > 1 {
+		t.Errorf("details %+v", details)
+	}
+}
+
+func TestLazyEightFailed(t *testing.T) {
+	steep := good
+	steep.maxBank = 40
+	overshoot := good
+	overshoot.levelAt = 200
+	short := good
+	short.levelAt = 120
+
+	tests := []struct {
+		name       string
+		technique  technique
+		directions []float64
+		hold       float64 // seconds after the turns
+		complete   bool
+		failed     string
+	}{
+		{name: "steep", technique: steep, directions: []float64{-1, 1}, complete: true, failed: "max_bank"},
+		{name: "overshoot", technique: overshoot, directions: []float64{1, -1}, complete: true, failed: "heading"},
+		{name: "same direction", technique: good, directions: []float64{1, 1}},
+		{name: "short turn", technique: short, directions: []float64{1, -1}},
+		{name: "one turn", technique: good, directions: []float64{1}, hold: 15},
+	}
+	for _, test := range tests {
+		flight := test.technique.fly(test.directions...).Hold(test.hold)
+		_, results := maneuvertest.Evaluate(New(DefaultSpec()), flight.Samples)
+		if len(results) == 0 {
+			t.Errorf("%s: no result", test.name)
+			continue
+		}
+		result := results[0]
+		if result.Passed || result.Complete != test.complete {
+			t.Errorf("%s: result complete: %t, passed: %t, want failed and complete: %t", test.name, result.Complete, result.Passed, test.complete)
+		}
+		if test.failed != "" && maneuvertest.Criterion(result, test.failed).Passed {
+			t.Errorf("%s: %s passed: %+v", test.name, test.failed, result.Criteria)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package maneuvers
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"msfs2020-gopilot/internal/telemetry"
+
+	"github.com/google/uuid"
+)
+
+// Phases shared by all maneuvers. Evaluators define their own phases for the
+// time in between.
+const (
+	PhaseIdle      = "idle"
+	PhaseCompleted = "completed"
+	PhaseAborted   = "aborted"
+)
+
+// Evaluator grades one maneuver. Evaluate is called for every sample and
+// returns the current state of the attempt, or nil while there is nothing to
+// report. Evaluators are not safe for concurrent use; see Session.
+type Evaluator interface {
+	Vars() []telemetry.Var
+	Evaluate(sample *telemetry.Sample) *Evaluation
+	Reset()
+}
+
+// Factory creates an evaluator from a (possibly overridden) spec.
+type Factory func(spec *Spec) Evaluator
+
+// Evaluation is the payload of the evaluation message.
+type Evaluation struct {
+	Maneuver   string      `json:"maneuver"`
+	Phase      string      `json:"phase"`
+	Time       time.Time   `json:"time"`
+	Deviations []Deviation `json:"deviations,omitempty"`
+	Details    interface{} `json:"details,omitempty"`
+	Result     *Result     `json:"result,omitempty"`
+}
+
+type Deviation struct {
+	Name   string  `json:"name"`
+	Unit   string  `json:"unit"`
+	Value  float64 `json:"value"`
+	Within bool    `json:"within"`
+}
+
+type Criterion struct {
+	Name         string    `json:"name"`
+	Tolerance    Tolerance `json:"tolerance"`
+	MinDeviation float64   `json:"minDeviation"`
+	MaxDeviation float64   `json:"maxDeviation"`
+	Passed       bool      `json:"passed"`
+	Score        float64   `json:"score"`
+}
+
+// TracePoint holds the values of one sample, keyed by moniker. "t" is the time
+// in seconds since the start of the attempt.
+type TracePoint map[string]float64
+
+// MaxTracePoints limits the size of the trace of an attempt.
+const MaxTracePoints = 1024
+
+// Trace records the samples of an attempt. Once it holds MaxTracePoints, every
+// other point is dropped and only every other sample is recorded from then on,
+// so the trace of a long attempt keeps its shape at a lower resolution.
+type Trace struct {
+	points  []TracePoint
+	every   int // record every n-th sample
+	samples int
+}
+
+func NewTrace() *Trace {
+	return &Trace{points: make([]TracePoint, 0, 512), every: 1}
+}
+
+func (t *Trace) Add(point TracePoint) {
+	n := t.samples
+	t.samples++
+	if n%t.every != 0 {
+		return
+	}
+	if len(t.points) == MaxTracePoints {
+		kept := t.points[:0]
+		for i := 0; i < len(t.points); i += 2 {
+			kept = append(kept, t.points[i])
+		}
+		for i := len(kept); i < len(t.points); i++ {
+			t.points[i] = nil
+		}
+		t.points = kept
+		t.every *= 2
+		if n%t.every != 0 {
+			return
+		}
+	}
+	t.points = append(t.points, point)
+}
+
+func (t *Trace) Points() []TracePoint {
+	return t.points
+}
+
+type Result struct {
+	ID          string       `json:"id"`
+	Maneuver    string       `json:"maneuver"`
+	StartedAt   time.Time    `json:"startedAt"`
+	CompletedAt time.Time    `json:"completedAt"`
+	Duration    float64      `json:"duration"` // seconds
+	Complete    bool         `json:"complete"`
+	Passed      bool         `json:"passed"`
+	Score       float64      `json:"score"`
+	Criteria    []Criterion  `json:"criteria"`
+	Details     interface{}  `json:"details,omitempty"`
+	Trace       []TracePoint `json:"trace,omitempty"`
+}
+
+// NewResult grades an attempt. It passes if it is complete and all criteria
+// passed; the score is the average of the criteria scores.
+func NewResult(maneuver string, startedAt, completedAt time.Time, complete bool, criteria []Criterion) *Result {
+	passed := complete
+	score := 0.0
+	for _, c := range criteria {
+		passed = passed && c.Passed
+		score += c.Score
+	}
+	if len(criteria) > 0 {
+		score /= float64(len(criteria))
+	}
+	if !complete {
+		score = 0
+	}
+	return &Result{
+		ID:          fmt.Sprintf("%s-%s", startedAt.Format("20060102-150405"), uuid.New().String()[:8]),
+		Maneuver:    maneuver,
+		StartedAt:   startedAt,
+		CompletedAt: completedAt,
+		Duration:    Round(completedAt.Sub(startedAt).Seconds(), 1),
+		Complete:    complete,
+		Passed:      passed,
+		Score:       Round(score, 1),
+		Criteria:    criteria,
+	}
+}
+
+// Summary is a result without its trace.
+func (result *Result) Summary() *Result {
+	summary := *result
+	summary.Trace = nil
+	return &summary
+}
+
+// Score rates a deviation: 100 points without deviation, 50 points at the
+// tolerance and 0 points at twice the tolerance.
+func Score(tolerance Tolerance, deviation float64) float64 {
+	return math.Max(0, math.Min(100, 100-50*tolerance.Ratio(deviation)))
+}
+
+// Tracker records the deviations of one criterion over an attempt.
+type Tracker struct {
+	name      string
+	tolerance Tolerance
+	min       float64
+	max       float64
+}
+
+func NewTracker(spec *Spec, name string) *Tracker {
+	return &Tracker{name: name, tolerance: spec.Tolerance(name)}
+}
+
+func (t *Tracker) Add(deviation float64) Deviation {
+	t.min = math.Min(t.min, deviation)
+	t.max = math.Max(t.max, deviation)
+	return t.Deviation(deviation)
+}
+
+// Deviation describes a value without recording it.
+func (t *Tracker) Deviation(deviation float64) Deviation {
+	return Deviation{
+		Name:   t.name,
+		Unit:   t.tolerance.Unit,
+		Value:  Round(deviation, 1),
+		Within: t.tolerance.Contains(deviation),
+	}
+}
+
+func (t *Tracker) Criterion() Criterion {
+	worst := t.max
+	if t.tolerance.Ratio(t.min) > t.tolerance.Ratio(t.max) {
+		worst = t.min
+	}
+	return Criterion{
+		Name:         t.name,
+		Tolerance:    t.tolerance,
+		MinDeviation: Round(t.min, 1),
+		MaxDeviation: Round(t.max, 1),
+		Passed:       t.tolerance.Contains(worst),
+		Score:        Round(Score(t.tolerance, worst), 1),
+	}
+}
+
+// HeadingDelta returns the signed change from one heading to another in the
+// range (-180, 180]. Positive values are turns to the left, which matches the
+// sign of PLANE BANK DEGREES.
+func HeadingDelta(from, to float64) float64 {
+	d := math.Mod(from-to, 360)
+	if d <= -180 {
+		d += 360
+	} else if d > 180 {
+		d -= 360
+	}
+	return d
+}
+
+func Round(value float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(value*p) / p
+}
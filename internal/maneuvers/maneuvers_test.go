@@ -0,0 +1,180 @@
+package maneuvers
+
+import (
+	"testing"
+	"time"
+
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+func TestCriterion(t *testing.T) {
+	spec := testSpec()
+	spec.Tolerances["altitude"] = Tolerance{Unit: "feet", Below: 50, Above: 100}
+	tracker := NewTracker(spec, "altitude")
+	for _, deviation := range []float64{10, 30, -20, 80, -60, 0} {
+		tracker.Add(deviation)
+	}
+	// -60 ft uses up more of the tolerance than +80 ft.
+	want := Criterion{
+		Name:         "altitude",
+		Tolerance:    spec.Tolerance("altitude"),
+		MinDeviation: -60,
+		MaxDeviation: 80,
+		Passed:       false,
+		Score:        40,
+	}
+	if got := tracker.Criterion(); got != want {
+		t.Errorf("criterion %+v, want %+v", got, want)
+	}
+
+	// Deviation describes a value without recording it.
+	if d := tracker.Deviation(-120.04); d.Value != -120 || d.Within || d.Unit != "feet" {
+		t.Errorf("deviation %+v", d)
+	}
+	if got := tracker.Criterion(); got.MinDeviation != -60 {
+		t.Errorf("minimum deviation %g after Deviation", got.MinDeviation)
+	}
+
+	passing := NewTracker(spec, "altitude")
+	passing.Add(40)
+	if got := passing.Criterion(); !got.Passed || got.Score != 80 {
+		t.Errorf("criterion %+v, want passed with 80 points", got)
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	start := time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(95500 * time.Millisecond)
+	criteria := []Criterion{{Passed: true, Score: 90}, {Passed: true, Score: 65}}
+
+	result := NewResult("test_turn", start, end, true, criteria)
+	if !result.Passed || result.Score != 77.5 || result.Duration != 95.5 || result.Maneuver != "test_turn" {
+		t.Errorf("result %+v, want passed with 77.5 points after 95.5 s", result)
+	}
+	if len(result.ID) != len("20210901-100000-")+8 || result.ID[:16] != "20210901-100000-" {
+		t.Errorf("ID %s", result.ID)
+	}
+
+	criteria[1].Passed = false
+	if result := NewResult("test_turn", start, end, true, criteria); result.Passed || result.Score != 77.5 {
+		t.Errorf("result %+v, want failed with 77.5 points", result)
+	}
+	if result := NewResult("test_turn", start, end, false, criteria[:1]); result.Passed || result.Score != 0 {
+		t.Errorf("incomplete result %+v, want failed with 0 points", result)
+	}
+}
+
+func TestHeadingDelta(t *testing.T) {
+	tests := []struct {
+		from, to, delta float64
+	}{
+		{from: 90, to: 80, delta: 10},
+		{from: 80, to: 90, delta: -10},
+		{from: 5, to: 355, delta: 10},
+		{from: 355, to: 5, delta: -10},
+		{from: 0, to: 180, delta: 180},
+		{from: 270, to: 90, delta: 180},
+	}
+	for _, test := range tests {
+		if got := HeadingDelta(test.from, test.to); got != test.delta {
+			t.Errorf("HeadingDelta(%g, %g) = %g, want %g", test.from, test.to, got, test.delta)
+		}
+	}
+}
+
+// countingEvaluator is active from the first sample and completes after the
+// given number of samples.
+type countingEvaluator struct {
+	samples int
+	count   int
+	resets  int
+}
+
+func (ev *countingEvaluator) Vars() []telemetry.Var { return nil }
+
+func (ev *countingEvaluator) Reset() {
+	ev.count = 0
+	ev.resets++
+}
+
+func (ev *countingEvaluator) Evaluate(sample *telemetry.Sample) *Evaluation {
+	ev.count++
+	if ev.count == ev.samples {
+		ev.count = 0
+		return &Evaluation{Phase: PhaseCompleted}
+	}
+	return &Evaluation{Phase: "counting"}
+}
+
+func TestSession(t *testing.T) {
+	start := time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC)
+	evaluator := &countingEvaluator{samples: 3}
+	phases := make([]string, 0)
+	session := NewSession(testSpec(), evaluator, func(evaluation *Evaluation) {
+		phases = append(phases, evaluation.Phase)
+	})
+	if status := session.Status(); status.Phase != PhaseIdle || status.Maneuver != "test_turn" {
+		t.Errorf("status %+v before the first sample", status)
+	}
+
+	seconds := []int{0, 1, 2, 3, 4, 20, 21}
+	for _, second := range seconds {
+		session.Consume(telemetry.NewSample(start.Add(time.Duration(second) * time.Second)))
+	}
+	// The gap before 20 s aborts the attempt which started at 3 s.
+	want := []string{"counting", "counting", PhaseCompleted, "counting", "counting", PhaseAborted, "counting", "counting"}
+	if !equalStrings(phases, want) {
+		t.Errorf("phases %v, want %v", phases, want)
+	}
+	if status := session.Status(); status.Phase != "counting" || !status.Time.Equal(start.Add(21*time.Second)) {
+		t.Errorf("status %+v", status)
+	}
+
+	session.Reset()
+	if status := session.Status(); status.Phase != PhaseAborted || phases[len(phases)-1] != PhaseAborted {
+		t.Errorf("status %+v after a reset", status)
+	}
+	// Nothing to abort.
+	session.Reset()
+	if len(phases) != len(want)+1 || evaluator.resets != 3 {
+		t.Errorf("%d evaluations, %d resets", len(phases), evaluator.resets)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTrace(t *testing.T) {
+	trace := NewTrace()
+	samples := 3*MaxTracePoints + 1
+	for i := 0; i < samples; i++ {
+		trace.Add(TracePoint{"t": float64(i)})
+	}
+	// The trace was halved twice and records every fourth sample.
+	points := trace.Points()
+	if len(points) != samples/4+1 {
+		t.Fatalf("%d points, want %d", len(points), samples/4+1)
+	}
+	for i, point := range points {
+		if point["t"] != float64(4*i) {
+			t.Fatalf("point %d is sample %g, want %d", i, point["t"], 4*i)
+		}
+	}
+
+	short := NewTrace()
+	for i := 0; i < MaxTracePoints; i++ {
+		short.Add(TracePoint{"t": float64(i)})
+	}
+	if len(short.Points()) != MaxTracePoints {
+		t.Errorf("%d points, want all %d", len(short.Points()), MaxTracePoints)
+	}
+}
@@ -0,0 +1,155 @@
+// Package maneuvertest records flights for the tests of the maneuver
+// evaluators.
+package maneuvertest
+
+import (
+	"math"
+	"time"
+
+	"msfs2020-gopilot/internal/geo"
+	"msfs2020-gopilot/internal/maneuvers"
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+// Interval is the time between two samples, like with the default data request
+// interval.
+const Interval = 250 * time.Millisecond
+
+var Start = time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC)
+
+// State holds the values of a sample under the monikers the evaluators use.
+type State struct {
+	Altitude      float64 // feet
+	Height        float64 // feet above ground
+	Airspeed      float64 // knots, also the ground speed
+	VerticalSpeed float64 // ft/min
+	Bank          float64 // degrees, positive to the left
+	Heading       float64 // degrees
+	Latitude      float64
+	Longitude     float64
+	StallWarning  bool
+	OnGround      bool
+	GearDown      bool
+}
+
+// Flight is a coordinated flight without wind: the heading changes with the
+// bank, and altitude, height and position with the speeds.
+type Flight struct {
+	State
+	Time    time.Time
+	Samples []*telemetry.Sample
+}
+
+func NewFlight(state State) *Flight {
+	return &Flight{State: state, Time: Start}
+}
+
+// Fly records samples for the given number of seconds. change may modify the
+// state before each sample; it gets the seconds since the start of the call.
+func (f *Flight) Fly(seconds float64, change func(s *State, elapsed float64)) *Flight {
+	dt := Interval.Seconds()
+	n := int(math.Round(seconds / dt))
+	for i := 1; i <= n; i++ {
+		if change != nil {
+			change(&f.State, float64(i)*dt)
+		}
+		f.advance(dt)
+		f.Time = f.Time.Add(Interval)
+		f.Samples = append(f.Samples, f.sample())
+	}
+	return f
+}
+
+// Hold keeps the bank, speeds and configuration.
+func (f *Flight) Hold(seconds float64) *Flight {
+	return f.Fly(seconds, nil)
+}
+
+// Roll changes the bank linearly.
+func (f *Flight) Roll(seconds, bank float64) *Flight {
+	from := f.Bank
+	return f.Fly(seconds, func(s *State, elapsed float64) {
+		s.Bank = from + (bank-from)*elapsed/seconds
+	})
+}
+
+// Set changes the state at once, e.g. to set the stall warning.
+func (f *Flight) Set(change func(s *State)) *Flight {
+	change(&f.State)
+	return f
+}
+
+// TurnRate returns the rate of a coordinated turn in degrees per second.
+func TurnRate(bank, airspeed float64) float64 {
+	return 1091 * math.Tan(geo.DegToRad(bank)) / airspeed
+}
+
+func (f *Flight) advance(dt float64) {
+	if f.Airspeed > 0 && !f.OnGround {
+		f.Heading = geo.NormalizeHeading(f.Heading - TurnRate(f.Bank, f.Airspeed)*dt)
+	}
+	f.Altitude += f.VerticalSpeed / 60 * dt
+	f.Height = math.Max(0, f.Height+f.VerticalSpeed/60*dt)
+	distance := geo.FeetToMeters(f.Airspeed * 6076.12 / 3600 * dt)
+	track := geo.DegToRad(f.Heading)
+	f.Latitude += geo.RadToDeg(distance * math.Cos(track) / geo.EarthRadius)
+	f.Longitude += geo.RadToDeg(distance * math.Sin(track) / (geo.EarthRadius * math.Cos(geo.DegToRad(f.Latitude))))
+}
+
+func (f *Flight) sample() *telemetry.Sample {
+	sample := telemetry.NewSample(f.Time)
+	sample.Set("altitude", f.Altitude)
+	sample.Set("height", f.Height)
+	sample.Set("airspeed", f.Airspeed)
+	sample.Set("verticalSpeed", f.VerticalSpeed)
+	sample.Set("bank", f.Bank)
+	sample.Set("heading", f.Heading)
+	sample.Set("latitude", f.Latitude)
+	sample.Set("longitude", f.Longitude)
+	sample.Set("stallWarning", f.StallWarning)
+	sample.Set("onGround", f.OnGround)
+	sample.Set("gearDown", f.GearDown)
+	return sample
+}
+
+// Evaluate feeds the samples to the evaluator. It returns the phases the
+// evaluator reported, without repetitions, and the results.
+func Evaluate(ev maneuvers.Evaluator, samples []*telemetry.Sample) ([]string, []*maneuvers.Result) {
+	phases := make([]string, 0)
+	results := make([]*maneuvers.Result, 0)
+	for _, sample := range samples {
+		evaluation := ev.Evaluate(sample)
+		if evaluation == nil {
+			continue
+		}
+		if len(phases) == 0 || phases[len(phases)-1] != evaluation.Phase {
+			phases = append(phases, evaluation.Phase)
+		}
+		if evaluation.Result != nil {
+			results = append(results, evaluation.Result)
+		}
+	}
+	return phases, results
+}
+
+// Criterion returns the criterion of a result with the given name.
+func Criterion(result *maneuvers.Result, name string) maneuvers.Criterion {
+	for _, c := range result.Criteria {
+		if c.Name == name {
+			return c
+		}
+	}
+	return maneuvers.Criterion{}
+}
+
+func EqualStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
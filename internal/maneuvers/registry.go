@@ -0,0 +1,83 @@
+package maneuvers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const specFileExt = ".yml"
+
+type registryEntry struct {
+	spec    *Spec
+	factory Factory
+}
+
+// Registry holds the available maneuvers.
+type Registry struct {
+	entries map[string]*registryEntry
+}
+
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*registryEntry)}
+}
+
+func (r *Registry) Register(spec *Spec, factory Factory) {
+	if _, ok := r.entries[spec.Name]; ok {
+		panic(fmt.Sprintf("maneuver %s registered twice", spec.Name))
+	}
+	r.entries[spec.Name] = &registryEntry{spec: spec, factory: factory}
+}
+
+// LoadDir applies the tolerance files in dir to the registered maneuvers. A
+// missing directory is not an error.
+func (r *Registry) LoadDir(dir string) error {
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	for name, entry := range r.entries {
+		path := filepath.Join(dir, name+specFileExt)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		spec := entry.spec.clone()
+		if err := spec.merge(path); err != nil {
+			return err
+		}
+		entry.spec = spec
+		log.Debug("Loaded tolerances for maneuver ", name, " from ", path)
+	}
+	return nil
+}
+
+func (r *Registry) Spec(name string) (*Spec, bool) {
+	entry, ok := r.entries[name]
+	if !ok {
+		return nil, false
+	}
+	return entry.spec.clone(), true
+}
+
+func (r *Registry) Specs() []*Spec {
+	specs := make([]*Spec, 0, len(r.entries))
+	for _, entry := range r.entries {
+		specs = append(specs, entry.spec.clone())
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].Name < specs[j].Name
+	})
+	return specs
+}
+
+func (r *Registry) NewSession(name string, onEvaluation func(evaluation *Evaluation)) (*Session, error) {
+	entry, ok := r.entries[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown maneuver %q", name)
+	}
+	spec := entry.spec.clone()
+	return NewSession(spec, entry.factory(spec), onEvaluation), nil
+}
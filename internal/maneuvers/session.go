@@ -0,0 +1,102 @@
+package maneuvers
+
+import (
+	"sync"
+	"time"
+
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+// Attempts are aborted if no sample was received for this long, e.g. when the
+// simulator was paused or the aircraft was teleported.
+const maxSampleGap = 5 * time.Second
+
+// Session feeds samples to an evaluator and reports its evaluations. It
+// implements telemetry.Consumer.
+type Session struct {
+	spec         *Spec
+	evaluator    Evaluator
+	onEvaluation func(evaluation *Evaluation)
+	last         *Evaluation
+	lastSample   time.Time
+	mutex        sync.Mutex
+}
+
+func NewSession(spec *Spec, evaluator Evaluator, onEvaluation func(evaluation *Evaluation)) *Session {
+	return &Session{
+		spec:         spec,
+		evaluator:    evaluator,
+		onEvaluation: onEvaluation,
+	}
+}
+
+func (s *Session) Spec() *Spec {
+	return s.spec
+}
+
+func (s *Session) Vars() []telemetry.Var {
+	return s.evaluator.Vars()
+}
+
+func (s *Session) Consume(sample *telemetry.Sample) {
+	evaluations := make([]*Evaluation, 0, 2)
+
+	s.mutex.Lock()
+	gap := !s.lastSample.IsZero() && sample.Time.Sub(s.lastSample) > maxSampleGap
+	s.lastSample = sample.Time
+	if gap {
+		if abort := s.abort(sample.Time); abort != nil {
+			evaluations = append(evaluations, abort)
+		}
+	}
+	if evaluation := s.evaluator.Evaluate(sample); evaluation != nil {
+		evaluation.Maneuver = s.spec.Name
+		evaluation.Time = sample.Time
+		s.last = evaluation
+		evaluations = append(evaluations, evaluation)
+	}
+	s.mutex.Unlock()
+
+	for _, evaluation := range evaluations {
+		s.emit(evaluation)
+	}
+}
+
+// Status returns the latest evaluation.
+func (s *Session) Status() *Evaluation {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.last == nil {
+		return &Evaluation{Maneuver: s.spec.Name, Phase: PhaseIdle}
+	}
+	return s.last
+}
+
+// Reset aborts the current attempt, if any.
+func (s *Session) Reset() {
+	s.mutex.Lock()
+	evaluation := s.abort(time.Now())
+	s.mutex.Unlock()
+	if evaluation != nil {
+		s.emit(evaluation)
+	}
+}
+
+func (s *Session) abort(t time.Time) *Evaluation {
+	s.evaluator.Reset()
+	if s.last == nil || !s.last.active() {
+		return nil
+	}
+	s.last = &Evaluation{Maneuver: s.spec.Name, Phase: PhaseAborted, Time: t}
+	return s.last
+}
+
+func (s *Session) emit(evaluation *Evaluation) {
+	if s.onEvaluation != nil {
+		s.onEvaluation(evaluation)
+	}
+}
+
+func (e *Evaluation) active() bool {
+	return e.Phase != PhaseIdle && e.Phase != PhaseCompleted && e.Phase != PhaseAborted
+}
@@ -0,0 +1,213 @@
+package slowflight
+
+import (
+	"math"
+	"time"
+
+	"msfs2020-gopilot/internal/maneuvers"
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+const (
+	Maneuver = "slow_flight"
+
+	PhaseEstablished = "established"
+
+	monikerAltitude     = "altitude"
+	monikerAirspeed     = "airspeed"
+	monikerHeading      = "heading"
+	monikerBank         = "bank"
+	monikerStallWarning = "stallWarning"
+	monikerOnGround     = "onGround"
+)
+
+// DefaultSpec uses the tolerances of the FAA Private Pilot ACS (Task VII.A,
+// Maneuvering During Slow Flight). The target airspeed depends on the aircraft
+// and should be set in the tolerance file.
+func DefaultSpec() *maneuvers.Spec {
+	return &maneuvers.Spec{
+		Name:        Maneuver,
+		Title:       "Slow Flight",
+		Description: "Straight-and-level flight and turns at an airspeed just above the stall warning",
+		Parameters: map[string]float64{
+			"target_airspeed":  55, // knots
+			"min_duration":     30, // seconds
+			"wings_level_bank": 5,  // degrees; heading is only graded with the wings level
+			"recovery_margin":  20, // knots above the target airspeed at which the maneuver ends
+		},
+		Tolerances: map[string]maneuvers.Tolerance{
+			"altitude":      maneuvers.Symmetric(100, "feet"),
+			"heading":       maneuvers.Symmetric(10, "degrees"),
+			"airspeed":      {Unit: "knots", Below: 0, Above: 10},
+			"stall_warning": {Unit: "", Below: 0, Above: 0},
+		},
+	}
+}
+
+type Details struct {
+	TargetAirspeed float64 `json:"targetAirspeed"`
+	Altitude       float64 `json:"altitude"`
+	Heading        float64 `json:"heading"`
+	Duration       float64 `json:"duration"`
+}
+
+// deviations of one sample.
+type deviations struct {
+	altitude   float64
+	airspeed   float64
+	warning    float64
+	heading    float64
+	wingsLevel bool
+}
+
+type attempt struct {
+	startedAt    time.Time
+	altitude     float64
+	heading      float64
+	altitudeDev  *maneuvers.Tracker
+	headingDev   *maneuvers.Tracker
+	airspeedDev  *maneuvers.Tracker
+	stallWarning *maneuvers.Tracker
+	// Deviations since the airspeed went above the tolerance, which are
+	// not graded if the aircraft is accelerating to end the maneuver.
+	pending []deviations
+	trace   *maneuvers.Trace
+}
+
+// Evaluator starts grading once the airspeed has decreased to the target
+// airspeed plus its tolerance. Altitude and heading at that moment are the
+// reference. The maneuver ends when the aircraft accelerates well above the
+// target airspeed or touches down. The acceleration at the end is not graded.
+type Evaluator struct {
+	spec           *maneuvers.Spec
+	targetAirspeed float64
+	minDuration    time.Duration
+	wingsLevelBank float64
+	recovery       float64
+	attempt        *attempt
+}
+
+func New(spec *maneuvers.Spec) maneuvers.Evaluator {
+	return &Evaluator{
+		spec:           spec,
+		targetAirspeed: spec.Param("target_airspeed"),
+		minDuration:    time.Duration(spec.Param("min_duration") * float64(time.Second)),
+		wingsLevelBank: spec.Param("wings_level_bank"),
+		recovery:       spec.Param("target_airspeed") + spec.Param("recovery_margin"),
+	}
+}
+
+func (ev *Evaluator) Vars() []telemetry.Var {
+	return []telemetry.Var{
+		{Name: "INDICATED ALTITUDE", Unit: "feet", Type: "float64", Moniker: monikerAltitude},
+		{Name: "AIRSPEED INDICATED", Unit: "knot", Type: "float64", Moniker: monikerAirspeed},
+		{Name: "PLANE HEADING DEGREES MAGNETIC", Unit: "degrees", Type: "float64", Moniker: monikerHeading},
+		{Name: "PLANE BANK DEGREES", Unit: "degrees", Type: "float64", Moniker: monikerBank},
+		{Name: "STALL WARNING", Unit: "bool", Type: "int32", Moniker: monikerStallWarning},
+		{Name: "SIM ON GROUND", Unit: "bool", Type: "int32", Moniker: monikerOnGround},
+	}
+}
+
+func (ev *Evaluator) Reset() {
+	ev.attempt = nil
+}
+
+func (ev *Evaluator) Evaluate(sample *telemetry.Sample) *maneuvers.Evaluation {
+	if !sample.Has(monikerAltitude, monikerAirspeed, monikerHeading, monikerBank) {
+		return nil
+	}
+	altitude, _ := sample.Float(monikerAltitude)
+	airspeed, _ := sample.Float(monikerAirspeed)
+	heading, _ := sample.Float(monikerHeading)
+	bank, _ := sample.Float(monikerBank)
+	stallWarning, _ := sample.Bool(monikerStallWarning)
+	onGround, _ := sample.Bool(monikerOnGround)
+
+	if ev.attempt == nil {
+		if onGround || airspeed > ev.targetAirspeed+ev.spec.Tolerance("airspeed").Above {
+			return nil
+		}
+		ev.attempt = &attempt{
+			startedAt:    sample.Time,
+			altitude:     altitude,
+			heading:      heading,
+			altitudeDev:  maneuvers.NewTracker(ev.spec, "altitude"),
+			headingDev:   maneuvers.NewTracker(ev.spec, "heading"),
+			airspeedDev:  maneuvers.NewTracker(ev.spec, "airspeed"),
+			stallWarning: maneuvers.NewTracker(ev.spec, "stall_warning"),
+			trace:        maneuvers.NewTrace(),
+		}
+	}
+
+	a := ev.attempt
+	if onGround || airspeed >= ev.recovery {
+		ev.attempt = nil
+		complete := sample.Time.Sub(a.startedAt) >= ev.minDuration && !onGround
+		criteria := []maneuvers.Criterion{
+			a.altitudeDev.Criterion(),
+			a.headingDev.Criterion(),
+			a.airspeedDev.Criterion(),
+			a.stallWarning.Criterion(),
+		}
+		result := maneuvers.NewResult(Maneuver, a.startedAt, sample.Time, complete, criteria)
+		result.Details = ev.details(a, sample.Time)
+		result.Trace = a.trace.Points()
+		return &maneuvers.Evaluation{Phase: maneuvers.PhaseCompleted, Details: result.Details, Result: result}
+	}
+
+	warning := 0.0
+	if stallWarning {
+		warning = 1
+	}
+	dev := deviations{
+		altitude: altitude - a.altitude,
+		airspeed: airspeed - ev.targetAirspeed,
+		warning:  warning,
+		heading:  -maneuvers.HeadingDelta(a.heading, heading),
+		// Turns are part of the maneuver, so the heading is only graded
+		// in straight flight.
+		wingsLevel: math.Abs(bank) < ev.wingsLevelBank,
+	}
+	if airspeed > ev.targetAirspeed+ev.spec.Tolerance("airspeed").Above {
+		a.pending = append(a.pending, dev)
+	} else {
+		for _, d := range a.pending {
+			a.add(d)
+		}
+		a.pending = a.pending[:0]
+		a.add(dev)
+	}
+	deviations := []maneuvers.Deviation{
+		a.altitudeDev.Deviation(dev.altitude),
+		a.airspeedDev.Deviation(dev.airspeed),
+		a.stallWarning.Deviation(dev.warning),
+		a.headingDev.Deviation(dev.heading),
+	}
+	a.trace.Add(maneuvers.TracePoint{
+		"t":                 maneuvers.Round(sample.Time.Sub(a.startedAt).Seconds(), 2),
+		monikerAltitude:     maneuvers.Round(altitude, 1),
+		monikerAirspeed:     maneuvers.Round(airspeed, 1),
+		monikerHeading:      maneuvers.Round(heading, 1),
+		monikerBank:         maneuvers.Round(bank, 1),
+		monikerStallWarning: warning,
+	})
+	return &maneuvers.Evaluation{Phase: PhaseEstablished, Deviations: deviations, Details: ev.details(a, sample.Time)}
+}
+
+func (a *attempt) add(d deviations) {
+	a.altitudeDev.Add(d.altitude)
+	a.airspeedDev.Add(d.airspeed)
+	a.stallWarning.Add(d.warning)
+	if d.wingsLevel {
+		a.headingDev.Add(d.heading)
+	}
+}
+
+func (ev *Evaluator) details(a *attempt, t time.Time) *Details {
+	return &Details{
+		TargetAirspeed: ev.targetAirspeed,
+		Altitude:       maneuvers.Round(a.altitude, 1),
+		Heading:        maneuvers.Round(a.heading, 1),
+		Duration:       maneuvers.Round(t.Sub(a.startedAt).Seconds(), 1),
+	}
+}
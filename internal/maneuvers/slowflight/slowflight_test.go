@@ -0,0 +1,105 @@
+package slowflight
+
+import (
+	"testing"
+
+	"msfs2020-gopilot/internal/maneuvers/maneuvertest"
+)
+
+func speed(knots float64) func(s *maneuvertest.State, elapsed float64) {
+	return func(s *maneuvertest.State, elapsed float64) {
+		s.Airspeed += (knots - s.Airspeed) * 0.2
+	}
+}
+
+// slowFlight slows down to 58 kt and flies a 360° turn to the left.
+func slowFlight() *maneuvertest.Flight {
+	return maneuvertest.NewFlight(maneuvertest.State{Altitude: 2500, Airspeed: 100, Heading: 180}).
+		Hold(3).
+		Fly(10, speed(58)).
+		Hold(10).
+		Roll(2, 15).Hold(69.5).Roll(2, 0).
+		Hold(5)
+}
+
+func TestSlowFlight(t *testing.T) {
+	flight := slowFlight().Fly(5, speed(80))
+	phases, results := maneuvertest.Evaluate(New(DefaultSpec()), flight.Samples)
+
+	if want := []string{PhaseEstablished, "completed"}; !maneuvertest.EqualStrings(phases, want) {
+		t.Errorf("phases %v, want %v", phases, want)
+	}
+	if len(results) != 1 {
+		t.Fatalf("%d results, want 1", len(results))
+	}
+	result := results[0]
+	if !result.Complete || !result.Passed {
+		t.Errorf("result complete: %t, passed: %t, criteria %+v, want a passed attempt", result.Complete, result.Passed, result.Criteria)
+	}
+	details := result.Details.(*Details)
+	if details.Altitude != 2500 || details.Heading != 180 || details.Duration < 30 {
+		t.Errorf("details %+v", details)
+	}
+}
+
+func TestSlowFlightFailed(t *testing.T) {
+	tests := []struct {
+		name     string
+		flight   *maneuvertest.Flight
+		complete bool
+		failed   string
+	}{
+		{
+			name: "stall warning",
+			flight: slowFlight().Set(func(s *maneuvertest.State) {
+				s.StallWarning = true
+			}).Hold(1).Set(func(s *maneuvertest.State) {
+				s.StallWarning = false
+			}).Fly(5, speed(80)),
+			complete: true,
+			failed:   "stall_warning",
+		},
+		{
+			name:     "too slow",
+			flight:   slowFlight().Fly(5, speed(50)).Hold(5).Fly(5, speed(80)),
+			complete: true,
+			failed:   "airspeed",
+		},
+		{
+			name:     "too fast",
+			flight:   slowFlight().Fly(5, speed(72)).Fly(5, speed(58)).Fly(5, speed(80)),
+			complete: true,
+			failed:   "airspeed",
+		},
+		{
+			name:     "heading",
+			flight:   slowFlight().Roll(1, 4).Hold(20).Fly(5, speed(80)),
+			complete: true,
+			failed:   "heading",
+		},
+		{
+			name:   "too short",
+			flight: maneuvertest.NewFlight(maneuvertest.State{Altitude: 2500, Airspeed: 60}).Hold(20).Fly(5, speed(80)),
+		},
+		{
+			name: "landed",
+			flight: slowFlight().Set(func(s *maneuvertest.State) {
+				s.OnGround = true
+			}).Hold(1),
+		},
+	}
+	for _, test := range tests {
+		_, results := maneuvertest.Evaluate(New(DefaultSpec()), test.flight.Samples)
+		if len(results) != 1 {
+			t.Errorf("%s: %d results, want 1", test.name, len(results))
+			continue
+		}
+		result := results[0]
+		if result.Passed || result.Complete != test.complete {
+			t.Errorf("%s: result complete: %t, passed: %t, want failed and complete: %t", test.name, result.Complete, result.Passed, test.complete)
+		}
+		if test.failed != "" && maneuvertest.Criterion(result, test.failed).Passed {
+			t.Errorf("%s: %s passed: %+v", test.name, test.failed, result.Criteria)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package maneuvers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Spec describes a maneuver and the tolerances it is graded against. Every
+// evaluator ships a default spec which can be overridden by a YAML file named
+// after the maneuver, e.g. steep_turn.yml:
+//
+//	title: Steep Turns
+//	parameters:
+//	  target_bank: 50
+//	tolerances:
+//	  altitude: 100             # +/- 100 feet
+//	  airspeed: {below: 0, above: 10}
+//
+// Only the keys present in the file are overridden.
+type Spec struct {
+	Name        string               `json:"name" yaml:"-"`
+	Title       string               `json:"title" yaml:"title"`
+	Description string               `json:"description,omitempty" yaml:"description"`
+	Parameters  map[string]float64   `json:"parameters" yaml:"parameters"`
+	Tolerances  map[string]Tolerance `json:"tolerances" yaml:"tolerances"`
+}
+
+// Tolerance is the allowed deviation below and above the target value. A
+// scalar in YAML sets both sides.
+type Tolerance struct {
+	Unit  string  `json:"unit" yaml:"unit"`
+	Below float64 `json:"below" yaml:"below"`
+	Above float64 `json:"above" yaml:"above"`
+}
+
+func Symmetric(tolerance float64, unit string) Tolerance {
+	return Tolerance{Unit: unit, Below: tolerance, Above: tolerance}
+}
+
+func (t *Tolerance) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var scalar float64
+	if err := unmarshal(&scalar); err == nil {
+		t.Below = scalar
+		t.Above = scalar
+		return nil
+	}
+	type plain Tolerance
+	return unmarshal((*plain)(t))
+}
+
+// Ratio returns how much of the tolerance a deviation uses up: 0 for no
+// deviation, 1 at the tolerance.
+func (t Tolerance) Ratio(deviation float64) float64 {
+	bound := t.Above
+	if deviation < 0 {
+		bound = t.Below
+		deviation = -deviation
+	}
+	if deviation == 0 {
+		return 0
+	}
+	if bound <= 0 {
+		return math.Inf(1)
+	}
+	return deviation / bound
+}
+
+func (t Tolerance) Contains(deviation float64) bool {
+	return t.Ratio(deviation) <= 1
+}
+
+func (spec *Spec) Param(name string) float64 {
+	return spec.Parameters[name]
+}
+
+func (spec *Spec) Tolerance(name string) Tolerance {
+	return spec.Tolerances[name]
+}
+
+func (spec *Spec) clone() *Spec {
+	c := *spec
+	c.Parameters = make(map[string]float64, len(spec.Parameters))
+	for k, v := range spec.Parameters {
+		c.Parameters[k] = v
+	}
+	c.Tolerances = make(map[string]Tolerance, len(spec.Tolerances))
+	for k, v := range spec.Tolerances {
+		c.Tolerances[k] = v
+	}
+	return &c
+}
+
+// merge applies the overrides of a tolerance file. Unknown parameters and
+// tolerances are rejected, since they are most likely typos.
+func (spec *Spec) merge(path string) error {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	override := &Spec{}
+	if err := yaml.UnmarshalStrict(buf, override); err != nil {
+		return fmt.Errorf("%s: %s", path, err.Error())
+	}
+	if override.Title != "" {
+		spec.Title = override.Title
+	}
+	if override.Description != "" {
+		spec.Description = override.Description
+	}
+	unknown := make([]string, 0)
+	for name, value := range override.Parameters {
+		if _, ok := spec.Parameters[name]; !ok {
+			unknown = append(unknown, "parameters."+name)
+			continue
+		}
+		spec.Parameters[name] = value
+	}
+	for name, tolerance := range override.Tolerances {
+		current, ok := spec.Tolerances[name]
+		if !ok {
+			unknown = append(unknown, "tolerances."+name)
+			continue
+		}
+		if tolerance.Unit == "" {
+			tolerance.Unit = current.Unit
+		}
+		if tolerance.Below < 0 || tolerance.Above < 0 {
+			return fmt.Errorf("%s: tolerances.%s must not be negative", path, name)
+		}
+		spec.Tolerances[name] = tolerance
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("%s: unknown keys for maneuver %s: %s", path, spec.Name, strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package maneuvers
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testSpec() *Spec {
+	return &Spec{
+		Name:  "test_turn",
+		Title: "Test Turn",
+		Parameters: map[string]float64{
+			"target_bank": 45,
+		},
+		Tolerances: map[string]Tolerance{
+			"altitude": Symmetric(100, "feet"),
+			"airspeed": Symmetric(10, "knots"),
+		},
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMerge(t *testing.T) {
+	spec := testSpec()
+	path := writeFile(t, t.TempDir(), "test_turn.yml", `
+title: Checkride Turn
+parameters:
+  target_bank: 50
+tolerances:
+  altitude: 50
+  airspeed: {below: 5, above: 15}
+`)
+	if err := spec.merge(path); err != nil {
+		t.Fatal(err)
+	}
+	if spec.Title != "Checkride Turn" || spec.Param("target_bank") != 50 {
+		t.Errorf("spec %+v", spec)
+	}
+	if got := spec.Tolerance("altitude"); got != Symmetric(50, "feet") {
+		t.Errorf("altitude tolerance %+v, want ±50 feet", got)
+	}
+	if got := spec.Tolerance("airspeed"); got != (Tolerance{Unit: "knots", Below: 5, Above: 15}) {
+		t.Errorf("airspeed tolerance %+v, want -5/+15 knots", got)
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "unknown keys", content: "parameters: {target_bnak: 50}\ntolerances: {heading: 10}", err: "unknown keys for maneuver test_turn: parameters.target_bnak, tolerances.heading"},
+		{name: "negative", content: "tolerances: {altitude: -100}", err: "tolerances.altitude must not be negative"},
+		{name: "unknown section", content: "tolerance: {altitude: 100}", err: "field tolerance not found"},
+		{name: "not a number", content: "tolerances: {altitude: high}", err: "cannot unmarshal"},
+	}
+	dir := t.TempDir()
+	for _, test := range tests {
+		spec := testSpec()
+		err := spec.merge(writeFile(t, dir, "test_turn.yml", test.content))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %v, want %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestRegistryLoadDir(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(testSpec(), nil)
+	if err := registry.LoadDir(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Errorf("missing directory: %s", err)
+	}
+
+	dir := t.TempDir()
+	writeFile(t, dir, "test_turn.yml", "parameters: {target_bank: 60}")
+	writeFile(t, dir, "other_maneuver.yml", "parameters: {whatever: 1}")
+	if err := registry.LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	spec, ok := registry.Spec("test_turn")
+	if !ok || spec.Param("target_bank") != 60 || spec.Tolerance("altitude").Above != 100 {
+		t.Errorf("spec %+v", spec)
+	}
+
+	// Specs are copies.
+	spec.Parameters["target_bank"] = 0
+	if spec, _ := registry.Spec("test_turn"); spec.Param("target_bank") != 60 {
+		t.Error("spec was changed through a copy")
+	}
+}
+
+func TestTolerance(t *testing.T) {
+	tolerance := Tolerance{Below: 5, Above: 10}
+	tests := []struct {
+		deviation float64
+		ratio     float64
+		score     float64
+		contains  bool
+	}{
+		{deviation: 0, ratio: 0, score: 100, contains: true},
+		{deviation: 5, ratio: 0.5, score: 75, contains: true},
+		{deviation: 10, ratio: 1, score: 50, contains: true},
+		{deviation: 15, ratio: 1.5, score: 25, contains: false},
+		{deviation: -5, ratio: 1, score: 50, contains: true},
+		{deviation: -10, ratio: 2, score: 0, contains: false},
+		{deviation: -20, ratio: 4, score: 0, contains: false},
+	}
+	for _, test := range tests {
+		if got := tolerance.Ratio(test.deviation); got != test.ratio {
+			t.Errorf("Ratio(%g) = %g, want %g", test.deviation, got, test.ratio)
+		}
+		if got := Score(tolerance, test.deviation); got != test.score {
+			t.Errorf("Score(%g) = %g, want %g", test.deviation, got, test.score)
+		}
+		if got := tolerance.Contains(test.deviation); got != test.contains {
+			t.Errorf("Contains(%g) = %t", test.deviation, got)
+		}
+	}
+
+	// No deviation is allowed on a side with a zero tolerance.
+	zero := Tolerance{Below: 0, Above: 0}
+	if !zero.Contains(0) || zero.Contains(1) || Score(zero, 1) != 0 {
+		t.Error("zero tolerance allows a deviation")
+	}
+}
@@ -0,0 +1,222 @@
+package stalls
+
+import (
+	"math"
+	"time"
+
+	"msfs2020-gopilot/internal/maneuvers"
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+const (
+	Maneuver = "stall"
+
+	PhaseStalled  = "stalled"
+	PhaseRecovery = "recovery"
+
+	// The stall warning has to be off for this long before the recovery
+	// begins, so a flickering warning is not counted as a secondary stall.
+	warningDebounce = time.Second
+
+	monikerAltitude      = "altitude"
+	monikerAirspeed      = "airspeed"
+	monikerVerticalSpeed = "verticalSpeed"
+	monikerBank          = "bank"
+	monikerStallWarning  = "stallWarning"
+	monikerOnGround      = "onGround"
+)
+
+// DefaultSpec uses the tolerances of the FAA Private Pilot ACS (Tasks VII.B and
+// VII.C, Power-Off and Power-On Stalls): at most 20° ±10° of bank in turning
+// stalls, recovery at the first indication of the stall and no secondary stall.
+// The ACS does not limit the altitude loss; 200 ft is a common training goal.
+func DefaultSpec() *maneuvers.Spec {
+	return &maneuvers.Spec{
+		Name:        Maneuver,
+		Title:       "Stalls",
+		Description: "Recovery from a power-off or power-on stall at the stall warning",
+		Parameters: map[string]float64{
+			"recovery_climb": 100, // ft/min; climbing at this rate completes the recovery
+		},
+		Tolerances: map[string]maneuvers.Tolerance{
+			"bank":            {Unit: "degrees", Below: 0, Above: 30},
+			"stall_duration":  {Unit: "seconds", Below: 0, Above: 3},
+			"altitude_loss":   {Unit: "feet", Below: 0, Above: 200},
+			"secondary_stall": {Unit: "", Below: 0, Above: 0},
+		},
+	}
+}
+
+type Details struct {
+	Altitude        float64 `json:"altitude"`
+	StallAirspeed   float64 `json:"stallAirspeed"`
+	AltitudeLoss    float64 `json:"altitudeLoss"`
+	StallDuration   float64 `json:"stallDuration"`
+	SecondaryStalls int     `json:"secondaryStalls"`
+}
+
+type attempt struct {
+	startedAt      time.Time
+	altitude       float64 // at the stall warning
+	airspeed       float64
+	minAltitude    float64
+	warningSince   time.Time
+	clearSince     time.Time // zero while the stall warning is on
+	warningTime    time.Duration
+	recovering     bool
+	secondary      int
+	bank           *maneuvers.Tracker
+	stallDuration  *maneuvers.Tracker
+	altitudeLoss   *maneuvers.Tracker
+	secondaryStall *maneuvers.Tracker
+	trace          *maneuvers.Trace
+}
+
+// Evaluator starts grading when the stall warning sounds in flight. Altitude
+// and airspeed at that moment are the reference. The recovery begins when the
+// warning stops and is complete once the aircraft climbs again. A warning
+// during the recovery is a secondary stall.
+type Evaluator struct {
+	spec          *maneuvers.Spec
+	recoveryClimb float64
+	attempt       *attempt
+}
+
+func New(spec *maneuvers.Spec) maneuvers.Evaluator {
+	return &Evaluator{
+		spec:          spec,
+		recoveryClimb: spec.Param("recovery_climb"),
+	}
+}
+
+func (ev *Evaluator) Vars() []telemetry.Var {
+	return []telemetry.Var{
+		{Name: "INDICATED ALTITUDE", Unit: "feet", Type: "float64", Moniker: monikerAltitude},
+		{Name: "AIRSPEED INDICATED", Unit: "knot", Type: "float64", Moniker: monikerAirspeed},
+		{Name: "VERTICAL SPEED", Unit: "ft/min", Type: "float64", Moniker: monikerVerticalSpeed},
+		{Name: "PLANE BANK DEGREES", Unit: "degrees", Type: "float64", Moniker: monikerBank},
+		{Name: "STALL WARNING", Unit: "bool", Type: "int32", Moniker: monikerStallWarning},
+		{Name: "SIM ON GROUND", Unit: "bool", Type: "int32", Moniker: monikerOnGround},
+	}
+}
+
+func (ev *Evaluator) Reset() {
+	ev.attempt = nil
+}
+
+func (ev *Evaluator) Evaluate(sample *telemetry.Sample) *maneuvers.Evaluation {
+	if !sample.Has(monikerAltitude, monikerAirspeed, monikerVerticalSpeed, monikerBank, monikerStallWarning) {
+		return nil
+	}
+	altitude, _ := sample.Float(monikerAltitude)
+	airspeed, _ := sample.Float(monikerAirspeed)
+	verticalSpeed, _ := sample.Float(monikerVerticalSpeed)
+	bank, _ := sample.Float(monikerBank)
+	stallWarning, _ := sample.Bool(monikerStallWarning)
+	onGround, _ := sample.Bool(monikerOnGround)
+
+	if ev.attempt == nil {
+		if !stallWarning || onGround {
+			return nil
+		}
+		ev.attempt = &attempt{
+			startedAt:      sample.Time,
+			altitude:       altitude,
+			airspeed:       airspeed,
+			minAltitude:    altitude,
+			warningSince:   sample.Time,
+			bank:           maneuvers.NewTracker(ev.spec, "bank"),
+			stallDuration:  maneuvers.NewTracker(ev.spec, "stall_duration"),
+			altitudeLoss:   maneuvers.NewTracker(ev.spec, "altitude_loss"),
+			secondaryStall: maneuvers.NewTracker(ev.spec, "secondary_stall"),
+			trace:          maneuvers.NewTrace(),
+		}
+	}
+
+	a := ev.attempt
+	a.minAltitude = math.Min(a.minAltitude, altitude)
+	if onGround {
+		return ev.complete(sample.Time, false)
+	}
+	if stallWarning {
+		if a.recovering {
+			a.recovering = false
+			a.secondary++
+			a.warningSince = sample.Time
+		} else if !a.clearSince.IsZero() {
+			// Flickered off for a moment.
+			a.warningTime -= a.clearSince.Sub(a.warningSince)
+		}
+		a.clearSince = time.Time{}
+	} else if !a.recovering {
+		if a.clearSince.IsZero() {
+			a.clearSince = sample.Time
+			a.warningTime += sample.Time.Sub(a.warningSince)
+		}
+		a.recovering = sample.Time.Sub(a.clearSince) >= warningDebounce
+	}
+	if a.recovering && verticalSpeed >= ev.recoveryClimb {
+		return ev.complete(sample.Time, true)
+	}
+
+	phase := PhaseStalled
+	if a.recovering {
+		phase = PhaseRecovery
+	}
+	deviations := []maneuvers.Deviation{
+		a.bank.Add(math.Abs(bank)),
+		a.stallDuration.Deviation(a.stallTime(sample.Time).Seconds()),
+		a.altitudeLoss.Deviation(a.altitude - a.minAltitude),
+		a.secondaryStall.Deviation(float64(a.secondary)),
+	}
+	warning := 0.0
+	if stallWarning {
+		warning = 1
+	}
+	a.trace.Add(maneuvers.TracePoint{
+		"t":                  maneuvers.Round(sample.Time.Sub(a.startedAt).Seconds(), 2),
+		monikerAltitude:      maneuvers.Round(altitude, 1),
+		monikerAirspeed:      maneuvers.Round(airspeed, 1),
+		monikerVerticalSpeed: maneuvers.Round(verticalSpeed, 0),
+		monikerBank:          maneuvers.Round(bank, 1),
+		monikerStallWarning:  warning,
+	})
+	return &maneuvers.Evaluation{Phase: phase, Deviations: deviations, Details: a.details(sample.Time)}
+}
+
+// stallTime returns how long the stall warning sounded so far.
+func (a *attempt) stallTime(t time.Time) time.Duration {
+	if a.clearSince.IsZero() {
+		return a.warningTime + t.Sub(a.warningSince)
+	}
+	return a.warningTime
+}
+
+func (ev *Evaluator) complete(t time.Time, recovered bool) *maneuvers.Evaluation {
+	a := ev.attempt
+	ev.attempt = nil
+
+	a.stallDuration.Add(a.stallTime(t).Seconds())
+	a.altitudeLoss.Add(a.altitude - a.minAltitude)
+	a.secondaryStall.Add(float64(a.secondary))
+	criteria := []maneuvers.Criterion{
+		a.bank.Criterion(),
+		a.stallDuration.Criterion(),
+		a.altitudeLoss.Criterion(),
+		a.secondaryStall.Criterion(),
+	}
+	result := maneuvers.NewResult(Maneuver, a.startedAt, t, recovered, criteria)
+	result.Details = a.details(t)
+	result.Trace = a.trace.Points()
+	return &maneuvers.Evaluation{Phase: maneuvers.PhaseCompleted, Details: result.Details, Result: result}
+}
+
+func (a *attempt) details(t time.Time) *Details {
+	return &Details{
+		Altitude:        maneuvers.Round(a.altitude, 1),
+		StallAirspeed:   maneuvers.Round(a.airspeed, 1),
+		AltitudeLoss:    maneuvers.Round(a.altitude-a.minAltitude, 1),
+		StallDuration:   maneuvers.Round(a.stallTime(t).Seconds(), 1),
+		SecondaryStalls: a.secondary,
+	}
+}
@@ -0,0 +1,117 @@
+package stalls
+
+import (
+	"testing"
+
+	"msfs2020-gopilot/internal/maneuvers/maneuvertest"
+)
+
+func warning(on bool) func(s *maneuvertest.State) {
+	return func(s *maneuvertest.State) {
+		s.StallWarning = on
+	}
+}
+
+func verticalSpeed(fpm float64) func(s *maneuvertest.State) {
+	return func(s *maneuvertest.State) {
+		s.VerticalSpeed = fpm
+	}
+}
+
+// approach flies towards the stall until the stall warning sounds.
+func approach() *maneuvertest.Flight {
+	return maneuvertest.NewFlight(maneuvertest.State{Altitude: 4000, Airspeed: 50, VerticalSpeed: -200}).
+		Hold(5).
+		Set(warning(true))
+}
+
+// recover lowers the nose and climbs again.
+func recover(flight *maneuvertest.Flight) *maneuvertest.Flight {
+	return flight.Set(warning(false)).Set(verticalSpeed(-600)).Hold(3).Set(verticalSpeed(300)).Hold(2)
+}
+
+func TestStall(t *testing.T) {
+	flight := recover(approach().Hold(2))
+	phases, results := maneuvertest.Evaluate(New(DefaultSpec()), flight.Samples)
+
+	if want := []string{PhaseStalled, PhaseRecovery, "completed"}; !maneuvertest.EqualStrings(phases, want) {
+		t.Errorf("phases %v, want %v", phases, want)
+	}
+	if len(results) != 1 {
+		t.Fatalf("%d results, want 1", len(results))
+	}
+	result := results[0]
+	if !result.Complete || !result.Passed {
+		t.Errorf("result complete: %t, passed: %t, criteria %+v, want a passed recovery", result.Complete, result.Passed, result.Criteria)
+	}
+	details := result.Details.(*Details)
+	// 6.7 ft with the warning and 30 ft in the recovery.
+	if details.StallDuration != 2 || details.AltitudeLoss < 35 || details.AltitudeLoss > 38 || details.SecondaryStalls != 0 {
+		t.Errorf("details %+v", details)
+	}
+}
+
+func TestStallFailed(t *testing.T) {
+	tests := []struct {
+		name     string
+		flight   *maneuvertest.Flight
+		complete bool
+		failed   string
+	}{
+		{
+			name:     "late recovery",
+			flight:   recover(approach().Hold(5)),
+			complete: true,
+			failed:   "stall_duration",
+		},
+		{
+			name:     "altitude lost",
+			flight:   recover(approach().Hold(1).Set(warning(false)).Set(verticalSpeed(-2000)).Hold(7)),
+			complete: true,
+			failed:   "altitude_loss",
+		},
+		{
+			name:     "secondary stall",
+			flight:   recover(approach().Hold(1).Set(warning(false)).Hold(2).Set(warning(true)).Hold(1)),
+			complete: true,
+			failed:   "secondary_stall",
+		},
+		{
+			name:     "steep bank",
+			flight:   recover(approach().Roll(1, 35).Hold(1)),
+			complete: true,
+			failed:   "bank",
+		},
+		{
+			name:   "crashed",
+			flight: approach().Hold(2).Set(warning(false)).Hold(3).Set(func(s *maneuvertest.State) { s.OnGround = true }).Hold(1),
+		},
+	}
+	for _, test := range tests {
+		_, results := maneuvertest.Evaluate(New(DefaultSpec()), test.flight.Samples)
+		if len(results) != 1 {
+			t.Errorf("%s: %d results, want 1", test.name, len(results))
+			continue
+		}
+		result := results[0]
+		if result.Passed || result.Complete != test.complete {
+			t.Errorf("%s: result complete: %t, passed: %t, want failed and complete: %t", test.name, result.Complete, result.Passed, test.complete)
+		}
+		if test.failed != "" && maneuvertest.Criterion(result, test.failed).Passed {
+			t.Errorf("%s: %s passed: %+v", test.name, test.failed, result.Criteria)
+		}
+	}
+}
+
+// A stall warning which is off for less than a second is one stall.
+func TestFlickeringWarning(t *testing.T) {
+	flight := recover(approach().Hold(1).Set(warning(false)).Hold(0.5).Set(warning(true)).Hold(1))
+	_, results := maneuvertest.Evaluate(New(DefaultSpec()), flight.Samples)
+	if len(results) != 1 {
+		t.Fatalf("%d results, want 1", len(results))
+	}
+	details := results[0].Details.(*Details)
+	if details.SecondaryStalls != 0 || details.StallDuration != 2.5 || !results[0].Passed {
+		t.Errorf("details %+v, criteria %+v", details, results[0].Criteria)
+	}
+}
@@ -0,0 +1,230 @@
+package steepturns
+
+import (
+	"math"
+	"time"
+
+	"msfs2020-gopilot/internal/maneuvers"
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+const (
+	Maneuver = "steep_turn"
+
+	PhaseEntry   = "entry"
+	PhaseTurning = "turning"
+	PhaseRollout = "rollout"
+
+	DirectionLeft  = "left"
+	DirectionRight = "right"
+
+	monikerAltitude = "altitude"
+	monikerAirspeed = "airspeed"
+	monikerBank     = "bank"
+	monikerHeading  = "heading"
+)
+
+// DefaultSpec uses the tolerances of the FAA Private Pilot ACS (Task V.A,
+// Steep Turns).
+func DefaultSpec() *maneuvers.Spec {
+	return &maneuvers.Spec{
+		Name:        Maneuver,
+		Title:       "Steep Turns",
+		Description: "360° turn at 45° bank, rolling out on the entry heading",
+		Parameters: map[string]float64{
+			"target_bank":      45,  // degrees
+			"wings_level_bank": 5,   // degrees; below this the wings are considered level
+			"min_turn_angle":   270, // degrees; shorter turns are incomplete
+			"rollout_lead":     30,  // degrees before the entry heading at which the rollout may begin
+		},
+		Tolerances: map[string]maneuvers.Tolerance{
+			"altitude":        maneuvers.Symmetric(100, "feet"),
+			"airspeed":        maneuvers.Symmetric(10, "knots"),
+			"bank":            maneuvers.Symmetric(5, "degrees"),
+			"rollout_heading": maneuvers.Symmetric(10, "degrees"),
+		},
+	}
+}
+
+type Reference struct {
+	Altitude float64 `json:"altitude"`
+	Airspeed float64 `json:"airspeed"`
+	Heading  float64 `json:"heading"`
+}
+
+type Details struct {
+	Direction  string    `json:"direction"`
+	Entry      Reference `json:"entry"`
+	TargetBank float64   `json:"targetBank"`
+	TurnAngle  float64   `json:"turnAngle"`
+}
+
+type attempt struct {
+	startedAt   time.Time
+	direction   float64 // -1: right, +1: left
+	entry       Reference
+	lastHeading float64
+	turnAngle   float64
+	established bool
+	altitude    *maneuvers.Tracker
+	airspeed    *maneuvers.Tracker
+	bank        *maneuvers.Tracker
+	rollout     *maneuvers.Tracker
+	trace       *maneuvers.Trace
+}
+
+// Evaluator detects steep turns in the SimVar stream and grades them. The entry
+// reference (altitude, airspeed, heading) is taken from the last wings-level
+// sample before the aircraft rolls into the turn. An attempt ends when the
+// wings are level again.
+type Evaluator struct {
+	spec           *maneuvers.Spec
+	targetBank     float64
+	entryBank      float64
+	wingsLevelBank float64
+	minTurnAngle   float64
+	rolloutLead    float64
+	attempt        *attempt
+	wingsLevel     *telemetry.Sample
+}
+
+func New(spec *maneuvers.Spec) maneuvers.Evaluator {
+	return &Evaluator{
+		spec:           spec,
+		targetBank:     spec.Param("target_bank"),
+		entryBank:      spec.Param("target_bank") - spec.Tolerance("bank").Below,
+		wingsLevelBank: spec.Param("wings_level_bank"),
+		minTurnAngle:   spec.Param("min_turn_angle"),
+		rolloutLead:    spec.Param("rollout_lead"),
+	}
+}
+
+func (ev *Evaluator) Vars() []telemetry.Var {
+	return []telemetry.Var{
+		{Name: "INDICATED ALTITUDE", Unit: "feet", Type: "float64", Moniker: monikerAltitude},
+		{Name: "AIRSPEED INDICATED", Unit: "knot", Type: "float64", Moniker: monikerAirspeed},
+		{Name: "PLANE BANK DEGREES", Unit: "degrees", Type: "float64", Moniker: monikerBank},
+		{Name: "PLANE HEADING DEGREES MAGNETIC", Unit: "degrees", Type: "float64", Moniker: monikerHeading},
+	}
+}
+
+func (ev *Evaluator) Reset() {
+	ev.attempt = nil
+	ev.wingsLevel = nil
+}
+
+func (ev *Evaluator) Evaluate(sample *telemetry.Sample) *maneuvers.Evaluation {
+	if !sample.Has(monikerAltitude, monikerAirspeed, monikerBank, monikerHeading) {
+		return nil
+	}
+	altitude, _ := sample.Float(monikerAltitude)
+	airspeed, _ := sample.Float(monikerAirspeed)
+	bank, _ := sample.Float(monikerBank)
+	heading, _ := sample.Float(monikerHeading)
+	absBank := math.Abs(bank)
+
+	if ev.attempt == nil {
+		if absBank < ev.wingsLevelBank {
+			ev.wingsLevel = sample
+			return nil
+		}
+		if absBank < ev.entryBank || ev.wingsLevel == nil {
+			return nil
+		}
+		ev.start(sample, bank)
+	}
+
+	a := ev.attempt
+	a.turnAngle += a.direction * maneuvers.HeadingDelta(a.lastHeading, heading)
+	a.lastHeading = heading
+
+	if !a.established && absBank >= ev.entryBank && a.direction*bank > 0 {
+		a.established = true
+	}
+	phase := PhaseEntry
+	if a.established {
+		phase = PhaseTurning
+		if a.turnAngle >= 360-ev.rolloutLead {
+			phase = PhaseRollout
+		}
+	}
+
+	deviations := []maneuvers.Deviation{
+		a.altitude.Add(altitude - a.entry.Altitude),
+		a.airspeed.Add(airspeed - a.entry.Airspeed),
+	}
+	// Bank is only graded while established in the turn.
+	if phase == PhaseTurning && absBank >= ev.wingsLevelBank {
+		deviations = append(deviations, a.bank.Add(absBank-ev.targetBank))
+	} else {
+		deviations = append(deviations, a.bank.Deviation(absBank-ev.targetBank))
+	}
+	a.trace.Add(maneuvers.TracePoint{
+		"t":             maneuvers.Round(sample.Time.Sub(a.startedAt).Seconds(), 2),
+		monikerAltitude: maneuvers.Round(altitude, 1),
+		monikerAirspeed: maneuvers.Round(airspeed, 1),
+		monikerBank:     maneuvers.Round(bank, 1),
+		monikerHeading:  maneuvers.Round(heading, 1),
+	})
+
+	if absBank < ev.wingsLevelBank {
+		result := ev.complete(sample.Time, heading)
+		ev.wingsLevel = sample
+		return &maneuvers.Evaluation{Phase: maneuvers.PhaseCompleted, Details: result.Details, Result: result}
+	}
+	return &maneuvers.Evaluation{Phase: phase, Deviations: deviations, Details: a.details(ev.targetBank)}
+}
+
+func (ev *Evaluator) start(sample *telemetry.Sample, bank float64) {
+	ref := ev.wingsLevel
+	altitude, _ := ref.Float(monikerAltitude)
+	airspeed, _ := ref.Float(monikerAirspeed)
+	heading, _ := ref.Float(monikerHeading)
+	direction := 1.0
+	if bank < 0 {
+		direction = -1.0
+	}
+	ev.attempt = &attempt{
+		startedAt:   ref.Time,
+		direction:   direction,
+		entry:       Reference{Altitude: altitude, Airspeed: airspeed, Heading: heading},
+		lastHeading: heading,
+		altitude:    maneuvers.NewTracker(ev.spec, "altitude"),
+		airspeed:    maneuvers.NewTracker(ev.spec, "airspeed"),
+		bank:        maneuvers.NewTracker(ev.spec, "bank"),
+		rollout:     maneuvers.NewTracker(ev.spec, "rollout_heading"),
+		trace:       maneuvers.NewTrace(),
+	}
+}
+
+func (ev *Evaluator) complete(t time.Time, heading float64) *maneuvers.Result {
+	a := ev.attempt
+	ev.attempt = nil
+
+	// Positive rollout deviations are overshoots, negative ones undershoots.
+	a.rollout.Add(a.direction * maneuvers.HeadingDelta(a.entry.Heading, heading))
+	criteria := []maneuvers.Criterion{
+		a.altitude.Criterion(),
+		a.airspeed.Criterion(),
+		a.bank.Criterion(),
+		a.rollout.Criterion(),
+	}
+	complete := a.established && a.turnAngle >= ev.minTurnAngle
+	result := maneuvers.NewResult(Maneuver, a.startedAt, t, complete, criteria)
+	result.Details = a.details(ev.targetBank)
+	result.Trace = a.trace.Points()
+	return result
+}
+
+func (a *attempt) details(targetBank float64) *Details {
+	direction := DirectionLeft
+	if a.direction < 0 {
+		direction = DirectionRight
+	}
+	return &Details{
+		Direction:  direction,
+		Entry:      a.entry,
+		TargetBank: targetBank,
+		TurnAngle:  maneuvers.Round(a.turnAngle, 1),
+	}
+}
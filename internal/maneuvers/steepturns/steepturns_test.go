@@ -0,0 +1,100 @@
+package steepturns
+
+import (
+	"math"
+	"testing"
+
+	"msfs2020-gopilot/internal/maneuvers/maneuvertest"
+)
+
+func level() *maneuvertest.Flight {
+	return maneuvertest.NewFlight(maneuvertest.State{Altitude: 3000, Airspeed: 100, Heading: 90}).Hold(5)
+}
+
+// At 45° of bank and 100 kt, the aircraft turns 360° in about 33 s including
+// the roll in and out.
+func TestSteepTurn(t *testing.T) {
+	flight := level().Roll(4, 45).Hold(29.5).Roll(4, 0).Hold(5)
+	phases, results := maneuvertest.Evaluate(New(DefaultSpec()), flight.Samples)
+
+	if want := []string{PhaseTurning, PhaseRollout, "completed"}; !maneuvertest.EqualStrings(phases, want) {
+		t.Errorf("phases %v, want %v", phases, want)
+	}
+	if len(results) != 1 {
+		t.Fatalf("%d results, want 1", len(results))
+	}
+	result := results[0]
+	if !result.Complete || !result.Passed || result.Score < 90 {
+		t.Errorf("result complete: %t, passed: %t, criteria %+v, want a passed turn", result.Complete, result.Passed, result.Criteria)
+	}
+	details := result.Details.(*Details)
+	if details.Direction != DirectionLeft || math.Abs(details.Entry.Heading-90) > 1 || details.TurnAngle < 355 || details.TurnAngle > 365 {
+		t.Errorf("details %+v", details)
+	}
+	if len(result.Trace) == 0 || result.Trace[0]["t"] <= 0 {
+		t.Errorf("trace starts with %v", result.Trace[0])
+	}
+}
+
+func TestSteepTurnFailed(t *testing.T) {
+	tests := []struct {
+		name     string
+		flight   *maneuvertest.Flight
+		complete bool
+		failed   string
+	}{
+		{
+			name: "altitude lost",
+			flight: level().Roll(4, 45).Set(func(s *maneuvertest.State) {
+				s.VerticalSpeed = -400
+			}).Hold(29.5).Set(func(s *maneuvertest.State) {
+				s.VerticalSpeed = 0
+			}).Roll(4, 0).Hold(1),
+			complete: true,
+			failed:   "altitude",
+		},
+		{
+			name:     "shallow",
+			flight:   level().Roll(4, -43).Roll(1, -38).Hold(35).Roll(4, 0).Hold(1),
+			complete: true,
+			failed:   "bank",
+		},
+		{
+			name:     "overshoot",
+			flight:   level().Roll(4, -45).Hold(31.5).Roll(4, 0).Hold(1),
+			complete: true,
+			failed:   "rollout_heading",
+		},
+		{
+			name:   "half a turn",
+			flight: level().Roll(4, 45).Hold(13).Roll(4, 0).Hold(1),
+		},
+	}
+	for _, test := range tests {
+		_, results := maneuvertest.Evaluate(New(DefaultSpec()), test.flight.Samples)
+		if len(results) != 1 {
+			t.Errorf("%s: %d results, want 1", test.name, len(results))
+			continue
+		}
+		result := results[0]
+		if result.Passed || result.Complete != test.complete {
+			t.Errorf("%s: result complete: %t, passed: %t, want failed and complete: %t", test.name, result.Complete, result.Passed, test.complete)
+		}
+		if test.failed != "" && maneuvertest.Criterion(result, test.failed).Passed {
+			t.Errorf("%s: %s passed: %+v", test.name, test.failed, result.Criteria)
+		}
+	}
+}
+
+// Shallow banks and turns from a bank which was never level are ignored.
+func TestNoSteepTurn(t *testing.T) {
+	flights := []*maneuvertest.Flight{
+		level().Roll(4, 30).Hold(60).Roll(4, 0),
+		maneuvertest.NewFlight(maneuvertest.State{Altitude: 3000, Airspeed: 100, Bank: 20}).Roll(2, 45).Hold(10),
+	}
+	for i, flight := range flights {
+		if phases, _ := maneuvertest.Evaluate(New(DefaultSpec()), flight.Samples); len(phases) != 0 {
+			t.Errorf("flight %d: phases %v", i, phases)
+		}
+	}
+}
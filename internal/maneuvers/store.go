@@ -0,0 +1,112 @@
+package maneuvers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const resultFileExt = ".json"
+
+var (
+	ErrNotFound = errors.New("result not found")
+
+	validResultID = regexp.MustCompile(`^[0-9a-zA-Z_-]+$`)
+)
+
+// Store persists one JSON file per attempt.
+type Store struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (store *Store) Dir() string {
+	return store.dir
+}
+
+func (store *Store) Save(result *Result) error {
+	if !validResultID.MatchString(result.ID) {
+		return fmt.Errorf("invalid result id %q", result.ID)
+	}
+	buf, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	// Write to a temporary file first so that readers never see partial results.
+	path := store.path(result.ID)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (store *Store) Get(id string) (*Result, error) {
+	if !validResultID.MatchString(id) {
+		return nil, ErrNotFound
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return store.load(store.path(id))
+}
+
+// List returns the summaries of the stored results of a maneuver, or of all
+// maneuvers if maneuver is empty, newest first.
+func (store *Store) List(maneuver string) ([]*Result, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	entries, err := ioutil.ReadDir(store.dir)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*Result, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), resultFileExt) {
+			continue
+		}
+		result, err := store.load(filepath.Join(store.dir, entry.Name()))
+		if err != nil || (maneuver != "" && result.Maneuver != maneuver) {
+			continue
+		}
+		results = append(results, result.Summary())
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].StartedAt.After(results[j].StartedAt)
+	})
+	return results, nil
+}
+
+func (store *Store) load(path string) (*Result, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	result := &Result{}
+	if err := json.Unmarshal(buf, result); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+	return result, nil
+}
+
+func (store *Store) path(id string) string {
+	return filepath.Join(store.dir, id+resultFileExt)
+}
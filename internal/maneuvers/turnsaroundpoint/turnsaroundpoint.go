@@ -0,0 +1,309 @@
+package turnsaroundpoint
+
+import (
+	"math"
+	"time"
+
+	"msfs2020-gopilot/internal/geo"
+	"msfs2020-gopilot/internal/maneuvers"
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+const (
+	Maneuver = "turns_around_point"
+
+	PhaseTurning = "turning"
+
+	DirectionLeft  = "left"
+	DirectionRight = "right"
+
+	// A position is recorded for every few degrees of the turn, so the
+	// circle fit is not biased towards the upwind side where the aircraft
+	// is slower over the ground.
+	positionSpacing = 5.0 // degrees
+
+	monikerAltitude  = "altitude"
+	monikerAirspeed  = "airspeed"
+	monikerBank      = "bank"
+	monikerHeading   = "heading"
+	monikerLatitude  = "latitude"
+	monikerLongitude = "longitude"
+)
+
+// DefaultSpec uses the tolerances of the FAA Private Pilot ACS (Task V.B,
+// Ground Reference Maneuvering): altitude ±100 ft, airspeed ±10 kt and a bank
+// of at most 45°. The ACS asks for a constant radius without a number; 200 ft
+// is a common training goal.
+func DefaultSpec() *maneuvers.Spec {
+	return &maneuvers.Spec{
+		Name:        Maneuver,
+		Title:       "Turns Around a Point",
+		Description: "Two or more turns at a constant radius around a point on the ground, correcting for wind",
+		Parameters: map[string]float64{
+			"entry_bank":       10,  // degrees; rolling past this from wings level starts an attempt
+			"wings_level_bank": 5,   // degrees; below this the attempt ends
+			"min_turn_angle":   720, // degrees; fewer turns are incomplete
+		},
+		Tolerances: map[string]maneuvers.Tolerance{
+			"altitude": maneuvers.Symmetric(100, "feet"),
+			"airspeed": maneuvers.Symmetric(10, "knots"),
+			"bank":     {Unit: "degrees", Below: 0, Above: 45},
+			"radius":   maneuvers.Symmetric(200, "feet"),
+		},
+	}
+}
+
+type Reference struct {
+	Altitude float64 `json:"altitude"`
+	Airspeed float64 `json:"airspeed"`
+	Heading  float64 `json:"heading"`
+}
+
+// Point is the center of the turns, as fitted to the flown positions when the
+// attempt is completed.
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Radius    float64 `json:"radius"` // feet
+}
+
+type Details struct {
+	Direction string    `json:"direction"`
+	Entry     Reference `json:"entry"`
+	TurnAngle float64   `json:"turnAngle"`
+	Point     *Point    `json:"point,omitempty"`
+}
+
+// position is relative to the entry position, in feet north and east.
+type position struct {
+	north float64
+	east  float64
+}
+
+type attempt struct {
+	startedAt    time.Time
+	direction    float64 // -1: right, +1: left
+	entry        Reference
+	entryLat     float64
+	entryLon     float64
+	lastHeading  float64
+	turnAngle    float64
+	lastPosition float64 // turn angle of the last recorded position
+	positions    []position
+	point        *Point
+	altitude     *maneuvers.Tracker
+	airspeed     *maneuvers.Tracker
+	bank         *maneuvers.Tracker
+	radius       *maneuvers.Tracker
+	trace        *maneuvers.Trace
+}
+
+// Evaluator detects the turns like steep turns: the last wings-level sample
+// before the aircraft rolls into the first turn is the reference for altitude
+// and airspeed, and the attempt ends when the wings are level again. The point
+// is not known in advance, so the radius is graded against the circle which
+// fits the flown positions best.
+type Evaluator struct {
+	spec           *maneuvers.Spec
+	entryBank      float64
+	wingsLevelBank float64
+	minTurnAngle   float64
+	attempt        *attempt
+	wingsLevel     *telemetry.Sample
+}
+
+func New(spec *maneuvers.Spec) maneuvers.Evaluator {
+	return &Evaluator{
+		spec:           spec,
+		entryBank:      spec.Param("entry_bank"),
+		wingsLevelBank: spec.Param("wings_level_bank"),
+		minTurnAngle:   spec.Param("min_turn_angle"),
+	}
+}
+
+func (ev *Evaluator) Vars() []telemetry.Var {
+	return []telemetry.Var{
+		{Name: "INDICATED ALTITUDE", Unit: "feet", Type: "float64", Moniker: monikerAltitude},
+		{Name: "AIRSPEED INDICATED", Unit: "knot", Type: "float64", Moniker: monikerAirspeed},
+		{Name: "PLANE BANK DEGREES", Unit: "degrees", Type: "float64", Moniker: monikerBank},
+		{Name: "PLANE HEADING DEGREES MAGNETIC", Unit: "degrees", Type: "float64", Moniker: monikerHeading},
+		{Name: "PLANE LATITUDE", Unit: "degrees", Type: "float64", Moniker: monikerLatitude},
+		{Name: "PLANE LONGITUDE", Unit: "degrees", Type: "float64", Moniker: monikerLongitude},
+	}
+}
+
+func (ev *Evaluator) Reset() {
+	ev.attempt = nil
+	ev.wingsLevel = nil
+}
+
+func (ev *Evaluator) Evaluate(sample *telemetry.Sample) *maneuvers.Evaluation {
+	if !sample.Has(monikerAltitude, monikerAirspeed, monikerBank, monikerHeading, monikerLatitude, monikerLongitude) {
+		return nil
+	}
+	altitude, _ := sample.Float(monikerAltitude)
+	airspeed, _ := sample.Float(monikerAirspeed)
+	bank, _ := sample.Float(monikerBank)
+	heading, _ := sample.Float(monikerHeading)
+	latitude, _ := sample.Float(monikerLatitude)
+	longitude, _ := sample.Float(monikerLongitude)
+	absBank := math.Abs(bank)
+
+	if ev.attempt == nil {
+		if absBank < ev.wingsLevelBank {
+			ev.wingsLevel = sample
+			return nil
+		}
+		if absBank < ev.entryBank || ev.wingsLevel == nil {
+			return nil
+		}
+		ev.start(bank)
+	}
+
+	a := ev.attempt
+	a.turnAngle += a.direction * maneuvers.HeadingDelta(a.lastHeading, heading)
+	a.lastHeading = heading
+	if math.Abs(a.turnAngle-a.lastPosition) >= positionSpacing {
+		a.addPosition(latitude, longitude)
+		a.lastPosition = a.turnAngle
+	}
+
+	if absBank < ev.wingsLevelBank {
+		result := ev.complete(sample.Time)
+		ev.wingsLevel = sample
+		return &maneuvers.Evaluation{Phase: maneuvers.PhaseCompleted, Details: result.Details, Result: result}
+	}
+
+	deviations := []maneuvers.Deviation{
+		a.altitude.Add(altitude - a.entry.Altitude),
+		a.airspeed.Add(airspeed - a.entry.Airspeed),
+		a.bank.Add(absBank),
+	}
+	a.trace.Add(maneuvers.TracePoint{
+		"t":              maneuvers.Round(sample.Time.Sub(a.startedAt).Seconds(), 2),
+		monikerAltitude:  maneuvers.Round(altitude, 1),
+		monikerAirspeed:  maneuvers.Round(airspeed, 1),
+		monikerBank:      maneuvers.Round(bank, 1),
+		monikerHeading:   maneuvers.Round(heading, 1),
+		monikerLatitude:  maneuvers.Round(latitude, 6),
+		monikerLongitude: maneuvers.Round(longitude, 6),
+	})
+	return &maneuvers.Evaluation{Phase: PhaseTurning, Deviations: deviations, Details: a.details()}
+}
+
+func (ev *Evaluator) start(bank float64) {
+	ref := ev.wingsLevel
+	altitude, _ := ref.Float(monikerAltitude)
+	airspeed, _ := ref.Float(monikerAirspeed)
+	heading, _ := ref.Float(monikerHeading)
+	latitude, _ := ref.Float(monikerLatitude)
+	longitude, _ := ref.Float(monikerLongitude)
+	direction := 1.0
+	if bank < 0 {
+		direction = -1.0
+	}
+	ev.attempt = &attempt{
+		startedAt:   ref.Time,
+		direction:   direction,
+		entry:       Reference{Altitude: altitude, Airspeed: airspeed, Heading: heading},
+		entryLat:    latitude,
+		entryLon:    longitude,
+		lastHeading: heading,
+		altitude:    maneuvers.NewTracker(ev.spec, "altitude"),
+		airspeed:    maneuvers.NewTracker(ev.spec, "airspeed"),
+		bank:        maneuvers.NewTracker(ev.spec, "bank"),
+		radius:      maneuvers.NewTracker(ev.spec, "radius"),
+		positions:   make([]position, 0, 2*360/positionSpacing),
+		trace:       maneuvers.NewTrace(),
+	}
+}
+
+func (a *attempt) addPosition(latitude, longitude float64) {
+	north, east := geo.AlongAcross(a.entryLat, a.entryLon, 0, latitude, longitude)
+	a.positions = append(a.positions, position{north: geo.MetersToFeet(north), east: geo.MetersToFeet(east)})
+}
+
+func (ev *Evaluator) complete(t time.Time) *maneuvers.Result {
+	a := ev.attempt
+	ev.attempt = nil
+
+	complete := a.turnAngle >= ev.minTurnAngle
+	if center, radius, ok := fitCircle(a.positions); ok {
+		for _, p := range a.positions {
+			a.radius.Add(math.Hypot(p.north-center.north, p.east-center.east) - radius)
+		}
+		north := geo.FeetToMeters(center.north) / geo.EarthRadius
+		east := geo.FeetToMeters(center.east) / (geo.EarthRadius * math.Cos(geo.DegToRad(a.entryLat)))
+		a.point = &Point{
+			Latitude:  maneuvers.Round(a.entryLat+geo.RadToDeg(north), 6),
+			Longitude: maneuvers.Round(a.entryLon+geo.RadToDeg(east), 6),
+			Radius:    maneuvers.Round(radius, 0),
+		}
+	} else {
+		complete = false
+	}
+	criteria := []maneuvers.Criterion{
+		a.altitude.Criterion(),
+		a.airspeed.Criterion(),
+		a.bank.Criterion(),
+		a.radius.Criterion(),
+	}
+	result := maneuvers.NewResult(Maneuver, a.startedAt, t, complete, criteria)
+	result.Details = a.details()
+	result.Trace = a.trace.Points()
+	return result
+}
+
+func (a *attempt) details() *Details {
+	direction := DirectionLeft
+	if a.direction < 0 {
+		direction = DirectionRight
+	}
+	return &Details{
+		Direction: direction,
+		Entry:     a.entry,
+		TurnAngle: maneuvers.Round(a.turnAngle, 1),
+		Point:     a.point,
+	}
+}
+
+// fitCircle returns the circle which fits the positions best in the least
+// squares sense (Kåsa's method). It fails for fewer than three positions or if
+// they are on a line.
+func fitCircle(positions []position) (position, float64, bool) {
+	if len(positions) < 3 {
+		return position{}, 0, false
+	}
+	// The circle x² + y² + D·x + E·y + F = 0 leads to a linear system for D,
+	// E and F.
+	var sxx, sxy, syy, sx, sy, sxz, syz, sz float64
+	n := float64(len(positions))
+	for _, p := range positions {
+		x, y := p.east, p.north
+		z := x*x + y*y
+		sxx += x * x
+		sxy += x * y
+		syy += y * y
+		sx += x
+		sy += y
+		sxz += x * z
+		syz += y * z
+		sz += z
+	}
+	det := func(a, b, c, d, e, f, g, h, i float64) float64 {
+		return a*(e*i-f*h) - b*(d*i-f*g) + c*(d*h-e*g)
+	}
+	m := det(sxx, sxy, sx, sxy, syy, sy, sx, sy, n)
+	if math.Abs(m) < 1e-9*math.Max(1, sxx*syy*n) {
+		return position{}, 0, false
+	}
+	d := det(-sxz, sxy, sx, -syz, syy, sy, -sz, sy, n) / m
+	e := det(sxx, -sxz, sx, sxy, -syz, sy, sx, -sz, n) / m
+	f := det(sxx, sxy, -sxz, sxy, syy, -syz, sx, sy, -sz) / m
+	center := position{east: -d / 2, north: -e / 2}
+	r2 := center.east*center.east + center.north*center.north - f
+	if r2 <= 0 {
+		return position{}, 0, false
+	}
+	return center, math.Sqrt(r2), true
+}
@@ -0,0 +1,107 @@
+package turnsaroundpoint
+
+import (
+	"math"
+	"testing"
+
+	"msfs2020-gopilot/internal/maneuvers/maneuvertest"
+)
+
+// circle flies turns to the left at 100 kt without wind. bank may change the
+// bank during the turns.
+func circle(seconds float64, bank func(elapsed float64) float64) *maneuvertest.Flight {
+	flight := maneuvertest.NewFlight(maneuvertest.State{Altitude: 1000, Airspeed: 100, Latitude: 47, Longitude: 8}).Hold(3).Roll(2, 30)
+	return flight.Fly(seconds, func(s *maneuvertest.State, elapsed float64) {
+		s.Bank = bank(elapsed)
+	}).Roll(2, 0).Hold(2)
+}
+
+func constant(float64) float64 {
+	return 30
+}
+
+func TestTurnsAroundPoint(t *testing.T) {
+	// Two turns at a rate of 6.3°/s.
+	phases, results := maneuvertest.Evaluate(New(DefaultSpec()), circle(116, constant).Samples)
+
+	if want := []string{PhaseTurning, "completed"}; !maneuvertest.EqualStrings(phases, want) {
+		t.Errorf("phases %v, want %v", phases, want)
+	}
+	if len(results) != 1 {
+		t.Fatalf("%d results, want 1", len(results))
+	}
+	result := results[0]
+	if !result.Complete || !result.Passed {
+		t.Errorf("result complete: %t, passed: %t, criteria %+v, want passed turns", result.Complete, result.Passed, result.Criteria)
+	}
+	details := result.Details.(*Details)
+	if details.Direction != DirectionLeft || details.TurnAngle < 720 || details.TurnAngle > 760 {
+		t.Errorf("details %+v", details)
+	}
+	// The radius of a 30° bank turn at 100 kt is about 1535 ft (0.0062° of
+	// longitude), to the west of the entry on a northerly heading and a bit
+	// north of it because of the roll-in.
+	point := details.Point
+	if point == nil || math.Abs(point.Radius-1535) > 50 || math.Abs(point.Longitude-7.9938) > 0.0002 || point.Latitude < 47 || point.Latitude > 47.0025 {
+		t.Errorf("point %+v", point)
+	}
+	if radius := maneuvertest.Criterion(result, "radius"); math.Max(-radius.MinDeviation, radius.MaxDeviation) > 50 {
+		t.Errorf("radius criterion %+v", radius)
+	}
+}
+
+func TestTurnsAroundPointFailed(t *testing.T) {
+	tests := []struct {
+		name     string
+		seconds  float64
+		bank     func(elapsed float64) float64
+		complete bool
+		failed   string
+	}{
+		{
+			name:    "varying radius",
+			seconds: 116,
+			// A bank between 20° and 40° makes the radius vary between
+			// 1040 and 2180 ft.
+			bank:     func(elapsed float64) float64 { return 30 + 10*math.Sin(elapsed/5) },
+			complete: true,
+			failed:   "radius",
+		},
+		{name: "steep", seconds: 90, bank: func(float64) float64 { return 50 }, complete: true, failed: "bank"},
+		{name: "one turn", seconds: 58, bank: constant},
+	}
+	for _, test := range tests {
+		_, results := maneuvertest.Evaluate(New(DefaultSpec()), circle(test.seconds, test.bank).Samples)
+		if len(results) != 1 {
+			t.Errorf("%s: %d results, want 1", test.name, len(results))
+			continue
+		}
+		result := results[0]
+		if result.Passed || result.Complete != test.complete {
+			t.Errorf("%s: result complete: %t, passed: %t, want failed and complete: %t", test.name, result.Complete, result.Passed, test.complete)
+		}
+		if test.failed != "" && maneuvertest.Criterion(result, test.failed).Passed {
+			t.Errorf("%s: %s passed: %+v", test.name, test.failed, result.Criteria)
+		}
+	}
+}
+
+func TestFitCircle(t *testing.T) {
+	positions := make([]position, 0)
+	for angle := 0.0; angle < 360; angle += 30 {
+		rad := angle * math.Pi / 180
+		positions = append(positions, position{north: 300 + 1000*math.Cos(rad), east: -200 + 1000*math.Sin(rad)})
+	}
+	center, radius, ok := fitCircle(positions)
+	if !ok || math.Abs(center.north-300) > 1e-6 || math.Abs(center.east+200) > 1e-6 || math.Abs(radius-1000) > 1e-6 {
+		t.Errorf("circle around %+v with a radius of %g (%t), want around {300 -200} with 1000", center, radius, ok)
+	}
+
+	line := []position{{north: 0, east: 0}, {north: 100, east: 50}, {north: 200, east: 100}, {north: 300, east: 150}}
+	if _, _, ok := fitCircle(line); ok {
+		t.Error("circle through positions on a line")
+	}
+	if _, _, ok := fitCircle(positions[:2]); ok {
+		t.Error("circle through two positions")
+	}
+}
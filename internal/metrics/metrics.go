@@ -0,0 +1,362 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Minimal implementation of the Prometheus text exposition format (version 0.0.4).
+// https://prometheus.io/docs/instrumenting/exposition_formats/
+
+const (
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+	labelSep      = "\xff"
+)
+
+var (
+	DefaultBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5}
+
+	metricNameRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRegexp  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Collector is one of the metrics of this package.
+type Collector interface {
+	describe() *desc
+	write(w io.Writer)
+}
+
+type Registry struct {
+	collectors []Collector
+	names      map[string]bool
+	mutex      sync.Mutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		collectors: make([]Collector, 0),
+		names:      make(map[string]bool),
+	}
+}
+
+// Register adds a metric to the exposition. Metrics are written in the order
+// they were registered. It fails for invalid and duplicate names.
+func (reg *Registry) Register(c Collector) error {
+	d := c.describe()
+	if !metricNameRegexp.MatchString(d.name) {
+		return fmt.Errorf("metrics: invalid metric name %q", d.name)
+	}
+	for _, label := range d.labels {
+		if !labelNameRegexp.MatchString(label) || strings.HasPrefix(label, "__") || (d.typ == typeHistogram && label == "le") {
+			return fmt.Errorf("metrics: invalid label name %q of %s", label, d.name)
+		}
+	}
+	reg.mutex.Lock()
+	defer reg.mutex.Unlock()
+	if reg.names[d.name] {
+		return fmt.Errorf("metrics: duplicate metric name %s", d.name)
+	}
+	reg.names[d.name] = true
+	reg.collectors = append(reg.collectors, c)
+	return nil
+}
+
+func (reg *Registry) Write(w io.Writer) {
+	reg.mutex.Lock()
+	collectors := make([]Collector, len(reg.collectors))
+	copy(collectors, reg.collectors)
+	reg.mutex.Unlock()
+
+	buf := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(buf)
+	}
+	buf.Flush()
+}
+
+func (reg *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		w.WriteHeader(http.StatusOK)
+		reg.Write(w)
+	}
+}
+
+type desc struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+}
+
+func (d *desc) describe() *desc {
+	return d
+}
+
+func (d *desc) writeHeader(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, d.typ)
+}
+
+// Counter
+
+type Counter struct {
+	desc
+	value float64
+	mutex sync.Mutex
+}
+
+func NewCounter(name, help string) *Counter {
+	return &Counter{desc: desc{name: name, help: help, typ: typeCounter}}
+}
+
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		return
+	}
+	c.mutex.Lock()
+	c.value += delta
+	c.mutex.Unlock()
+}
+
+func (c *Counter) Value() float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.value
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.writeHeader(w)
+	fmt.Fprintf(w, "%s %s\n", c.name, formatValue(c.Value()))
+}
+
+// CounterVec
+
+type CounterVec struct {
+	desc
+	values map[string]float64
+	mutex  sync.Mutex
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{
+		desc:   desc{name: name, help: help, typ: typeCounter, labels: labels},
+		values: make(map[string]float64),
+	}
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 || len(labelValues) != len(c.labels) {
+		return
+	}
+	key := strings.Join(labelValues, labelSep)
+	c.mutex.Lock()
+	c.values[key] += delta
+	c.mutex.Unlock()
+}
+
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.values[strings.Join(labelValues, labelSep)]
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mutex.Lock()
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values := make([]float64, len(keys))
+	for i, key := range keys {
+		values[i] = c.values[key]
+	}
+	c.mutex.Unlock()
+
+	c.writeHeader(w)
+	for i, key := range keys {
+		labels := formatLabels(c.labels, strings.Split(key, labelSep))
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labels, formatValue(values[i]))
+	}
+}
+
+// Gauge
+
+type Gauge struct {
+	desc
+	value float64
+	mutex sync.Mutex
+}
+
+func NewGauge(name, help string) *Gauge {
+	return &Gauge{desc: desc{name: name, help: help, typ: typeGauge}}
+}
+
+func (g *Gauge) Set(value float64) {
+	g.mutex.Lock()
+	g.value = value
+	g.mutex.Unlock()
+}
+
+func (g *Gauge) Add(delta float64) {
+	g.mutex.Lock()
+	g.value += delta
+	g.mutex.Unlock()
+}
+
+func (g *Gauge) Value() float64 {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.value
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.writeHeader(w)
+	fmt.Fprintf(w, "%s %s\n", g.name, formatValue(g.Value()))
+}
+
+// GaugeFunc
+
+type GaugeFunc struct {
+	desc
+	fn func() float64
+}
+
+func NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	return &GaugeFunc{desc: desc{name: name, help: help, typ: typeGauge}, fn: fn}
+}
+
+func (g *GaugeFunc) write(w io.Writer) {
+	g.writeHeader(w)
+	fmt.Fprintf(w, "%s %s\n", g.name, formatValue(g.fn()))
+}
+
+// Histogram
+
+type Histogram struct {
+	desc
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+	mutex   sync.Mutex
+}
+
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	sorted := make([]float64, len(buckets))
+	copy(sorted, buckets)
+	sort.Float64s(sorted)
+	return &Histogram{
+		desc:    desc{name: name, help: help, typ: typeHistogram},
+		buckets: sorted,
+		counts:  make([]uint64, len(sorted)),
+	}
+}
+
+func (h *Histogram) Observe(value float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+func (h *Histogram) ObserveDuration(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+func (h *Histogram) Count() uint64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.count
+}
+
+func (h *Histogram) Sum() float64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.sum
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.mutex.Lock()
+	counts := make([]uint64, len(h.counts))
+	copy(counts, h.counts)
+	count, sum := h.count, h.sum
+	h.mutex.Unlock()
+
+	h.writeHeader(w)
+	for i, bound := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", h.name, formatValue(bound), counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", h.name, count)
+	fmt.Fprintf(w, "%s_sum %s\n", h.name, formatValue(sum))
+	fmt.Fprintf(w, "%s_count %d\n", h.name, count)
+}
+
+// Helpers
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return fmt.Sprint(value)
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(names))
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", name, escapeLabelValue(value)))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeHelp(str string) string {
+	str = strings.ReplaceAll(str, "\\", "\\\\")
+	return strings.ReplaceAll(str, "\n", "\\n")
+}
+
+func escapeLabelValue(str string) string {
+	str = strings.ReplaceAll(str, "\\", "\\\\")
+	str = strings.ReplaceAll(str, "\"", "\\\"")
+	return strings.ReplaceAll(str, "\n", "\\n")
+}
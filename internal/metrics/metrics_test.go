@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func mustRegister(t *testing.T, reg *Registry, collectors ...Collector) {
+	t.Helper()
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExposition(t *testing.T) {
+	reg := NewRegistry()
+	counter := NewCounter("test_events_total", "Number of events.")
+	vec := NewCounterVec("test_messages_total", "Messages by type\nand \\ direction.", "type", "direction")
+	gauge := NewGauge("test_temperature", "Temperature.")
+	histogram := NewHistogram("test_duration_seconds", "Durations.", []float64{1, 0.1, 0.5})
+	gaugeFunc := NewGaugeFunc("test_ready", "Readiness.", func() float64 { return math.Inf(1) })
+	mustRegister(t, reg, counter, vec, gauge, histogram, gaugeFunc)
+
+	counter.Inc()
+	counter.Add(2.5)
+	counter.Add(-1) // ignored
+	vec.Inc("simvars", "out")
+	vec.Add(2, "say \"hi\"\n", `C:\path`)
+	vec.Inc("too", "many", "labels") // ignored
+	gauge.Set(21.5)
+	gauge.Add(-30)
+	for _, value := range []float64{0.05, 0.1, 0.3, 0.7, 2} {
+		histogram.Observe(value)
+	}
+
+	// Samples are sorted by their label values; histogram buckets are
+	// cumulative and sorted by their bounds.
+	want := `# HELP test_events_total Number of events.
+# TYPE test_events_total counter
+test_events_total 3.5
+# HELP test_messages_total Messages by type\nand \\ direction.
+# TYPE test_messages_total counter
+test_messages_total{type="say \"hi\"\n",direction="C:\\path"} 2
+test_messages_total{type="simvars",direction="out"} 1
+# HELP test_temperature Temperature.
+# TYPE test_temperature gauge
+test_temperature -8.5
+# HELP test_duration_seconds Durations.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{le="0.1"} 2
+test_duration_seconds_bucket{le="0.5"} 3
+test_duration_seconds_bucket{le="1"} 4
+test_duration_seconds_bucket{le="+Inf"} 5
+test_duration_seconds_sum 3.15
+test_duration_seconds_count 5
+# HELP test_ready Readiness.
+# TYPE test_ready gauge
+test_ready +Inf
+`
+	var buf strings.Builder
+	reg.Write(&buf)
+	if got := buf.String(); got != want {
+		t.Errorf("exposition:\n%s\nwant:\n%s", got, want)
+	}
+
+	recorder := httptest.NewRecorder()
+	reg.Handler()(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if got := recorder.Header().Get("Content-Type"); got != ContentType {
+		t.Errorf("content type %q", got)
+	}
+	if recorder.Body.String() != want {
+		t.Error("handler wrote another exposition")
+	}
+}
+
+func TestEmptyMetrics(t *testing.T) {
+	reg := NewRegistry()
+	mustRegister(t, reg,
+		NewCounterVec("test_empty_total", "No samples yet.", "type"),
+		NewHistogram("test_empty_seconds", "Default buckets.", nil),
+	)
+	var buf strings.Builder
+	reg.Write(&buf)
+	got := buf.String()
+	if !strings.HasPrefix(got, "# HELP test_empty_total No samples yet.\n# TYPE test_empty_total counter\n# HELP test_empty_seconds") {
+		t.Errorf("exposition:\n%s", got)
+	}
+	if n := strings.Count(got, "test_empty_seconds_bucket{"); n != len(DefaultBuckets)+1 {
+		t.Errorf("%d buckets, want %d", n, len(DefaultBuckets)+1)
+	}
+	if !strings.HasSuffix(got, "test_empty_seconds_sum 0\ntest_empty_seconds_count 0\n") {
+		t.Errorf("exposition:\n%s", got)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	reg := NewRegistry()
+	mustRegister(t, reg, NewCounter("test_total", "Test."))
+
+	tests := []struct {
+		collector Collector
+		err       string
+	}{
+		{collector: NewGauge("test_total", "Same name."), err: "metrics: duplicate metric name test_total"},
+		{collector: NewGauge("test-gauge", "Dash."), err: `metrics: invalid metric name "test-gauge"`},
+		{collector: NewGauge("", "Empty."), err: `metrics: invalid metric name ""`},
+		{collector: NewCounterVec("test_vec_total", "Label.", "message type"), err: `metrics: invalid label name "message type" of test_vec_total`},
+		{collector: NewCounterVec("test_reserved_total", "Reserved.", "__name"), err: `metrics: invalid label name "__name" of test_reserved_total`},
+	}
+	for _, test := range tests {
+		if err := reg.Register(test.collector); err == nil || err.Error() != test.err {
+			t.Errorf("error %v, want %q", err, test.err)
+		}
+	}
+
+	var buf strings.Builder
+	reg.Write(&buf)
+	if got := buf.String(); got != "# HELP test_total Test.\n# TYPE test_total counter\ntest_total 0\n" {
+		t.Errorf("exposition:\n%s", got)
+	}
+}
@@ -0,0 +1,159 @@
+package mqttbridge
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+const (
+	CommandSetData  = "setdata"
+	CommandTeleport = "teleport"
+	CommandEvent    = "event"
+
+	simTopic     = "sim"
+	commandTopic = "cmd"
+	statusTopic  = "status"
+
+	StatusOnline  = "online"
+	StatusOffline = "offline"
+)
+
+// ParseVar parses a SimVar as given in the config: "NAME:unit" for numbers
+// (e.g. "PLANE ALTITUDE:feet" or "GENERAL ENG RPM:1:rpm") and "NAME" for
+// strings (e.g. "TITLE").
+func ParseVar(s string) (telemetry.Var, error) {
+	s = strings.TrimSpace(s)
+	name, unit, dataType := s, "", "string256"
+	if i := strings.LastIndexByte(s, ':'); i >= 0 {
+		name, unit, dataType = strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:]), "float64"
+		if unit == "" {
+			return telemetry.Var{}, fmt.Errorf("%q: missing unit", s)
+		}
+	}
+	if name == "" {
+		return telemetry.Var{}, fmt.Errorf("%q: missing name", s)
+	}
+	return telemetry.Var{Name: name, Unit: unit, Type: dataType, Moniker: name}, nil
+}
+
+// SimVarTopic returns the topic a SimVar is published to, e.g.
+// gopilot/sim/PLANE_ALTITUDE.
+func SimVarTopic(prefix, name string) string {
+	return join(prefix, simTopic, strings.ReplaceAll(name, " ", "_"))
+}
+
+// CommandTopic returns the topic of a command, e.g. gopilot/cmd/setdata.
+func CommandTopic(prefix, command string) string {
+	return join(prefix, commandTopic, command)
+}
+
+func StatusTopic(prefix string) string {
+	return join(prefix, statusTopic)
+}
+
+func join(parts ...string) string {
+	topic := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.Trim(part, "/"); part != "" {
+			topic = append(topic, part)
+		}
+	}
+	return strings.Join(topic, "/")
+}
+
+// Message is a payload to be published.
+type Message struct {
+	Topic   string
+	Payload string
+}
+
+// Publisher is a telemetry.Consumer which keeps the latest value of each
+// SimVar and hands out the ones that changed since they were last published.
+type Publisher struct {
+	vars      []telemetry.Var
+	topics    map[string]string // by moniker
+	latest    map[string]string // by topic
+	published map[string]string // by topic
+	mutex     sync.Mutex
+}
+
+func NewPublisher(prefix string, simVars []string) (*Publisher, error) {
+	pub := &Publisher{
+		vars:      make([]telemetry.Var, 0, len(simVars)),
+		topics:    make(map[string]string),
+		latest:    make(map[string]string),
+		published: make(map[string]string),
+	}
+	for _, s := range simVars {
+		v, err := ParseVar(s)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := pub.topics[v.Moniker]; ok {
+			return nil, fmt.Errorf("%q: duplicate SimVar", s)
+		}
+		pub.vars = append(pub.vars, v)
+		pub.topics[v.Moniker] = SimVarTopic(prefix, v.Name)
+	}
+	return pub, nil
+}
+
+func (pub *Publisher) Vars() []telemetry.Var {
+	return pub.vars
+}
+
+func (pub *Publisher) Consume(sample *telemetry.Sample) {
+	pub.mutex.Lock()
+	defer pub.mutex.Unlock()
+	for moniker, value := range sample.Values {
+		topic, ok := pub.topics[moniker]
+		if !ok {
+			continue
+		}
+		pub.latest[topic] = format(value)
+	}
+}
+
+// Changes returns the values which changed since the last call, sorted by
+// topic, and marks them as published.
+func (pub *Publisher) Changes() []Message {
+	pub.mutex.Lock()
+	defer pub.mutex.Unlock()
+	changes := make([]Message, 0)
+	for topic, payload := range pub.latest {
+		if last, ok := pub.published[topic]; ok && last == payload {
+			continue
+		}
+		pub.published[topic] = payload
+		changes = append(changes, Message{Topic: topic, Payload: payload})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Topic < changes[j].Topic
+	})
+	return changes
+}
+
+// Reset makes the next call to Changes return all values, e.g. after
+// reconnecting to a broker which does not keep retained messages.
+func (pub *Publisher) Reset() {
+	pub.mutex.Lock()
+	defer pub.mutex.Unlock()
+	pub.published = make(map[string]string)
+}
+
+func format(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
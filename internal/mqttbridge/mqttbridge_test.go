@@ -0,0 +1,86 @@
+package mqttbridge
+
+import (
+	"testing"
+	"time"
+
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+func TestParseVar(t *testing.T) {
+	tests := []struct {
+		in                   string
+		name, unit, dataType string
+		err                  bool
+	}{
+		{in: "PLANE ALTITUDE:feet", name: "PLANE ALTITUDE", unit: "feet", dataType: "float64"},
+		{in: "GENERAL ENG RPM:1:rpm", name: "GENERAL ENG RPM:1", unit: "rpm", dataType: "float64"},
+		{in: " TITLE ", name: "TITLE", dataType: "string256"},
+		{in: "PLANE ALTITUDE:", err: true},
+		{in: ":feet", err: true},
+	}
+	for _, test := range tests {
+		v, err := ParseVar(test.in)
+		if test.err {
+			if err == nil {
+				t.Errorf("ParseVar(%q) succeeded", test.in)
+			}
+			continue
+		}
+		if err != nil || v.Name != test.name || v.Unit != test.unit || v.Type != test.dataType {
+			t.Errorf("ParseVar(%q) = %+v, %v", test.in, v, err)
+		}
+	}
+}
+
+func TestTopics(t *testing.T) {
+	if got := SimVarTopic("/gopilot/", "PLANE ALTITUDE"); got != "gopilot/sim/PLANE_ALTITUDE" {
+		t.Errorf("SimVarTopic = %q", got)
+	}
+	if got := CommandTopic("", CommandSetData); got != "cmd/setdata" {
+		t.Errorf("CommandTopic = %q", got)
+	}
+	if got := StatusTopic("gopilot"); got != "gopilot/status" {
+		t.Errorf("StatusTopic = %q", got)
+	}
+}
+
+func TestPublisherChanges(t *testing.T) {
+	pub, err := NewPublisher("gopilot", []string{"PLANE ALTITUDE:feet", "TITLE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPublisher("gopilot", []string{"TITLE", "TITLE"}); err == nil {
+		t.Error("duplicate SimVar was accepted")
+	}
+
+	sample := func(altitude float64) *telemetry.Sample {
+		s := telemetry.NewSample(time.Now())
+		s.Set("PLANE ALTITUDE", altitude)
+		s.Set("TITLE", "Cessna")
+		s.Set("UNKNOWN", 1.0)
+		return s
+	}
+	pub.Consume(sample(1500.5))
+	changes := pub.Changes()
+	want := []Message{
+		{Topic: "gopilot/sim/PLANE_ALTITUDE", Payload: "1500.5"},
+		{Topic: "gopilot/sim/TITLE", Payload: "Cessna"},
+	}
+	if len(changes) != len(want) || changes[0] != want[0] || changes[1] != want[1] {
+		t.Fatalf("Changes = %v, want %v", changes, want)
+	}
+
+	pub.Consume(sample(1500.5))
+	if changes := pub.Changes(); len(changes) != 0 {
+		t.Errorf("unchanged values published again: %v", changes)
+	}
+	pub.Consume(sample(1600))
+	if changes := pub.Changes(); len(changes) != 1 || changes[0].Payload != "1600" {
+		t.Errorf("Changes = %v, want only the altitude", changes)
+	}
+	pub.Reset()
+	if changes := pub.Changes(); len(changes) != 2 {
+		t.Errorf("Changes after Reset = %v, want all values", changes)
+	}
+}
@@ -0,0 +1,160 @@
+package nmea
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"msfs2020-gopilot/internal/geo"
+)
+
+const (
+	talker = "GP"
+	// The simulator has a perfect fix, so a plausible constellation is
+	// reported.
+	satellites = 8
+	pdop       = 1.2
+	hdop       = 0.8
+	vdop       = 0.9
+
+	kilometersPerNauticalMile = geo.MetersPerNauticalMile / 1000
+)
+
+// Fix is the position and movement the sentences are built from.
+type Fix struct {
+	Time             time.Time
+	Latitude         float64 // degrees
+	Longitude        float64 // degrees
+	Altitude         float64 // feet MSL
+	GroundSpeed      float64 // knots
+	Track            float64 // degrees true
+	MagneticVariance float64 // degrees, positive east
+}
+
+// Sentence builds a sentence from its fields (starting with the type, e.g.
+// "GPRMC") and adds the checksum and the line ending.
+func Sentence(fields ...string) string {
+	body := strings.Join(fields, ",")
+	return fmt.Sprintf("$%s*%02X\r\n", body, Checksum(body))
+}
+
+// Checksum is the XOR of all characters between '$' and '*'.
+func Checksum(body string) byte {
+	var sum byte
+	for i := 0; i < len(body); i++ {
+		sum ^= body[i]
+	}
+	return sum
+}
+
+// Valid reports whether a sentence (with or without line ending) has a correct
+// checksum.
+func Valid(sentence string) bool {
+	sentence = strings.TrimRight(sentence, "\r\n")
+	star := strings.LastIndexByte(sentence, '*')
+	if !strings.HasPrefix(sentence, "$") || star < 0 || star+3 != len(sentence) {
+		return false
+	}
+	var sum byte
+	if _, err := fmt.Sscanf(sentence[star+1:], "%02X", &sum); err != nil {
+		return false
+	}
+	return Checksum(sentence[1:star]) == sum
+}
+
+// Sentences returns RMC, GGA, VTG and GSA for the fix.
+func Sentences(fix *Fix) []string {
+	return []string{RMC(fix), GGA(fix), VTG(fix), GSA()}
+}
+
+func RMC(fix *Fix) string {
+	t := fix.Time.UTC()
+	lat, ns := latitude(fix.Latitude)
+	lon, ew := longitude(fix.Longitude)
+	variation, variationDir := math.Abs(fix.MagneticVariance), "E"
+	if fix.MagneticVariance < 0 {
+		variationDir = "W"
+	}
+	return Sentence(talker+"RMC",
+		utcTime(t), "A",
+		lat, ns, lon, ew,
+		decimal(fix.GroundSpeed, 1), heading(fix.Track),
+		t.Format("020106"),
+		decimal(variation, 1), variationDir,
+		"A")
+}
+
+func GGA(fix *Fix) string {
+	lat, ns := latitude(fix.Latitude)
+	lon, ew := longitude(fix.Longitude)
+	return Sentence(talker+"GGA",
+		utcTime(fix.Time.UTC()),
+		lat, ns, lon, ew,
+		"1", fmt.Sprintf("%02d", satellites), decimal(hdop, 1),
+		decimal(geo.FeetToMeters(fix.Altitude), 1), "M",
+		"0.0", "M",
+		"", "")
+}
+
+func VTG(fix *Fix) string {
+	return Sentence(talker+"VTG",
+		heading(fix.Track), "T",
+		heading(fix.Track-fix.MagneticVariance), "M",
+		decimal(fix.GroundSpeed, 1), "N",
+		decimal(fix.GroundSpeed*kilometersPerNauticalMile, 1), "K",
+		"A")
+}
+
+// GSA reports an automatic 3D fix.
+func GSA() string {
+	fields := []string{talker + "GSA", "A", "3"}
+	for i := 1; i <= 12; i++ {
+		if i <= satellites {
+			fields = append(fields, fmt.Sprintf("%02d", i))
+		} else {
+			fields = append(fields, "")
+		}
+	}
+	fields = append(fields, decimal(pdop, 1), decimal(hdop, 1), decimal(vdop, 1))
+	return Sentence(fields...)
+}
+
+func utcTime(t time.Time) string {
+	return fmt.Sprintf("%02d%02d%02d.%02d", t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/1e7)
+}
+
+// latitude returns ddmm.mmmm and N or S.
+func latitude(deg float64) (string, string) {
+	hemisphere := "N"
+	if deg < 0 {
+		hemisphere = "S"
+	}
+	return degreesMinutes(math.Abs(deg), 2), hemisphere
+}
+
+// longitude returns dddmm.mmmm and E or W.
+func longitude(deg float64) (string, string) {
+	hemisphere := "E"
+	if deg < 0 {
+		hemisphere = "W"
+	}
+	return degreesMinutes(math.Abs(deg), 3), hemisphere
+}
+
+func degreesMinutes(deg float64, width int) string {
+	// Round first, so that 59.99999 minutes do not become 60.0000.
+	totalMinutes := math.Round(deg*60*10000) / 10000
+	degrees := math.Floor(totalMinutes / 60)
+	minutes := totalMinutes - degrees*60
+	return fmt.Sprintf("%0*d%07.4f", width, int(degrees), minutes)
+}
+
+// heading returns a heading with one decimal in [0, 360).
+func heading(deg float64) string {
+	return decimal(geo.NormalizeHeading(math.Round(deg*10)/10), 1)
+}
+
+func decimal(value float64, decimals int) string {
+	return fmt.Sprintf("%.*f", decimals, value)
+}
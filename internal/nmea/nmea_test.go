@@ -0,0 +1,131 @@
+package nmea
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChecksum(t *testing.T) {
+	// Widely used reference sentences.
+	tests := []string{
+		"$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47",
+		"$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6A",
+	}
+	for _, sentence := range tests {
+		star := strings.LastIndexByte(sentence, '*')
+		if got, want := Sentence(sentence[1:star]), sentence+"\r\n"; got != want {
+			t.Errorf("Sentence = %q, want %q", got, want)
+		}
+		if !Valid(sentence) || !Valid(sentence+"\r\n") {
+			t.Errorf("%q is not valid", sentence)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		sentence string
+		valid    bool
+	}{
+		{sentence: "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47\r\n", valid: true},
+		{sentence: "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*48", valid: false},
+		{sentence: "$GPGGA,123519,4807.038,S,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47", valid: false},
+		{sentence: "GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47", valid: false},
+		{sentence: "$GPGGA,123519*4", valid: false},
+		{sentence: "$GPGGA,123519", valid: false},
+		{sentence: "$GPGGA,123519*ZZ", valid: false},
+	}
+	for _, test := range tests {
+		if got := Valid(test.sentence); got != test.valid {
+			t.Errorf("Valid(%q) = %t, want %t", test.sentence, got, test.valid)
+		}
+	}
+}
+
+var (
+	munich = &Fix{
+		Time:             time.Date(2021, 6, 1, 9, 5, 3, 250e6, time.UTC),
+		Latitude:         48.1173,
+		Longitude:        11.5166667,
+		Altitude:         1788.7,
+		GroundSpeed:      123.46,
+		Track:            359.96,
+		MagneticVariance: -3.1,
+	}
+	southWest = &Fix{
+		Time:      time.Date(2022, 1, 1, 0, 59, 59, 995e6, time.FixedZone("CET", 3600)),
+		Latitude:  -5.5,
+		Longitude: -0.25,
+		Altitude:  -100,
+	}
+	dateLine = &Fix{
+		Time:        time.Date(2021, 12, 31, 23, 59, 59, 995e6, time.UTC),
+		Latitude:    0.99999999,
+		Longitude:   -179.9999,
+		GroundSpeed: 5,
+		Track:       -90,
+	}
+)
+
+func TestGGA(t *testing.T) {
+	tests := []struct {
+		name string
+		fix  *Fix
+		want string
+	}{
+		{
+			name: "north east",
+			fix:  munich,
+			want: "$GPGGA,090503.25,4807.0380,N,01131.0000,E,1,08,0.8,545.2,M,0.0,M,,*50\r\n",
+		},
+		{
+			name: "south west, zero padded, below sea level",
+			fix:  southWest,
+			want: "$GPGGA,235959.99,0530.0000,S,00015.0000,W,1,08,0.8,-30.5,M,0.0,M,,*4B\r\n",
+		},
+	}
+	for _, test := range tests {
+		if got := GGA(test.fix); got != test.want {
+			t.Errorf("%s:\n got %q\nwant %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRMC(t *testing.T) {
+	tests := []struct {
+		name string
+		fix  *Fix
+		want string
+	}{
+		{
+			name: "north east, west variation, track rounded to 0",
+			fix:  munich,
+			want: "$GPRMC,090503.25,A,4807.0380,N,01131.0000,E,123.5,0.0,010621,3.1,W,A*2E\r\n",
+		},
+		{
+			name: "minutes rounded to the next degree",
+			fix:  dateLine,
+			want: "$GPRMC,235959.99,A,0100.0000,N,17959.9940,W,5.0,270.0,311221,0.0,E,A*22\r\n",
+		},
+	}
+	for _, test := range tests {
+		if got := RMC(test.fix); got != test.want {
+			t.Errorf("%s:\n got %q\nwant %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSentences(t *testing.T) {
+	for _, sentence := range Sentences(munich) {
+		if !Valid(sentence) {
+			t.Errorf("%q is not valid", sentence)
+		}
+		if !strings.HasSuffix(sentence, "\r\n") {
+			t.Errorf("%q has no line ending", sentence)
+		}
+	}
+	if got, want := GSA(), "$GPGSA,A,3,01,02,03,04,05,06,07,08,,,,,1.2,0.8,0.9*"; !strings.HasPrefix(got, want) {
+		t.Errorf("GSA = %q, want %q…", got, want)
+	}
+}
@@ -0,0 +1,159 @@
+package overlay
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	fileExtension = ".html"
+	scriptPath    = "/assets/js/overlay.js"
+)
+
+var (
+	ErrNotFound = errors.New("overlay not found")
+
+	validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// Var is a SimVar used by an overlay. It is sent as is in the register
+// message of the overlay page.
+type Var struct {
+	Name    string `json:"name"`
+	Unit    string `json:"unit"`
+	Type    string `json:"type"`
+	Moniker string `json:"moniker"`
+}
+
+// Data is what the overlay templates are executed with.
+type Data struct {
+	Name  string
+	Query url.Values
+}
+
+var layout = template.Must(template.New("layout").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} - GoPilot Overlay</title>
+<style>html, body { background: transparent; margin: 0; overflow: hidden; }</style>
+</head>
+<body>
+{{.Body}}
+<script id="overlay-vars" type="application/json">{{.Vars}}</script>
+<script src="{{.Script}}"></script>
+</body>
+</html>
+`))
+
+// Overlays renders the overlay templates (<name>.html) of a directory. The
+// templates are read on every request, so they can be edited while GoPilot
+// is running.
+type Overlays struct {
+	dir string
+}
+
+func New(dir string) *Overlays {
+	return &Overlays{dir: dir}
+}
+
+// Names returns the names of all overlays in the directory.
+func (o *Overlays) Names() ([]string, error) {
+	files, err := ioutil.ReadDir(o.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), fileExtension)
+		if file.IsDir() || name == file.Name() || !validName.MatchString(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Render writes the page of an overlay. Every {{simvar "NAME" "unit"}} in the
+// template becomes an element whose text is updated with the live value of
+// the SimVar; an empty unit denotes a string. An optional third argument sets
+// the number of decimals, e.g. {{simvar "PLANE ALTITUDE" "feet" 0}}.
+func (o *Overlays) Render(w io.Writer, name string, query url.Values) error {
+	if !validName.MatchString(name) {
+		return ErrNotFound
+	}
+	text, err := ioutil.ReadFile(filepath.Join(o.dir, name+fileExtension))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	vars := &collector{monikers: make(map[string]string)}
+	tmpl, err := template.New(name).Funcs(template.FuncMap{"simvar": vars.simvar}).Parse(string(text))
+	if err != nil {
+		return err
+	}
+	body := &bytes.Buffer{}
+	if err := tmpl.Execute(body, &Data{Name: name, Query: query}); err != nil {
+		return err
+	}
+
+	page := &bytes.Buffer{}
+	err = layout.Execute(page, map[string]interface{}{
+		"Name":   name,
+		"Body":   template.HTML(body.String()),
+		"Vars":   vars.vars,
+		"Script": scriptPath,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = page.WriteTo(w)
+	return err
+}
+
+type collector struct {
+	vars     []Var
+	monikers map[string]string
+}
+
+func (c *collector) simvar(name, unit string, decimals ...int) (template.HTML, error) {
+	if name == "" {
+		return "", errors.New("simvar: missing name")
+	}
+	if len(decimals) > 1 {
+		return "", errors.New("simvar: too many arguments")
+	}
+	key := name + "\x00" + unit
+	moniker, ok := c.monikers[key]
+	if !ok {
+		moniker = fmt.Sprintf("v%d", len(c.vars))
+		dataType := "float64"
+		if unit == "" {
+			dataType = "string256"
+		}
+		c.vars = append(c.vars, Var{Name: name, Unit: unit, Type: dataType, Moniker: moniker})
+		c.monikers[key] = moniker
+	}
+	attrs := fmt.Sprintf(`class="simvar" data-simvar="%s"`, moniker)
+	if len(decimals) == 1 {
+		attrs += fmt.Sprintf(` data-decimals="%d"`, decimals[0])
+	}
+	return template.HTML("<span " + attrs + "></span>"), nil
+}
@@ -0,0 +1,128 @@
+package ownship
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+const (
+	// The state is considered stale if no sample was received for this long,
+	// e.g. while the simulator is in the menus.
+	maxAge = 5 * time.Second
+
+	monikerLatitude         = "latitude"
+	monikerLongitude        = "longitude"
+	monikerAltitude         = "altitude"
+	monikerPressureAltitude = "pressureAltitude"
+	monikerGroundSpeed      = "groundSpeed"
+	monikerTrueAirspeed     = "trueAirspeed"
+	monikerVerticalSpeed    = "verticalSpeed"
+	monikerTrack            = "track"
+	monikerHeading          = "heading"
+	monikerMagneticHeading  = "magneticHeading"
+	monikerPitch            = "pitch"
+	monikerBank             = "bank"
+	monikerOnGround         = "onGround"
+	monikerATCID            = "atcId"
+)
+
+// State is the latest state of the user aircraft, as needed by the navigation
+// outputs (GDL90, NMEA, X-Plane).
+type State struct {
+	Time             time.Time
+	Latitude         float64 // degrees
+	Longitude        float64 // degrees
+	Altitude         float64 // feet MSL
+	PressureAltitude float64 // feet
+	GroundSpeed      float64 // knots
+	TrueAirspeed     float64 // knots
+	VerticalSpeed    float64 // feet per minute
+	Track            float64 // degrees true
+	Heading          float64 // degrees true
+	MagneticHeading  float64 // degrees magnetic
+	MagneticVariance float64 // degrees, positive east
+	Pitch            float64 // degrees, positive nose up
+	Bank             float64 // degrees, positive right wing down
+	OnGround         bool
+	ATCID            string
+}
+
+// Tracker keeps the latest State.
+type Tracker struct {
+	state State
+	valid bool
+	mutex sync.RWMutex
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+func (tr *Tracker) Vars() []telemetry.Var {
+	return []telemetry.Var{
+		{Name: "PLANE LATITUDE", Unit: "degrees", Type: "float64", Moniker: monikerLatitude},
+		{Name: "PLANE LONGITUDE", Unit: "degrees", Type: "float64", Moniker: monikerLongitude},
+		{Name: "PLANE ALTITUDE", Unit: "feet", Type: "float64", Moniker: monikerAltitude},
+		{Name: "PRESSURE ALTITUDE", Unit: "feet", Type: "float64", Moniker: monikerPressureAltitude},
+		{Name: "GROUND VELOCITY", Unit: "knot", Type: "float64", Moniker: monikerGroundSpeed},
+		{Name: "AIRSPEED TRUE", Unit: "knot", Type: "float64", Moniker: monikerTrueAirspeed},
+		{Name: "VERTICAL SPEED", Unit: "ft/min", Type: "float64", Moniker: monikerVerticalSpeed},
+		{Name: "GPS GROUND TRUE TRACK", Unit: "degrees", Type: "float64", Moniker: monikerTrack},
+		{Name: "PLANE HEADING DEGREES TRUE", Unit: "degrees", Type: "float64", Moniker: monikerHeading},
+		{Name: "PLANE HEADING DEGREES MAGNETIC", Unit: "degrees", Type: "float64", Moniker: monikerMagneticHeading},
+		{Name: "PLANE PITCH DEGREES", Unit: "degrees", Type: "float64", Moniker: monikerPitch},
+		{Name: "PLANE BANK DEGREES", Unit: "degrees", Type: "float64", Moniker: monikerBank},
+		{Name: "SIM ON GROUND", Unit: "bool", Type: "int32", Moniker: monikerOnGround},
+		{Name: "ATC ID", Unit: "", Type: "string64", Moniker: monikerATCID},
+	}
+}
+
+func (tr *Tracker) Consume(sample *telemetry.Sample) {
+	if !sample.Has(monikerLatitude, monikerLongitude, monikerAltitude) {
+		return
+	}
+	s := State{Time: sample.Time}
+	s.Latitude, _ = sample.Float(monikerLatitude)
+	s.Longitude, _ = sample.Float(monikerLongitude)
+	s.Altitude, _ = sample.Float(monikerAltitude)
+	s.PressureAltitude, _ = sample.Float(monikerPressureAltitude)
+	s.GroundSpeed, _ = sample.Float(monikerGroundSpeed)
+	s.TrueAirspeed, _ = sample.Float(monikerTrueAirspeed)
+	s.VerticalSpeed, _ = sample.Float(monikerVerticalSpeed)
+	s.Heading, _ = sample.Float(monikerHeading)
+	s.MagneticHeading, _ = sample.Float(monikerMagneticHeading)
+	s.Pitch, _ = sample.Float(monikerPitch)
+	s.Bank, _ = sample.Float(monikerBank)
+	s.OnGround, _ = sample.Bool(monikerOnGround)
+	s.ATCID, _ = sample.String(monikerATCID)
+	// The track is not meaningful while standing still.
+	if track, ok := sample.Float(monikerTrack); ok && s.GroundSpeed >= 1 {
+		s.Track = track
+	} else {
+		s.Track = s.Heading
+	}
+	// true = magnetic + variation
+	s.MagneticVariance = math.Mod(s.Heading-s.MagneticHeading+540, 360) - 180
+	// PLANE PITCH DEGREES is negative for nose up and PLANE BANK DEGREES is
+	// negative for a right bank.
+	s.Pitch = -s.Pitch
+	s.Bank = -s.Bank
+
+	tr.mutex.Lock()
+	tr.state = s
+	tr.valid = true
+	tr.mutex.Unlock()
+}
+
+// State returns the latest state. ok is false if there is none or it is stale.
+func (tr *Tracker) State() (state State, ok bool) {
+	tr.mutex.RLock()
+	defer tr.mutex.RUnlock()
+	if !tr.valid || time.Since(tr.state.Time) > maxAge {
+		return tr.state, false
+	}
+	return tr.state, true
+}
@@ -0,0 +1,163 @@
+package presence
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"msfs2020-gopilot/internal/telemetry"
+)
+
+const (
+	DefaultTemplate = `{{if not .Connected}}Not flying{{else if .Airborne}}Flying {{.Model}}` +
+		`{{with .Departure}} from {{.}}{{end}}{{with .NextWaypoint}} to {{.}}{{end}} at {{fl .PressureAltitude}}` +
+		`{{else}}{{phase .Phase}} {{.Model}}{{with .NearestAirport}} at {{.}}{{end}}{{end}}`
+
+	monikerTitle        = "title"
+	monikerModel        = "model"
+	monikerNextWaypoint = "nextWaypoint"
+)
+
+// Data is what the templates are executed with.
+type Data struct {
+	Time               time.Time `json:"time"`
+	Connected          bool      `json:"connected"`
+	Aircraft           string    `json:"aircraft"` // title, e.g. "Cessna Skyhawk G1000 Asobo"
+	Model              string    `json:"model"`    // ICAO type designator, e.g. "C172"
+	ATCID              string    `json:"atcId"`
+	Phase              string    `json:"phase"`
+	Airborne           bool      `json:"airborne"`
+	Departure          string    `json:"departure"` // ICAO code
+	Arrival            string    `json:"arrival"`   // ICAO code, known after landing
+	NextWaypoint       string    `json:"nextWaypoint"`
+	NearestAirport     string    `json:"nearestAirport"` // ICAO code
+	NearestAirportName string    `json:"nearestAirportName"`
+	Latitude           float64   `json:"latitude"`
+	Longitude          float64   `json:"longitude"`
+	Altitude           float64   `json:"altitude"`         // feet MSL
+	PressureAltitude   float64   `json:"pressureAltitude"` // feet
+	GroundSpeed        float64   `json:"groundSpeed"`      // knots
+	Heading            float64   `json:"heading"`          // degrees magnetic
+}
+
+var funcs = template.FuncMap{
+	// fl formats an altitude as flight level, e.g. FL085.
+	"fl": func(feet float64) string {
+		return fmt.Sprintf("FL%03d", int(math.Round(feet/100)))
+	},
+	// feet rounds an altitude to hundreds of feet, e.g. 3,500 ft.
+	"feet": func(feet float64) string {
+		return thousands(int(math.Round(feet/100)*100)) + " ft"
+	},
+	"round": func(value float64) int {
+		return int(math.Round(value))
+	},
+	// phase turns a phase into words, e.g. takeoff_roll into "Takeoff roll".
+	"phase": func(phase string) string {
+		if phase == "" {
+			return "Sitting in"
+		}
+		phase = strings.ReplaceAll(phase, "_", " ")
+		return strings.ToUpper(phase[:1]) + phase[1:]
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// Composer turns Data into a status text.
+type Composer struct {
+	tmpl *template.Template
+}
+
+func NewComposer(text string) (*Composer, error) {
+	tmpl, err := template.New("status").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &Composer{tmpl: tmpl}, nil
+}
+
+// Compose executes the template. Runs of white space, including line breaks,
+// are collapsed, so templates may be spread over several lines.
+func (c *Composer) Compose(data *Data) (string, error) {
+	var buf bytes.Buffer
+	if err := c.tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(buf.String()), " "), nil
+}
+
+var atcModel = regexp.MustCompile(`AC_MODEL[ _]?([^.]+)\.`)
+
+// Aircraft is a telemetry.Consumer for the SimVars of the status which are
+// not tracked elsewhere.
+type Aircraft struct {
+	title        string
+	model        string
+	nextWaypoint string
+	mutex        sync.Mutex
+}
+
+func NewAircraft() *Aircraft {
+	return &Aircraft{}
+}
+
+func (a *Aircraft) Vars() []telemetry.Var {
+	return []telemetry.Var{
+		{Name: "TITLE", Unit: "", Type: "string256", Moniker: monikerTitle},
+		{Name: "ATC MODEL", Unit: "", Type: "string64", Moniker: monikerModel},
+		{Name: "GPS WP NEXT ID", Unit: "", Type: "string64", Moniker: monikerNextWaypoint},
+	}
+}
+
+func (a *Aircraft) Consume(sample *telemetry.Sample) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if title, ok := sample.String(monikerTitle); ok {
+		a.title = title
+	}
+	if model, ok := sample.String(monikerModel); ok {
+		a.model = Model(model)
+	}
+	if waypoint, ok := sample.String(monikerNextWaypoint); ok {
+		a.nextWaypoint = strings.TrimSpace(waypoint)
+	}
+}
+
+// Fill sets the aircraft fields of data.
+func (a *Aircraft) Fill(data *Data) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	data.Aircraft = a.title
+	data.Model = a.model
+	data.NextWaypoint = a.nextWaypoint
+	if data.Model == "" {
+		data.Model = a.title
+	}
+}
+
+// Model returns the type designator of an ATC MODEL value. The simulator
+// returns localization keys such as "TT:ATCCOM.AC_MODEL C172.0.text" for
+// most aircraft.
+func Model(value string) string {
+	if m := atcModel.FindStringSubmatch(value); m != nil {
+		return m[1]
+	}
+	return strings.TrimSpace(value)
+}
+
+func thousands(n int) string {
+	if n < 0 {
+		return "-" + thousands(-n)
+	}
+	s := fmt.Sprint(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
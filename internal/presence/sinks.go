@@ -0,0 +1,100 @@
+package presence
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"msfs2020-gopilot/internal/util"
+)
+
+// Sink publishes a status. Publish is only called when the status changed.
+type Sink interface {
+	Name() string
+	Publish(status string, data *Data) error
+}
+
+// FileSink appends every status with a timestamp to a file.
+type FileSink struct {
+	path string
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (sink *FileSink) Name() string {
+	return "file " + sink.path
+}
+
+func (sink *FileSink) Publish(status string, data *Data) error {
+	f, err := os.OpenFile(sink.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%s\t%s\n", data.Time.Format(time.RFC3339), status)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// OBSSink replaces the content of a file with the status, for an OBS text
+// source with "Read from file".
+type OBSSink struct {
+	path string
+}
+
+func NewOBSSink(path string) *OBSSink {
+	return &OBSSink{path: path}
+}
+
+func (sink *OBSSink) Name() string {
+	return "OBS text file " + sink.path
+}
+
+func (sink *OBSSink) Publish(status string, data *Data) error {
+	// OBS might read the file while it is written, so replace it at once.
+	tmp := sink.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(status), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, sink.path)
+}
+
+// HTTPSink POSTs the status and its data as JSON, e.g. to a local bot.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSink(url string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (sink *HTTPSink) Name() string {
+	return "HTTP " + util.RedactURL(sink.url)
+}
+
+func (sink *HTTPSink) Publish(status string, data *Data) error {
+	buf, err := json.Marshal(map[string]interface{}{
+		"status": status,
+		"data":   data,
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := sink.client.Post(sink.url, "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return util.RedactURLError(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", util.RedactURL(sink.url), resp.Status)
+	}
+	return nil
+}
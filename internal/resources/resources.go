@@ -0,0 +1,193 @@
+package resources
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+
+	gopilot "msfs2020-gopilot"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	assetsDir   = "assets"
+	dataDir     = "data"
+	appCacheDir = "gopilot"
+)
+
+// Resources provides the web assets and the data files either from the files
+// embedded in the executable or, if an override directory is given, from disk.
+// The override directory is meant for development and is expected to have the
+// same layout as the repository (assets/ and data/).
+type Resources struct {
+	overrideDir string
+	assets      fs.FS
+	data        fs.FS
+}
+
+func New(overrideDir string) (*Resources, error) {
+	res := &Resources{}
+	if overrideDir == "" {
+		assets, err := fs.Sub(gopilot.Assets, assetsDir)
+		if err != nil {
+			return nil, err
+		}
+		data, err := fs.Sub(gopilot.Data, dataDir)
+		if err != nil {
+			return nil, err
+		}
+		res.assets = assets
+		res.data = data
+		return res, nil
+	}
+
+	for _, dir := range []string{assetsDir, dataDir} {
+		if _, err := os.Stat(filepath.Join(overrideDir, dir)); err != nil {
+			return nil, err
+		}
+	}
+	res.overrideDir = overrideDir
+	res.assets = os.DirFS(filepath.Join(overrideDir, assetsDir))
+	res.data = os.DirFS(filepath.Join(overrideDir, dataDir))
+	return res, nil
+}
+
+func (res *Resources) IsEmbedded() bool {
+	return res.overrideDir == ""
+}
+
+func (res *Resources) Assets() fs.FS {
+	return res.assets
+}
+
+func (res *Resources) Data() fs.FS {
+	return res.data
+}
+
+// DataDir returns a directory on disk containing the given data subdirectory.
+// Libraries such as the airport finder can only read from the file system, so
+// embedded data files are extracted to the user's cache directory first.
+func (res *Resources) DataDir(subDir string) (string, error) {
+	if !res.IsEmbedded() {
+		return filepath.Join(res.overrideDir, dataDir, subDir), nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	targetDir := filepath.Join(cacheDir, appCacheDir, subDir)
+	if err := Extract(res.data, subDir, targetDir); err != nil {
+		return "", err
+	}
+	return targetDir, nil
+}
+
+// Extract copies all files within dir of fsys to targetDir. Files which already
+// exist with the same content are left untouched, so files of an older version
+// are replaced even if the size did not change.
+func Extract(fsys fs.FS, dir, targetDir string) error {
+	return fs.WalkDir(fsys, dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, filepath.FromSlash(filePath))
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(targetDir, rel)
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if existing, err := os.Stat(target); err == nil && existing.Size() == info.Size() {
+			same, err := sameContent(fsys, filePath, target)
+			if err != nil {
+				return err
+			}
+			if same {
+				return nil
+			}
+		}
+		log.Debug("Extracting ", filePath, " to ", target)
+		return extractFile(fsys, filePath, target)
+	})
+}
+
+func sameContent(fsys fs.FS, filePath, target string) (bool, error) {
+	src, err := fsys.Open(filePath)
+	if err != nil {
+		return false, err
+	}
+	defer src.Close()
+	embedded, err := hash(src)
+	if err != nil {
+		return false, err
+	}
+	dst, err := os.Open(target)
+	if err != nil {
+		// Unreadable files are extracted again.
+		return false, nil
+	}
+	defer dst.Close()
+	existing, err := hash(dst)
+	if err != nil {
+		return false, nil
+	}
+	return bytes.Equal(embedded, existing), nil
+}
+
+func hash(r io.Reader) ([]byte, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func extractFile(fsys fs.FS, filePath, target string) error {
+	src, err := fsys.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// ServeFile writes the named file of fsys to the response, honoring range and
+// conditional requests like http.ServeFile does.
+func ServeFile(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	http.ServeContent(w, r, path.Base(name), info.ModTime(), content)
+}
@@ -0,0 +1,58 @@
+package resources
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestExtract(t *testing.T) {
+	fsys := fstest.MapFS{
+		"airports/airports.csv": {Data: []byte("id,ident\n1,EDDF\n")},
+		"airports/regions.csv":  {Data: []byte("id,code\n1,DE-HE\n")},
+		"airports/sub/notes":    {Data: []byte("notes")},
+		"other/file":            {Data: []byte("not extracted")},
+	}
+	target := t.TempDir()
+	if err := Extract(fsys, "airports", target); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"airports.csv": "id,ident\n1,EDDF\n", "regions.csv": "id,code\n1,DE-HE\n", "sub/notes": "notes"} {
+		if got := readFile(t, filepath.Join(target, name)); got != want {
+			t.Errorf("%s: %q, want %q", name, got, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(target, "file")); err == nil {
+		t.Error("file outside the directory was extracted")
+	}
+
+	// A new version with a file of the same size, and an unchanged file
+	// which is not written again.
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	unchanged := filepath.Join(target, "regions.csv")
+	if err := os.Chtimes(unchanged, old, old); err != nil {
+		t.Fatal(err)
+	}
+	fsys["airports/airports.csv"] = &fstest.MapFile{Data: []byte("id,ident\n1,EDDM\n")}
+	if err := Extract(fsys, "airports", target); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, filepath.Join(target, "airports.csv")); got != "id,ident\n1,EDDM\n" {
+		t.Errorf("stale file of the same size kept: %q", got)
+	}
+	if info, err := os.Stat(unchanged); err != nil || !info.ModTime().Equal(old) {
+		t.Errorf("unchanged file was extracted again")
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf)
+}
@@ -0,0 +1,175 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+const (
+	tokenSize = 16 // bytes
+)
+
+// Message is a message buffered for a suspended session.
+type Message struct {
+	Type string
+	Data []byte
+}
+
+type session struct {
+	token    string
+	clientID string
+	buffer   []Message
+	expiry   *time.Timer // set while the client is disconnected
+}
+
+// Store keeps a session per WebSocket client. When a client disconnects, its
+// session is suspended for the grace period and collects the messages the
+// client misses. A new connection which presents the token of a suspended
+// session takes it over.
+type Store struct {
+	sessions    map[string]*session // by token
+	clients     map[string]*session // by client ID
+	grace       time.Duration
+	maxBuffered int
+	onExpire    func(clientID string)
+	mutex       sync.Mutex
+}
+
+// NewStore returns a store whose suspended sessions expire after grace and
+// buffer at most maxBuffered messages. onExpire is called, on its own
+// goroutine, with the ID of the client whose session expired.
+func NewStore(grace time.Duration, maxBuffered int, onExpire func(clientID string)) *Store {
+	return &Store{
+		sessions:    make(map[string]*session),
+		clients:     make(map[string]*session),
+		grace:       grace,
+		maxBuffered: maxBuffered,
+		onExpire:    onExpire,
+	}
+}
+
+// Open starts a session for a client and returns its token.
+func (s *Store) Open(clientID string) (string, error) {
+	buf := make([]byte, tokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	sess := &session{token: hex.EncodeToString(buf), clientID: clientID}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sessions[sess.token] = sess
+	s.clients[clientID] = sess
+	return sess.token, nil
+}
+
+// Suspend keeps the session of a disconnected client for the grace period.
+// It returns false if the client has no session, i.e. if its resources should
+// be released right away.
+func (s *Store) Suspend(clientID string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sess, ok := s.clients[clientID]
+	if !ok {
+		return false
+	}
+	sess.expiry = time.AfterFunc(s.grace, func() {
+		s.mutex.Lock()
+		// The session may have been resumed in the meantime.
+		expired := s.clients[clientID] == sess
+		if expired {
+			s.remove(sess)
+		}
+		s.mutex.Unlock()
+		if expired && s.onExpire != nil {
+			s.onExpire(clientID)
+		}
+	})
+	return true
+}
+
+// Resume hands the session with the token over to a newly connected client,
+// whose own session is discarded. It returns the ID of the previous client
+// and the messages buffered while it was disconnected. The previous client
+// may still be connected if it did not notice that its connection broke.
+func (s *Store) Resume(token, clientID string) (string, []Message, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sess, ok := s.sessions[token]
+	if !ok || sess.clientID == clientID {
+		return "", nil, false
+	}
+	if sess.expiry != nil {
+		sess.expiry.Stop()
+		sess.expiry = nil
+	}
+	if own, ok := s.clients[clientID]; ok {
+		s.remove(own)
+	}
+	previous := sess.clientID
+	delete(s.clients, previous)
+	sess.clientID = clientID
+	s.clients[clientID] = sess
+	buffered := sess.buffer
+	sess.buffer = nil
+	return previous, buffered, true
+}
+
+// Token returns the token of a client's session.
+func (s *Store) Token(clientID string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if sess, ok := s.clients[clientID]; ok {
+		return sess.token, true
+	}
+	return "", false
+}
+
+// IsSuspended reports whether the client disconnected and its session waits
+// to be resumed.
+func (s *Store) IsSuspended(clientID string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sess, ok := s.clients[clientID]
+	return ok && sess.expiry != nil
+}
+
+// Buffer keeps a message for every suspended session.
+func (s *Store) Buffer(message Message) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, sess := range s.clients {
+		if sess.expiry != nil {
+			s.append(sess, message)
+		}
+	}
+}
+
+// BufferFor keeps a message for a client if its session is suspended.
+func (s *Store) BufferFor(clientID string, message Message) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sess, ok := s.clients[clientID]
+	if !ok || sess.expiry == nil {
+		return false
+	}
+	s.append(sess, message)
+	return true
+}
+
+func (s *Store) append(sess *session, message Message) {
+	if s.maxBuffered <= 0 {
+		return
+	}
+	if len(sess.buffer) >= s.maxBuffered {
+		// Drop the oldest message.
+		sess.buffer = append(sess.buffer[:0], sess.buffer[1:]...)
+	}
+	sess.buffer = append(sess.buffer, message)
+}
+
+func (s *Store) remove(sess *session) {
+	delete(s.sessions, sess.token)
+	delete(s.clients, sess.clientID)
+}
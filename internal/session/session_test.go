@@ -0,0 +1,100 @@
+package session
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResume(t *testing.T) {
+	store := NewStore(time.Minute, 2, nil)
+	token, err := store.Open("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !store.Suspend("a") {
+		t.Fatal("Suspend returned false for a client with a session")
+	}
+	if !store.IsSuspended("a") {
+		t.Error("session is not suspended")
+	}
+	for i := 0; i < 3; i++ {
+		store.Buffer(Message{Type: "alert", Data: []byte(fmt.Sprint(i))})
+	}
+
+	if _, err := store.Open("b"); err != nil {
+		t.Fatal(err)
+	}
+	previous, buffered, ok := store.Resume(token, "b")
+	if !ok || previous != "a" {
+		t.Fatalf("Resume = %q, %t, want a, true", previous, ok)
+	}
+	// The oldest message was dropped.
+	if len(buffered) != 2 || string(buffered[0].Data) != "1" || string(buffered[1].Data) != "2" {
+		t.Errorf("buffered = %v, want messages 1 and 2", buffered)
+	}
+	if got, _ := store.Token("b"); got != token {
+		t.Errorf("b has token %q, want the resumed %q", got, token)
+	}
+	if _, ok := store.Token("a"); ok {
+		t.Error("a still has a session")
+	}
+	if store.IsSuspended("b") {
+		t.Error("resumed session is still suspended")
+	}
+	if _, _, ok := store.Resume(token, "b"); ok {
+		t.Error("a client resumed its own session")
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	expired := make(chan string, 1)
+	store := NewStore(10*time.Millisecond, 10, func(clientID string) {
+		expired <- clientID
+	})
+	token, _ := store.Open("a")
+	store.Suspend("a")
+	select {
+	case clientID := <-expired:
+		if clientID != "a" {
+			t.Errorf("expired %q, want a", clientID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("session did not expire")
+	}
+	if _, _, ok := store.Resume(token, "b"); ok {
+		t.Error("expired session was resumed")
+	}
+	if store.Suspend("unknown") {
+		t.Error("Suspend returned true for a client without a session")
+	}
+}
+
+func TestConcurrentSessions(t *testing.T) {
+	store := NewStore(5*time.Millisecond, 10, func(clientID string) {})
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				old := fmt.Sprintf("%d-%d-old", i, j)
+				token, err := store.Open(old)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				store.Suspend(old)
+				store.Buffer(Message{Type: "alert"})
+				store.BufferFor(old, Message{Type: "landing"})
+				store.IsSuspended(old)
+				resumer := fmt.Sprintf("%d-%d-new", i, j)
+				store.Open(resumer)
+				store.Resume(token, resumer)
+				store.Token(resumer)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
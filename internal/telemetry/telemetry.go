@@ -0,0 +1,79 @@
+package telemetry
+
+import (
+	"time"
+)
+
+// Var describes a SimVar a Consumer wants to receive. Type is a SimConnect data
+// type name as used by the WebSocket register message (e.g. "float64").
+type Var struct {
+	Name    string
+	Unit    string
+	Type    string
+	Moniker string
+}
+
+// Sample holds the latest values of a consumer's SimVars, keyed by moniker.
+type Sample struct {
+	Time   time.Time
+	Values map[string]interface{}
+}
+
+// Consumer receives SimVar samples on the server side, in the same way a
+// WebSocket client receives simvars messages.
+type Consumer interface {
+	Vars() []Var
+	Consume(sample *Sample)
+}
+
+func NewSample(t time.Time) *Sample {
+	return &Sample{
+		Time:   t,
+		Values: make(map[string]interface{}),
+	}
+}
+
+func (sample *Sample) Set(moniker string, value interface{}) {
+	sample.Values[moniker] = value
+}
+
+func (sample *Sample) Float(moniker string) (float64, bool) {
+	switch v := sample.Values[moniker].(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func (sample *Sample) Bool(moniker string) (bool, bool) {
+	value, ok := sample.Float(moniker)
+	return value != 0, ok
+}
+
+func (sample *Sample) String(moniker string) (string, bool) {
+	value, ok := sample.Values[moniker].(string)
+	return value, ok
+}
+
+// Has reports whether the sample contains values for all given monikers.
+func (sample *Sample) Has(monikers ...string) bool {
+	for _, moniker := range monikers {
+		if _, ok := sample.Values[moniker]; !ok {
+			return false
+		}
+	}
+	return true
+}
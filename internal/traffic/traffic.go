@@ -0,0 +1,89 @@
+package traffic
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	CategoryAircraft   = "aircraft"
+	CategoryHelicopter = "helicopter"
+)
+
+// Target is an AI or multiplayer object around the user aircraft.
+type Target struct {
+	ID            uint32    `json:"id"` // SimConnect object ID
+	Category      string    `json:"category"`
+	ATCID         string    `json:"atcId"`
+	Title         string    `json:"title"`
+	Latitude      float64   `json:"latitude"`
+	Longitude     float64   `json:"longitude"`
+	Altitude      float64   `json:"altitude"`      // feet
+	Heading       float64   `json:"heading"`       // degrees true
+	GroundSpeed   float64   `json:"groundSpeed"`   // knots
+	VerticalSpeed float64   `json:"verticalSpeed"` // feet per minute
+	OnGround      bool      `json:"onGround"`
+	LastSeen      time.Time `json:"lastSeen"`
+}
+
+// Table holds the latest state of every target. Targets which have not been
+// updated for the expiry time are removed by Expire.
+type Table struct {
+	targets map[uint32]*Target
+	expiry  time.Duration
+	mutex   sync.RWMutex
+}
+
+func NewTable(expiry time.Duration) *Table {
+	return &Table{
+		targets: make(map[uint32]*Target),
+		expiry:  expiry,
+	}
+}
+
+func (table *Table) Update(target Target) {
+	table.mutex.Lock()
+	defer table.mutex.Unlock()
+	table.targets[target.ID] = &target
+}
+
+// Expire removes outdated targets and returns their IDs.
+func (table *Table) Expire(now time.Time) []uint32 {
+	table.mutex.Lock()
+	defer table.mutex.Unlock()
+	removed := make([]uint32, 0)
+	for id, target := range table.targets {
+		if now.Sub(target.LastSeen) > table.expiry {
+			delete(table.targets, id)
+			removed = append(removed, id)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i] < removed[j] })
+	return removed
+}
+
+// Targets returns a copy of all targets, ordered by ID.
+func (table *Table) Targets() []Target {
+	table.mutex.RLock()
+	defer table.mutex.RUnlock()
+	targets := make([]Target, 0, len(table.targets))
+	for _, target := range table.targets {
+		targets = append(targets, *target)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].ID < targets[j].ID })
+	return targets
+}
+
+func (table *Table) Len() int {
+	table.mutex.RLock()
+	defer table.mutex.RUnlock()
+	return len(table.targets)
+}
+
+// Clear removes all targets, e.g. after the connection was lost.
+func (table *Table) Clear() {
+	table.mutex.Lock()
+	defer table.mutex.Unlock()
+	table.targets = make(map[uint32]*Target)
+}
@@ -1,31 +1,61 @@
 package util
 
 import (
+	"net/url"
 	"strconv"
 )
 
+// Redacted replaces secrets in logs and logged configurations.
+const Redacted = "********"
+
+// FloatFromJson returns the number with the given key. Values of other types
+// are reported as missing.
 func FloatFromJson(key string, json map[string]interface{}) (float64, bool) {
-	value, ok := json[key]
-	if !ok {
-		return 0.0, false
-	}
-	return value.(float64), true
+	value, ok := json[key].(float64)
+	return value, ok
 }
 
 func IntFromJson(key string, json map[string]interface{}) (int, bool) {
-	value, ok := json[key]
-	if !ok {
-		return 0, false
-	}
-	return int(value.(float64)), true
+	value, ok := json[key].(float64)
+	return int(value), ok
 }
 
 func StringFromJson(key string, json map[string]interface{}) (string, bool) {
-	value, ok := json[key]
-	if !ok {
-		return "", false
+	value, ok := json[key].(string)
+	return value, ok
+}
+
+// RedactURL masks the parts of a URL which commonly hold credentials: the user
+// info, the path (e.g. the token of a Discord or Slack webhook) and the query.
+// Scheme and host are kept, so the URL can still be recognized.
+func RedactURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return Redacted
+	}
+	redacted := u.Scheme + "://"
+	if u.User != nil {
+		redacted += Redacted + "@"
+	}
+	redacted += u.Host
+	if u.Path != "" && u.Path != "/" {
+		redacted += "/" + Redacted
+	}
+	if u.RawQuery != "" {
+		redacted += "?" + Redacted
+	}
+	return redacted
+}
+
+// RedactURLError masks the URL of the error of an HTTP request, see RedactURL.
+func RedactURLError(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		return &url.Error{Op: urlErr.Op, URL: RedactURL(urlErr.URL), Err: urlErr.Err}
 	}
-	return value.(string), true
+	return err
 }
 
 func FloatToString(value float64) string {
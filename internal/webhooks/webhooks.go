@@ -0,0 +1,319 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"msfs2020-gopilot/internal/util"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	EventSimConnected       = "simconnect.connected"
+	EventSimDisconnected    = "simconnect.disconnected"
+	EventClientConnected    = "client.connected"
+	EventClientDisconnected = "client.disconnected"
+	EventTakeoff            = "takeoff"
+	EventLanding            = "landing"
+	EventTeleport           = "teleport"
+
+	// The signature is the hex encoded HMAC-SHA256 of the body, prefixed with
+	// "sha256=".
+	SignatureHeader = "X-GoPilot-Signature"
+	EventHeader     = "X-GoPilot-Event"
+	DeliveryHeader  = "X-GoPilot-Delivery"
+
+	queueFileExt   = ".json"
+	pollInterval   = time.Second
+	initialBackoff = 5 * time.Second
+	maxBackoff     = 10 * time.Minute
+	maxErrorBody   = 256
+)
+
+// Events are all event types, in the order they are documented.
+var Events = []string{
+	EventSimConnected,
+	EventSimDisconnected,
+	EventClientConnected,
+	EventClientDisconnected,
+	EventTakeoff,
+	EventLanding,
+	EventTeleport,
+}
+
+// Event is the body of every POST.
+type Event struct {
+	ID   string      `json:"id"`
+	Type string      `json:"event"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+type Options struct {
+	URLs        []string
+	Secret      string
+	Events      []string // all events if empty
+	MaxAttempts int
+	Timeout     time.Duration
+}
+
+// delivery is an event for one URL. It is kept in the queue directory until
+// it was delivered or given up on, so that nothing is lost on a restart.
+type delivery struct {
+	ID          string          `json:"id"`
+	URL         string          `json:"url"`
+	Event       string          `json:"event"`
+	Body        json.RawMessage `json:"body"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"nextAttempt"`
+	LastError   string          `json:"lastError,omitempty"`
+}
+
+// Dispatcher queues events on disk and POSTs them to all URLs, retrying with
+// exponential backoff.
+type Dispatcher struct {
+	dir    string
+	opts   Options
+	events map[string]bool
+	client *http.Client
+	wake   chan struct{}
+	mutex  sync.Mutex // guards the queue files
+}
+
+func NewDispatcher(dir string, opts Options) (*Dispatcher, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	d := &Dispatcher{
+		dir:    dir,
+		opts:   opts,
+		events: make(map[string]bool),
+		client: &http.Client{Timeout: opts.Timeout},
+		wake:   make(chan struct{}, 1),
+	}
+	for _, event := range opts.Events {
+		d.events[event] = true
+	}
+	return d, nil
+}
+
+// Send queues an event for all URLs. It does not wait for the delivery.
+func (d *Dispatcher) Send(eventType string, data interface{}) error {
+	if len(d.events) > 0 && !d.events[eventType] {
+		return nil
+	}
+	event := &Event{
+		ID:   uuid.New().String(),
+		Type: eventType,
+		Time: time.Now().UTC(),
+		Data: data,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	d.mutex.Lock()
+	for i, url := range d.opts.URLs {
+		err = d.save(&delivery{
+			ID:          fmt.Sprintf("%d-%s-%d", time.Now().UnixNano(), event.ID, i),
+			URL:         url,
+			Event:       eventType,
+			Body:        body,
+			NextAttempt: time.Now(),
+		})
+		if err != nil {
+			break
+		}
+	}
+	d.mutex.Unlock()
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return err
+}
+
+// Pending returns the number of queued deliveries.
+func (d *Dispatcher) Pending() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return len(d.queued())
+}
+
+// Run delivers the queued events until ctx is cancelled, including the ones
+// left over from a previous run.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		d.deliverDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// deliverDue tries all deliveries which are due, oldest first. Once a URL
+// failed, its remaining deliveries wait for the next round so that a broken
+// endpoint is not flooded and the order is kept.
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	d.mutex.Lock()
+	paths := d.queued()
+	d.mutex.Unlock()
+
+	failed := make(map[string]bool)
+	for _, path := range paths {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		dlv, err := load(path)
+		if err != nil {
+			log.Warn("Dropping unreadable webhook delivery: ", err)
+			os.Remove(path)
+			continue
+		}
+		if failed[dlv.URL] || time.Now().Before(dlv.NextAttempt) {
+			failed[dlv.URL] = true
+			continue
+		}
+
+		permanent, err := d.post(dlv)
+		d.mutex.Lock()
+		switch {
+		case err == nil:
+			os.Remove(path)
+		case permanent || dlv.Attempts+1 >= d.opts.MaxAttempts:
+			log.Errorf("Giving up on webhook %s for %s after %d attempt(s): %s", dlv.Event, util.RedactURL(dlv.URL), dlv.Attempts+1, err)
+			os.Remove(path)
+		default:
+			failed[dlv.URL] = true
+			dlv.Attempts++
+			dlv.LastError = err.Error()
+			dlv.NextAttempt = time.Now().Add(Backoff(dlv.Attempts))
+			log.Warnf("Webhook %s for %s failed (attempt %d), retrying at %s: %s",
+				dlv.Event, util.RedactURL(dlv.URL), dlv.Attempts, dlv.NextAttempt.Format("15:04:05"), err)
+			if err := d.save(dlv); err != nil {
+				log.Error("Unable to requeue webhook delivery: ", err)
+			}
+		}
+		d.mutex.Unlock()
+	}
+}
+
+// post returns whether a failure is permanent, i.e. retrying is pointless.
+func (d *Dispatcher) post(dlv *delivery) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, dlv.URL, bytes.NewReader(dlv.Body))
+	if err != nil {
+		return true, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GoPilot-Webhook")
+	req.Header.Set(EventHeader, dlv.Event)
+	req.Header.Set(DeliveryHeader, dlv.ID)
+	if d.opts.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(d.opts.Secret, dlv.Body))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return false, util.RedactURLError(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 300 {
+		io.Copy(ioutil.Discard, resp.Body)
+		return false, nil
+	}
+	err = fmt.Errorf("%s", resp.Status)
+	if msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody)); len(bytes.TrimSpace(msg)) > 0 {
+		err = fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	// Client errors will not go away by retrying, except for these.
+	permanent := resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests
+	return permanent, err
+}
+
+// Sign returns the signature of a body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the delay before the next attempt: 5 s after the first
+// failure, doubled after every further one, at most 10 min.
+func Backoff(attempts int) time.Duration {
+	delay := initialBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+// queued returns the paths of all deliveries, oldest first.
+func (d *Dispatcher) queued() []string {
+	entries, err := ioutil.ReadDir(d.dir)
+	if err != nil {
+		return nil
+	}
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), queueFileExt) {
+			paths = append(paths, filepath.Join(d.dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (d *Dispatcher) save(dlv *delivery) error {
+	buf, err := json.Marshal(dlv)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so that a crash never leaves a partial
+	// delivery behind.
+	path := filepath.Join(d.dir, dlv.ID+queueFileExt)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func load(path string) (*delivery, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dlv := &delivery{}
+	if err := json.Unmarshal(buf, dlv); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+	return dlv, nil
+}
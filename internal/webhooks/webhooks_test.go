@@ -0,0 +1,281 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		delay    time.Duration
+	}{
+		{attempts: 0, delay: 5 * time.Second},
+		{attempts: 1, delay: 5 * time.Second},
+		{attempts: 2, delay: 10 * time.Second},
+		{attempts: 3, delay: 20 * time.Second},
+		{attempts: 7, delay: 320 * time.Second},
+		{attempts: 8, delay: 10 * time.Minute},
+		{attempts: 100, delay: 10 * time.Minute},
+	}
+	for _, test := range tests {
+		if got := Backoff(test.attempts); got != test.delay {
+			t.Errorf("Backoff(%d) = %s, want %s", test.attempts, got, test.delay)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	// RFC 4231 test case 2.
+	got := Sign("Jefe", []byte("what do ya want for nothing?"))
+	if want := "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"; got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
+
+type received struct {
+	event     Event
+	header    http.Header
+	signature string
+}
+
+// endpoint records the deliveries and answers with the next status, or 200
+// once all statuses are used.
+type endpoint struct {
+	server   *httptest.Server
+	statuses []int
+	received []received
+	mutex    sync.Mutex
+}
+
+func newEndpoint(t *testing.T, statuses ...int) *endpoint {
+	e := &endpoint{statuses: statuses}
+	e.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+		status := http.StatusOK
+		if len(e.statuses) > 0 {
+			status, e.statuses = e.statuses[0], e.statuses[1:]
+		}
+		if status == http.StatusOK {
+			event := Event{}
+			json.Unmarshal(body, &event)
+			e.received = append(e.received, received{event: event, header: r.Header, signature: Sign("secret", body)})
+		}
+		w.WriteHeader(status)
+		w.Write([]byte("nope"))
+	}))
+	t.Cleanup(e.server.Close)
+	return e
+}
+
+func (e *endpoint) events() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	events := make([]string, len(e.received))
+	for i, r := range e.received {
+		events[i] = r.event.Type
+	}
+	return events
+}
+
+func newTestDispatcher(t *testing.T, dir string, events []string, urls ...string) *Dispatcher {
+	t.Helper()
+	d, err := NewDispatcher(dir, Options{
+		URLs:        urls,
+		Secret:      "secret",
+		Events:      events,
+		MaxAttempts: 3,
+		Timeout:     time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// makeDue lets all queued deliveries be retried now.
+func makeDue(t *testing.T, d *Dispatcher) {
+	t.Helper()
+	for _, path := range d.queued() {
+		dlv, err := load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dlv.NextAttempt = time.Now()
+		if err := d.save(dlv); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDelivery(t *testing.T) {
+	e := newEndpoint(t)
+	d := newTestDispatcher(t, t.TempDir(), []string{EventTakeoff, EventLanding}, e.server.URL)
+	if err := d.Send(EventTakeoff, map[string]string{"airport": "EDDF"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Send(EventClientConnected, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := d.Pending(); n != 1 {
+		t.Fatalf("%d deliveries pending, want the takeoff only", n)
+	}
+	d.deliverDue(context.Background())
+
+	if d.Pending() != 0 || len(e.received) != 1 {
+		t.Fatalf("%d pending, %d received", d.Pending(), len(e.received))
+	}
+	r := e.received[0]
+	if r.event.Type != EventTakeoff || r.event.ID == "" || r.event.Data.(map[string]interface{})["airport"] != "EDDF" {
+		t.Errorf("received %+v", r.event)
+	}
+	if r.header.Get(EventHeader) != EventTakeoff || r.header.Get(DeliveryHeader) == "" {
+		t.Errorf("headers %v", r.header)
+	}
+	if got := r.header.Get(SignatureHeader); got != r.signature {
+		t.Errorf("signature %s, want %s", got, r.signature)
+	}
+}
+
+func TestFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		pending  int
+		attempts int
+	}{
+		{name: "bad request", statuses: []int{http.StatusBadRequest}},
+		{name: "not found", statuses: []int{http.StatusNotFound}},
+		{name: "server error", statuses: []int{http.StatusInternalServerError}, pending: 1, attempts: 1},
+		{name: "too many requests", statuses: []int{http.StatusTooManyRequests}, pending: 1, attempts: 1},
+		{name: "request timeout", statuses: []int{http.StatusRequestTimeout}, pending: 1, attempts: 1},
+	}
+	for _, test := range tests {
+		e := newEndpoint(t, test.statuses...)
+		d := newTestDispatcher(t, t.TempDir(), nil, e.server.URL)
+		d.Send(EventLanding, nil)
+		d.deliverDue(context.Background())
+		queued := d.queued()
+		if len(queued) != test.pending {
+			t.Errorf("%s: %d deliveries pending, want %d", test.name, len(queued), test.pending)
+			continue
+		}
+		if test.pending == 0 {
+			continue
+		}
+		dlv, err := load(queued[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dlv.Attempts != test.attempts || dlv.LastError == "" || !dlv.NextAttempt.After(time.Now()) {
+			t.Errorf("%s: requeued %+v", test.name, dlv)
+		}
+		// Not due yet.
+		d.deliverDue(context.Background())
+		if len(e.received) != 0 {
+			t.Errorf("%s: retried before the backoff", test.name)
+		}
+	}
+}
+
+func TestGiveUpAfterMaxAttempts(t *testing.T) {
+	e := newEndpoint(t, 500, 502, 503, 504)
+	d := newTestDispatcher(t, t.TempDir(), nil, e.server.URL)
+	d.Send(EventLanding, nil)
+	for i := 0; i < 3; i++ {
+		makeDue(t, d)
+		d.deliverDue(context.Background())
+	}
+	if n := d.Pending(); n != 0 {
+		t.Errorf("%d deliveries pending after 3 attempts", n)
+	}
+}
+
+// A failing URL keeps the order of its events and does not hold up the others.
+func TestOrderAfterFailure(t *testing.T) {
+	failing := newEndpoint(t, http.StatusServiceUnavailable)
+	working := newEndpoint(t)
+	d := newTestDispatcher(t, t.TempDir(), nil, failing.server.URL, working.server.URL)
+	events := []string{EventTakeoff, EventLanding, EventTeleport}
+	for _, event := range events {
+		d.Send(event, nil)
+	}
+
+	d.deliverDue(context.Background())
+	if got := working.events(); !equalStrings(got, events) {
+		t.Errorf("working URL received %v, want %v", got, events)
+	}
+	if got := failing.events(); len(got) != 0 || d.Pending() != 3 {
+		t.Errorf("failing URL received %v, %d pending", got, d.Pending())
+	}
+
+	makeDue(t, d)
+	d.deliverDue(context.Background())
+	if got := failing.events(); !equalStrings(got, events) {
+		t.Errorf("failing URL received %v after recovering, want %v", got, events)
+	}
+	if n := d.Pending(); n != 0 {
+		t.Errorf("%d deliveries pending", n)
+	}
+}
+
+// Deliveries queued before a restart are sent by the next dispatcher.
+func TestRestart(t *testing.T) {
+	e := newEndpoint(t)
+	dir := t.TempDir()
+	before := newTestDispatcher(t, dir, nil, e.server.URL)
+	before.Send(EventTakeoff, nil)
+	before.Send(EventLanding, nil)
+	// Left over by a crash while saving, and a corrupt delivery.
+	ioutil.WriteFile(filepath.Join(dir, "0-partial.json.tmp"), []byte(`{"id":`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "0-corrupt.json"), []byte(`{"id":`), 0644)
+
+	after := newTestDispatcher(t, dir, nil, e.server.URL)
+	if n := after.Pending(); n != 3 {
+		t.Fatalf("%d deliveries pending after the restart, want 3", n)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		after.Run(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for after.Pending() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if n := after.Pending(); n != 0 {
+		t.Errorf("%d deliveries pending", n)
+	}
+	if got := e.events(); !equalStrings(got, []string{EventTakeoff, EventLanding}) {
+		t.Errorf("received %v, want takeoff and landing", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "0-partial.json.tmp")); err != nil {
+		t.Error("partial file was removed: ", err)
+	}
+}
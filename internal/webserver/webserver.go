@@ -2,6 +2,8 @@ package webserver
 
 import (
 	"context"
+	"io/fs"
+	"net"
 	"net/http"
 	"time"
 
@@ -15,30 +17,36 @@ type Route struct {
 }
 
 type WebServer struct {
-	address  string
-	shutdown chan bool
+	address string
+	server  *http.Server
 }
 
-func NewWebServer(address string, shutdown chan bool) *WebServer {
+func NewWebServer(address string) *WebServer {
 	server := &WebServer{
-		address:  address,
-		shutdown: shutdown,
+		address: address,
 	}
 	return server
 }
 
-func (ws *WebServer) Run(routes []Route, staticAssetsDir string) {
+// Run binds the address and serves the routes in the background. Errors
+// binding the address, e.g. because the port is in use, are returned.
+func (ws *WebServer) Run(routes []Route, staticAssetsDir string, assets fs.FS) error {
 	// Serve static files: https://golangcode.com/serve-static-assets-using-the-mux-router/
 	router := mux.NewRouter().StrictSlash(true)
 	router.
 		PathPrefix(staticAssetsDir).
-		Handler(http.StripPrefix(staticAssetsDir, http.FileServer(http.Dir("."+staticAssetsDir))))
+		Handler(http.StripPrefix(staticAssetsDir, http.FileServer(http.FS(assets))))
 
 	for _, route := range routes {
 		router.HandleFunc(route.Pattern, route.Handler)
 	}
 
-	server := &http.Server{
+	listener, err := net.Listen("tcp", ws.address)
+	if err != nil {
+		return err
+	}
+
+	ws.server = &http.Server{
 		Addr:         ws.address,
 		WriteTimeout: time.Second * 15,
 		ReadTimeout:  time.Second * 15,
@@ -47,24 +55,19 @@ func (ws *WebServer) Run(routes []Route, staticAssetsDir string) {
 	}
 
 	go func() {
-		if _, ok := (<-ws.shutdown); !ok {
-			log.Error("channel is closed")
+		if err := ws.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Error("Web server stopped: ", err)
 		}
-		var wait time.Duration = time.Second * 15
-		ctx, cancel := context.WithTimeout(context.Background(), wait)
-		defer cancel()
-		// Doesn't block if no connections, but will otherwise wait
-		// until the timeout deadline.
-		log.Info("Shutting down web server")
-		server.Shutdown(ctx)
-		// Optionally, you could run srv.Shutdown in a goroutine and block on
-		// <-ctx.Done() if your application should wait for other services
-		// to finalize based on context cancellation.
 	}()
+	return nil
+}
 
-	go func() {
-		if err := server.ListenAndServe(); err != nil {
-			log.Error(err)
-		}
-	}()
+// Shutdown stops accepting connections and waits for the active requests
+// until ctx is done. Hijacked connections, i.e. WebSockets, are not waited for.
+func (ws *WebServer) Shutdown(ctx context.Context) error {
+	if ws.server == nil {
+		return nil
+	}
+	log.Info("Shutting down web server")
+	return ws.server.Shutdown(ctx)
 }
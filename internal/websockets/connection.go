@@ -3,27 +3,33 @@ package websockets
 
 import (
 	"bytes"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
 )
 
 type Connection struct {
-	wsconn         *websocket.Conn
-	eventReceiver  chan *SocketEvent
-	deregistration chan *Connection
-	messageSender  chan []byte
-	messageQueue   chan []byte
-	close          chan bool
-	timestamp      time.Time
-	uuid           string
-	connected      bool
+	wsconn        *websocket.Conn
+	eventReceiver chan *SocketEvent
+	queue         *queue
+	options       *Options
+	close         chan bool
+	timestamp     time.Time
+	uuid          string
+	encoding      string
+	// Set while every message finds the queue full, see Send.
+	congestedSince time.Time
+	slow           bool
+	// Sent instead of an empty close frame, see CloseWithReason.
+	closeMessage []byte
+	mutex        sync.Mutex
 }
 
 const (
 	maxMessageSize = 2048
-	flushTime      = time.Millisecond * 30
 	pingTime       = time.Second * 60
 	pongTime       = pingTime + time.Second*10
 	writeDelay     = time.Second * 10
@@ -34,16 +40,16 @@ var (
 	space   = []byte{' '}
 )
 
-func NewConnection(conn *websocket.Conn, eventReceiver chan *SocketEvent, deregistration chan *Connection) *Connection {
+func NewConnection(conn *websocket.Conn, encoding string, options *Options, eventReceiver chan *SocketEvent) *Connection {
 	connection := &Connection{
-		wsconn:         conn,
-		eventReceiver:  eventReceiver,
-		deregistration: deregistration,
-		timestamp:      time.Now(),
-		messageSender:  make(chan []byte, 256),
-		messageQueue:   make(chan []byte, 256),
-		close:          make(chan bool, 1),
-		uuid:           uuid.New().String(),
+		wsconn:        conn,
+		eventReceiver: eventReceiver,
+		timestamp:     time.Now(),
+		encoding:      encoding,
+		queue:         newQueue(options.QueueSize),
+		options:       options,
+		close:         make(chan bool, 1),
+		uuid:          uuid.New().String(),
 	}
 	return connection
 }
@@ -52,29 +58,77 @@ func (connection *Connection) UUID() string {
 	return connection.uuid
 }
 
+func (connection *Connection) RemoteAddr() string {
+	return connection.wsconn.RemoteAddr().String()
+}
+
+func (connection *Connection) ConnectedSince() time.Time {
+	return connection.timestamp
+}
+
+func (connection *Connection) Encoding() string {
+	return connection.encoding
+}
+
+func (connection *Connection) QueueDepth() int {
+	return connection.queue.len()
+}
+
+// Disconnect closes the underlying network connection. The receiver notices
+// the broken connection and deregisters it like any other disconnect.
+func (connection *Connection) Disconnect() {
+	connection.wsconn.Close()
+}
+
+// Run serves the connection until it breaks.
 func (connection *Connection) Run() {
 	connection.receiver()
 	connection.sender()
-	connection.connected = true
-
 	<-connection.close
-	connection.connected = false
-
-	connection.deregistration <- connection
 }
 
 func (connection *Connection) Close() {
-	if connection.connected {
-		connection.close <- true
-	}
+	connection.queue.close()
 	connection.wsconn.Close()
-	defer close(connection.close)
-	defer close(connection.messageSender)
-	defer close(connection.messageQueue)
 }
 
-func (connection *Connection) Send(message []byte) {
-	connection.messageSender <- message
+// CloseWithReason drops the queued messages and sends a close frame with the
+// code and the reason. The connection ends once the client has answered it.
+func (connection *Connection) CloseWithReason(code int, reason string) {
+	connection.mutex.Lock()
+	connection.closeMessage = websocket.FormatCloseMessage(code, reason)
+	connection.mutex.Unlock()
+	connection.queue.close()
+}
+
+// Send queues a message. It never blocks: if the client can't keep up, state
+// messages are dropped and a client which stays congested for longer than
+// Options.SlowClientTimeout is disconnected.
+func (connection *Connection) Send(message *Message) {
+	dropped, full := connection.queue.push(message)
+	if connection.options.OnDrop != nil {
+		for _, reason := range dropped {
+			connection.options.OnDrop(reason)
+		}
+	}
+	connection.mutex.Lock()
+	defer connection.mutex.Unlock()
+	if !full {
+		connection.congestedSince = time.Time{}
+		return
+	}
+	if connection.congestedSince.IsZero() {
+		connection.congestedSince = time.Now()
+		return
+	}
+	if !connection.slow && time.Since(connection.congestedSince) > connection.options.SlowClientTimeout {
+		connection.slow = true
+		log.Warnf("Disconnecting slow client %s (%d messages queued)", connection.uuid, connection.queue.len())
+		if connection.options.OnSlowClient != nil {
+			connection.options.OnSlowClient(connection)
+		}
+		connection.Disconnect()
+	}
 }
 
 func (connection *Connection) receiver() {
@@ -108,28 +162,6 @@ func (connection *Connection) receiver() {
 }
 
 func (connection *Connection) sender() {
-	go func() {
-		flush := time.NewTicker(flushTime)
-		defer flush.Stop()
-
-		var buf bytes.Buffer
-		for {
-			select {
-			case <-flush.C:
-				message, err := buf.ReadBytes('\n')
-				if err == nil {
-					connection.messageQueue <- message
-				}
-
-			case message, ok := <-connection.messageSender:
-				if !ok {
-					return
-				}
-				buf.Write(message)
-				buf.WriteString("\n")
-			}
-		}
-	}()
 	go func() {
 		ping := time.NewTicker(pingTime)
 		defer ping.Stop()
@@ -139,24 +171,58 @@ func (connection *Connection) sender() {
 			case <-ping.C:
 				connection.wsconn.SetWriteDeadline(time.Now().Add(writeDelay))
 				if err := connection.wsconn.WriteMessage(websocket.PingMessage, []byte{}); err != nil {
+					connection.Disconnect()
 					return
 				}
 
-			case message, ok := <-connection.messageQueue:
-				connection.wsconn.SetWriteDeadline(time.Now().Add(writeDelay))
+			case <-connection.queue.notify:
+				messages, ok := connection.queue.pop()
 				if !ok {
-					connection.wsconn.WriteMessage(websocket.CloseMessage, []byte{})
-					return
-				}
-				w, err := connection.wsconn.NextWriter(websocket.TextMessage)
-				if err != nil {
+					connection.mutex.Lock()
+					closeMessage := connection.closeMessage
+					connection.mutex.Unlock()
+					if closeMessage == nil {
+						closeMessage = []byte{}
+					}
+					connection.wsconn.SetWriteDeadline(time.Now().Add(writeDelay))
+					connection.wsconn.WriteMessage(websocket.CloseMessage, closeMessage)
 					return
 				}
-				w.Write(message)
-				if err := w.Close(); err != nil {
-					return
+				for _, message := range messages {
+					if err := connection.write(message); err != nil {
+						// The receiver notices and deregisters the connection.
+						connection.Disconnect()
+						return
+					}
 				}
 			}
 		}
 	}()
 }
+
+func (connection *Connection) write(message *Message) error {
+	messageType := websocket.TextMessage
+	data := message.Data
+	if IsBinary(connection.encoding) {
+		// Binary messages can't be delimited by newlines and are sent in
+		// frames of their own.
+		messageType = websocket.BinaryMessage
+		if !message.Encoded {
+			var err error
+			if data, err = transcode(connection.encoding, message.Data); err != nil {
+				log.Warn("Unable to encode message: ", err)
+				return nil
+			}
+		}
+	}
+	connection.wsconn.SetWriteDeadline(time.Now().Add(writeDelay))
+	w, err := connection.wsconn.NextWriter(messageType)
+	if err != nil {
+		return err
+	}
+	w.Write(data)
+	if messageType == websocket.TextMessage {
+		w.Write(newline)
+	}
+	return w.Close()
+}
@@ -0,0 +1,98 @@
+package websockets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack"
+)
+
+// Encodings of the messages sent to a client. A client picks one with the
+// subprotocol (gopilot.msgpack) or the query (/ws?encoding=msgpack) when it
+// connects. Clients always send JSON.
+const (
+	EncodingJSON    = "json"
+	EncodingMsgPack = "msgpack"
+	EncodingCBOR    = "cbor"
+
+	subprotocolPrefix = "gopilot."
+)
+
+var (
+	Encodings = []string{EncodingJSON, EncodingMsgPack, EncodingCBOR}
+
+	cborEncoding cbor.EncMode
+)
+
+func init() {
+	var err error
+	// Floats are encoded with the fewest bytes which don't lose precision.
+	cborEncoding, err = cbor.EncOptions{ShortestFloat: cbor.ShortestFloat16}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+}
+
+func subprotocols() []string {
+	protocols := make([]string, len(Encodings))
+	for i, encoding := range Encodings {
+		protocols[i] = subprotocolPrefix + encoding
+	}
+	return protocols
+}
+
+func validEncoding(encoding string) bool {
+	for _, e := range Encodings {
+		if e == encoding {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBinary reports whether messages in the encoding are sent as binary frames.
+func IsBinary(encoding string) bool {
+	return encoding == EncodingMsgPack || encoding == EncodingCBOR
+}
+
+// Marshal encodes a message in the encoding.
+func Marshal(encoding string, v interface{}) ([]byte, error) {
+	switch encoding {
+	case EncodingJSON:
+		return json.Marshal(v)
+
+	case EncodingMsgPack:
+		buf := &bytes.Buffer{}
+		err := msgpack.NewEncoder(buf).UseCompactEncoding(true).Encode(v)
+		return buf.Bytes(), err
+
+	case EncodingCBOR:
+		return cborEncoding.Marshal(v)
+	}
+	return nil, fmt.Errorf("unknown encoding: %s", encoding)
+}
+
+// transcode converts a JSON message into the encoding.
+func transcode(encoding string, message []byte) ([]byte, error) {
+	if encoding == EncodingJSON {
+		return message, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(message, &v); err != nil {
+		return nil, err
+	}
+	return Marshal(encoding, v)
+}
+
+func negotiateEncoding(subprotocol, query string) string {
+	if encoding := strings.TrimPrefix(subprotocol, subprotocolPrefix); encoding != subprotocol && validEncoding(encoding) {
+		return encoding
+	}
+	if validEncoding(query) {
+		return query
+	}
+	return EncodingJSON
+}
@@ -0,0 +1,108 @@
+package websockets
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// simVarsMessage is a simvars message of a typical panel: the values keyed by
+// moniker for JSON and by their index in the moniker table for the binary
+// encodings.
+func simVarsMessage(encoding string) map[string]interface{} {
+	values := []interface{}{
+		3512.4172, 118.25, 2.5, -1.25, 271.8, 265.33, -512.0, 29.92, 2412.0, 0.75,
+		47.259, 11.352, true, false, "Cessna Skyhawk G1000 Asobo", 1.0,
+	}
+	monikers := []string{
+		"altitude", "airspeed", "pitch", "bank", "heading", "track", "vs", "kohlsman", "rpm", "throttle",
+		"lat", "lon", "onground", "parkingbrake", "title", "flaps",
+	}
+	var data interface{}
+	if IsBinary(encoding) {
+		indexed := make(map[int]interface{}, len(values))
+		for i, value := range values {
+			indexed[i] = value
+		}
+		data = indexed
+	} else {
+		named := make(map[string]interface{}, len(values))
+		for i, value := range values {
+			named[monikers[i]] = value
+		}
+		data = named
+	}
+	return map[string]interface{}{"type": "simvars", "meta": "panel", "data": data}
+}
+
+func TestMarshal(t *testing.T) {
+	for _, encoding := range Encodings {
+		buf, err := Marshal(encoding, simVarsMessage(encoding))
+		if err != nil || len(buf) == 0 {
+			t.Errorf("Marshal(%s) = %d bytes, %v", encoding, len(buf), err)
+		}
+	}
+	if _, err := Marshal("xml", nil); err == nil {
+		t.Error("unknown encoding was accepted")
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		subprotocol, query, want string
+	}{
+		{subprotocol: "gopilot.cbor", query: "msgpack", want: EncodingCBOR},
+		{subprotocol: "", query: "msgpack", want: EncodingMsgPack},
+		{subprotocol: "gopilot.xml", query: "", want: EncodingJSON},
+		{subprotocol: "cbor", query: "", want: EncodingJSON},
+	}
+	for _, test := range tests {
+		if got := negotiateEncoding(test.subprotocol, test.query); got != test.want {
+			t.Errorf("negotiateEncoding(%q, %q) = %s, want %s", test.subprotocol, test.query, got, test.want)
+		}
+	}
+}
+
+// BenchmarkMarshal compares the encodings of a simvars message, which is sent
+// to every client on every tick. Besides the time, it reports the message size.
+func BenchmarkMarshal(b *testing.B) {
+	for _, encoding := range Encodings {
+		encoding := encoding
+		msg := simVarsMessage(encoding)
+		b.Run(encoding, func(b *testing.B) {
+			var size int
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf, err := Marshal(encoding, msg)
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = len(buf)
+			}
+			b.ReportMetric(float64(size), "bytes/msg")
+		})
+	}
+}
+
+// BenchmarkTranscode measures the conversion of a JSON broadcast for a client
+// using a binary encoding.
+func BenchmarkTranscode(b *testing.B) {
+	message, err := json.Marshal(simVarsMessage(EncodingJSON))
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, encoding := range Encodings {
+		encoding := encoding
+		b.Run(encoding, func(b *testing.B) {
+			var size int
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf, err := transcode(encoding, message)
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = len(buf)
+			}
+			b.ReportMetric(float64(size), "bytes/msg")
+		})
+	}
+}
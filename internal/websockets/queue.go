@@ -0,0 +1,111 @@
+package websockets
+
+import (
+	"sync"
+)
+
+const (
+	DropReasonCoalesced = "coalesced"
+	DropReasonQueueFull = "queue_full"
+)
+
+// Message is a message queued for a client.
+type Message struct {
+	Data []byte
+	// Encoded is set if Data is in the encoding of the client already.
+	// Otherwise it is JSON.
+	Encoded bool
+	// Key marks a message which only carries the latest state, e.g. the
+	// SimVars of a request. It replaces a queued message with the same key,
+	// and such messages are the first to be dropped when the queue is full.
+	// Messages without a key are never dropped.
+	Key string
+}
+
+// queue is the bounded outbound queue of a connection. Pushing never blocks.
+type queue struct {
+	messages []*Message
+	size     int
+	notify   chan struct{}
+	closed   bool
+	mutex    sync.Mutex
+}
+
+func newQueue(size int) *queue {
+	return &queue{
+		messages: make([]*Message, 0, size),
+		size:     size,
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// push queues a message and returns the reasons of the messages which were
+// dropped to make room, and whether the queue is full.
+func (q *queue) push(message *Message) ([]string, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return nil, false
+	}
+	defer q.signal()
+
+	if message.Key != "" {
+		for i, queued := range q.messages {
+			if queued.Key == message.Key {
+				q.messages[i] = message
+				return []string{DropReasonCoalesced}, len(q.messages) >= q.size
+			}
+		}
+	}
+	if len(q.messages) < q.size {
+		q.messages = append(q.messages, message)
+		return nil, len(q.messages) >= q.size
+	}
+
+	// Make room by dropping the oldest message which may be dropped.
+	for i, queued := range q.messages {
+		if queued.Key != "" {
+			q.messages = append(q.messages[:i], q.messages[i+1:]...)
+			q.messages = append(q.messages, message)
+			return []string{DropReasonQueueFull}, true
+		}
+	}
+	if message.Key != "" {
+		return []string{DropReasonQueueFull}, true
+	}
+	// Control messages are never dropped, even if that exceeds the size.
+	q.messages = append(q.messages, message)
+	return nil, true
+}
+
+// pop takes all queued messages. It returns false once the queue is closed.
+func (q *queue) pop() ([]*Message, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return nil, false
+	}
+	messages := q.messages
+	q.messages = make([]*Message, 0, q.size)
+	return messages, true
+}
+
+func (q *queue) len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.messages)
+}
+
+func (q *queue) close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.closed = true
+	q.signal()
+}
+
+func (q *queue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
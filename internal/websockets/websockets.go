@@ -2,8 +2,11 @@
 package websockets
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -14,13 +17,29 @@ type SocketEvent struct {
 	Data       []byte
 }
 
+// WebSocket keeps track of the connected clients. Its methods may be called
+// from any goroutine.
 type WebSocket struct {
-	EventReceiver  chan *SocketEvent
-	connections    map[*Connection]int
-	registration   chan *Connection
-	deregistration chan *Connection
-	broadcaster    chan []byte
-	sender         chan *SocketEvent
+	EventReceiver chan *SocketEvent
+	connections   map[string]*Connection // by UUID
+	options       *Options
+	// Set by Shutdown. Serve rejects new clients from then on.
+	closing bool
+	serving sync.WaitGroup
+	mutex   sync.RWMutex
+}
+
+// Options of the outbound queues of the connections.
+type Options struct {
+	// Messages queued per connection before state messages are dropped.
+	QueueSize int
+	// Time after which a client whose queue stays full is disconnected.
+	SlowClientTimeout time.Duration
+	// OnDrop is called for every dropped message, see DropReasonCoalesced and
+	// DropReasonQueueFull.
+	OnDrop func(reason string)
+	// OnSlowClient is called before a slow client is disconnected.
+	OnSlowClient func(connection *Connection)
 }
 
 const (
@@ -37,92 +56,162 @@ func init() {
 	upgrader = websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
+		Subprotocols:    subprotocols(),
 	}
 }
 
-func NewWebSocket() *WebSocket {
+func NewWebSocket(options Options) *WebSocket {
 	socket := &WebSocket{
-		EventReceiver:  make(chan *SocketEvent),
-		connections:    make(map[*Connection]int),
-		registration:   make(chan *Connection),
-		deregistration: make(chan *Connection),
-		broadcaster:    make(chan []byte, 256),
-		sender:         make(chan *SocketEvent),
-	}
-	go socket.Run()
+		EventReceiver: make(chan *SocketEvent),
+		connections:   make(map[string]*Connection),
+		options:       &options,
+	}
 	return socket
 }
 
 func (socket *WebSocket) ConnectionCount() int {
+	socket.mutex.RLock()
+	defer socket.mutex.RUnlock()
 	return len(socket.connections)
 }
 
 func (socket *WebSocket) ConnectionUUIDs() []string {
-	uuids := make([]string, 0)
-	for key := range socket.connections {
-		uuids = append(uuids, key.UUID())
+	socket.mutex.RLock()
+	defer socket.mutex.RUnlock()
+	uuids := make([]string, 0, len(socket.connections))
+	for uuid := range socket.connections {
+		uuids = append(uuids, uuid)
 	}
 	return uuids
 }
 
-func (socket *WebSocket) Run() {
-	for {
-		select {
-		case connection := <-socket.registration:
-			socket.connections[connection] = 1
-			socket.EventReceiver <- &SocketEvent{
-				Type:       SocketEventConnected,
-				Connection: connection,
-				Data:       nil,
-			}
-
-		case connection := <-socket.deregistration:
-			if _, exists := socket.connections[connection]; exists {
-				socket.EventReceiver <- &SocketEvent{
-					Type:       SocketEventDisconnected,
-					Connection: connection,
-					Data:       nil,
-				}
-				connection.Close()
-				delete(socket.connections, connection)
-			}
-
-		case data := <-socket.broadcaster:
-			for client := range socket.connections {
-				client.Send(data)
-			}
+func (socket *WebSocket) Connections() []*Connection {
+	socket.mutex.RLock()
+	defer socket.mutex.RUnlock()
+	connections := make([]*Connection, 0, len(socket.connections))
+	for _, connection := range socket.connections {
+		connections = append(connections, connection)
+	}
+	return connections
+}
 
-		case message := <-socket.sender:
-			message.Connection.Send(message.Data)
-		}
+func (socket *WebSocket) Disconnect(connectionUUID string) bool {
+	connection, ok := socket.connection(connectionUUID)
+	if ok {
+		connection.Disconnect()
 	}
+	return ok
 }
 
+// Serve upgrades a request to a WebSocket connection and serves it until it
+// breaks. The connected event is delivered before any message of the client,
+// the disconnected event after the last one.
 func (socket *WebSocket) Serve(w http.ResponseWriter, r *http.Request) {
+	if socket.isClosing() {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	query := r.URL.Query().Get("encoding")
+	if query != "" && !validEncoding(query) {
+		http.Error(w, "unknown encoding: "+query, http.StatusBadRequest)
+		return
+	}
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
 		return
 	}
-	connection := NewConnection(conn, socket.EventReceiver, socket.deregistration)
-	socket.registration <- connection
+	encoding := negotiateEncoding(conn.Subprotocol(), query)
+	connection := NewConnection(conn, encoding, socket.options, socket.EventReceiver)
+
+	socket.mutex.Lock()
+	if socket.closing {
+		socket.mutex.Unlock()
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "shutting down"))
+		conn.Close()
+		return
+	}
+	socket.connections[connection.uuid] = connection
+	socket.serving.Add(1)
+	socket.mutex.Unlock()
+	defer socket.serving.Done()
+	socket.EventReceiver <- &SocketEvent{
+		Type:       SocketEventConnected,
+		Connection: connection,
+	}
+
 	connection.Run()
+
+	socket.mutex.Lock()
+	delete(socket.connections, connection.uuid)
+	socket.mutex.Unlock()
+	socket.EventReceiver <- &SocketEvent{
+		Type:       SocketEventDisconnected,
+		Connection: connection,
+	}
+	connection.Close()
+}
+
+// Shutdown rejects new clients and sends a close frame with the reason to the
+// connected ones. It returns once all clients are gone and their disconnected
+// events have been delivered. Clients which haven't answered the close frame
+// when ctx is done are disconnected.
+func (socket *WebSocket) Shutdown(ctx context.Context, reason string) {
+	socket.mutex.Lock()
+	socket.closing = true
+	socket.mutex.Unlock()
+
+	for _, connection := range socket.Connections() {
+		connection.CloseWithReason(websocket.CloseGoingAway, reason)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		socket.serving.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return
+	case <-ctx.Done():
+	}
+	for _, connection := range socket.Connections() {
+		connection.Disconnect()
+	}
+	<-done
 }
 
-func (socket *WebSocket) Broadcast(message []byte) {
-	socket.broadcaster <- message
+func (socket *WebSocket) isClosing() bool {
+	socket.mutex.RLock()
+	defer socket.mutex.RUnlock()
+	return socket.closing
 }
 
-func (socket *WebSocket) Send(connectionUUID string, data []byte) bool {
-	for connection := range socket.connections {
-		if connection.uuid == connectionUUID {
-			socket.sender <- &SocketEvent{
-				Type:       SocketEventMessage,
-				Connection: connection,
-				Data:       data,
-			}
-			return true
-		}
+func (socket *WebSocket) Broadcast(message *Message) {
+	for _, connection := range socket.Connections() {
+		connection.Send(message)
 	}
-	return false
+}
+
+func (socket *WebSocket) Send(connectionUUID string, message *Message) bool {
+	connection, ok := socket.connection(connectionUUID)
+	if ok {
+		connection.Send(message)
+	}
+	return ok
+}
+
+// Encoding returns the encoding of the messages sent to a client.
+func (socket *WebSocket) Encoding(connectionUUID string) (string, bool) {
+	if connection, ok := socket.connection(connectionUUID); ok {
+		return connection.encoding, true
+	}
+	return "", false
+}
+
+func (socket *WebSocket) connection(connectionUUID string) (*Connection, bool) {
+	socket.mutex.RLock()
+	defer socket.mutex.RUnlock()
+	connection, ok := socket.connections[connectionUUID]
+	return connection, ok
 }
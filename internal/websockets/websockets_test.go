@@ -0,0 +1,193 @@
+package websockets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// startSocket serves a WebSocket and consumes its events until the test ends.
+// The returned function reports the number of events per type.
+func startSocket(t *testing.T, options Options) (*WebSocket, string, func() map[int]int) {
+	t.Helper()
+	socket := NewWebSocket(options)
+	counts := make(map[int]int)
+	var mutex sync.Mutex
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case event := <-socket.EventReceiver:
+				mutex.Lock()
+				counts[event.Type]++
+				mutex.Unlock()
+			case <-done:
+				return
+			}
+		}
+	}()
+	server := httptest.NewServer(http.HandlerFunc(socket.Serve))
+	t.Cleanup(func() {
+		server.Close()
+		close(done)
+	})
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	return socket, url, func() map[int]int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		snapshot := make(map[int]int, len(counts))
+		for k, v := range counts {
+			snapshot[k] = v
+		}
+		return snapshot
+	}
+}
+
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestConcurrentConnectBroadcastDisconnect(t *testing.T) {
+	const clients = 20
+	socket, url, counts := startSocket(t, Options{QueueSize: 16, SlowClientTimeout: time.Second})
+
+	stopBroadcast := make(chan struct{})
+	var broadcasts sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		broadcasts.Add(1)
+		go func() {
+			defer broadcasts.Done()
+			for {
+				select {
+				case <-stopBroadcast:
+					return
+				default:
+				}
+				socket.Broadcast(&Message{Data: []byte(`{"type":"status"}`), Key: "status"})
+				for _, uuid := range socket.ConnectionUUIDs() {
+					socket.Send(uuid, &Message{Data: []byte(`{"type":"ping"}`)})
+					socket.Encoding(uuid)
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			if _, _, err := conn.ReadMessage(); err != nil {
+				t.Error(err)
+				return
+			}
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"echo"}`))
+			if i%2 == 0 {
+				// Let the server drop some of the clients.
+				for _, connection := range socket.Connections() {
+					if connection.RemoteAddr() == conn.LocalAddr().String() {
+						socket.Disconnect(connection.UUID())
+					}
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	waitFor(t, "all clients to disconnect", func() bool {
+		return socket.ConnectionCount() == 0 && counts()[SocketEventDisconnected] == clients
+	})
+	close(stopBroadcast)
+	broadcasts.Wait()
+
+	if got := counts()[SocketEventConnected]; got != clients {
+		t.Errorf("connected events = %d, want %d", got, clients)
+	}
+}
+
+func TestSendToUnknownConnection(t *testing.T) {
+	socket := NewWebSocket(Options{QueueSize: 1})
+	if socket.Send("nobody", &Message{Data: []byte("{}")}) {
+		t.Error("Send to an unknown connection reported success")
+	}
+	if socket.Disconnect("nobody") {
+		t.Error("Disconnect of an unknown connection reported success")
+	}
+}
+
+func TestShutdownSendsCloseFrame(t *testing.T) {
+	socket, url, _ := startSocket(t, Options{QueueSize: 16, SlowClientTimeout: time.Second})
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	waitFor(t, "the client to register", func() bool { return socket.ConnectionCount() == 1 })
+
+	closed := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				closed <- err
+				return
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	socket.Shutdown(ctx, "maintenance")
+
+	err = <-closed
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) || !strings.Contains(err.Error(), "maintenance") {
+		t.Errorf("client got %v, want a going away close frame with the reason", err)
+	}
+	if n := socket.ConnectionCount(); n != 0 {
+		t.Errorf("%d connections left after shutdown", n)
+	}
+	if _, _, err := websocket.DefaultDialer.Dial(url, nil); err == nil {
+		t.Error("connection accepted after shutdown")
+	}
+}
+
+func TestShutdownDisconnectsUnresponsiveClients(t *testing.T) {
+	socket, url, _ := startSocket(t, Options{QueueSize: 16, SlowClientTimeout: time.Second})
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	waitFor(t, "the client to register", func() bool { return socket.ConnectionCount() == 1 })
+
+	// The client never reads, so it never answers the close frame.
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	socket.Shutdown(ctx, "maintenance")
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("shutdown took %s", elapsed)
+	}
+	if n := socket.ConnectionCount(); n != 0 {
+		t.Errorf("%d connections left after shutdown", n)
+	}
+}
@@ -0,0 +1,48 @@
+package xplane
+
+import (
+	"fmt"
+
+	"msfs2020-gopilot/internal/geo"
+)
+
+const (
+	metersPerSecondPerKnot = geo.MetersPerNauticalMile / 3600
+)
+
+// State is the content of the XGPS and XATT messages of the X-Plane UDP output
+// as understood by ForeFlight, Garmin Pilot and most moving-map apps, e.g.
+//
+//	XGPSMSFS,-80.110000,34.550000,1200.1,359.05,55.6
+//	XATTMSFS,180.2,0.1,0.2
+type State struct {
+	Latitude    float64 // degrees
+	Longitude   float64 // degrees
+	Altitude    float64 // feet MSL
+	Track       float64 // degrees true
+	GroundSpeed float64 // knots
+	Heading     float64 // degrees true
+	Pitch       float64 // degrees, positive nose up
+	Bank        float64 // degrees, positive right wing down
+}
+
+// XGPS returns the position message. The simulator name identifies the
+// source and must not contain commas.
+func XGPS(sim string, s *State) []byte {
+	return []byte(fmt.Sprintf("XGPS%s,%.6f,%.6f,%.1f,%.2f,%.1f",
+		sim,
+		s.Longitude,
+		s.Latitude,
+		geo.FeetToMeters(s.Altitude),
+		geo.NormalizeHeading(s.Track),
+		s.GroundSpeed*metersPerSecondPerKnot))
+}
+
+// XATT returns the attitude message.
+func XATT(sim string, s *State) []byte {
+	return []byte(fmt.Sprintf("XATT%s,%.1f,%.1f,%.1f",
+		sim,
+		geo.NormalizeHeading(s.Heading),
+		s.Pitch,
+		s.Bank))
+}
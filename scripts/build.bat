@@ -1,5 +1,6 @@
 echo off
 set EXEC=gopilot.exe
+set CLI_EXEC=gopilot-cli.exe
 set TEMPLATE=./tools/packifier/template.gopher
 set PACKAGE=app
 set FUNCTION=SimConnectDLL
@@ -21,4 +22,6 @@ set CGO_ENABLED=1
 set GOOS=windows
 set GOARCH=amd64
 go build -o %EXEC% ./cmd/gopilot/main.go
+echo building %CLI_EXEC%
+go build -o %CLI_EXEC% ./cmd/gopilot-cli
 echo done.
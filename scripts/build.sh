@@ -1,6 +1,7 @@
 #!/bin/bash
 
 EXEC=gopilot.exe
+CLI_EXEC=gopilot-cli.exe
 if test -f "$EXEC"; then
   echo "Removing old executable..."
   rm $EXEC
@@ -31,4 +32,5 @@ fi
 
 echo "Building..."
 CGO_ENABLED=1 GOOS=windows GOARCH=amd64 go build -o $EXEC ./cmd/gopilot/main.go
+GOOS=windows GOARCH=amd64 go build -o $CLI_EXEC ./cmd/gopilot-cli
 echo "Done."